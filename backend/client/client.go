@@ -0,0 +1,267 @@
+// Package client is a Go client for the Cove API.
+//
+// Every /v1 endpoint has a typed method on Client. Responses are unwrapped
+// from the API's {"data": ...} envelope, failures come back as *Error, list
+// endpoints can be walked with iterators, and requests that fail for
+// transient reasons are retried. POST requests carry an Idempotency-Key so a
+// retried request is never applied twice.
+//
+//	c := client.New("https://api.cove.egeuysal.com", accessToken)
+//	link, err := c.CreateLink(ctx, client.CreateLinkRequest{GroupID: groupID, URL: u})
+//	if errors.Is(err, client.ErrConflict) {
+//		// already posted in this group
+//	}
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultMaxRetries = 3
+	userAgent         = "cove-go-client/1"
+)
+
+// Client calls the Cove API as one user. It is safe for concurrent use.
+type Client struct {
+	// BaseURL is the API's origin, without the /v1 prefix
+	BaseURL string
+	// Token is a Supabase access token, sent as a bearer token
+	Token string
+	// HTTPClient defaults to an http.Client with a 30 second timeout
+	HTTPClient *http.Client
+	// MaxRetries is how many times a request is retried after a network
+	// error, a 429, a 502, 503 or 504, or a 409 for an attempt still in
+	// progress. Zero disables retries.
+	MaxRetries int
+	// UserAgent is sent with every request
+	UserAgent string
+}
+
+func New(baseURL, token string) *Client {
+	return &Client{
+		BaseURL:    strings.TrimRight(baseURL, "/"),
+		Token:      token,
+		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+		MaxRetries: DefaultMaxRetries,
+		UserAgent:  userAgent,
+	}
+}
+
+// request is one API call. Body is kept as bytes so it can be resent.
+type request struct {
+	method      string
+	path        string
+	query       url.Values
+	body        []byte
+	contentType string
+	// accept overrides the JSON Accept header
+	accept string
+}
+
+func jsonRequest(method, path string, body interface{}) (request, error) {
+	req := request{method: method, path: path}
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return req, fmt.Errorf("encoding request body: %w", err)
+		}
+		req.body = encoded
+		req.contentType = "application/json"
+	}
+	return req, nil
+}
+
+// call sends req and decodes the data envelope of a successful response
+// into out, which may be nil
+func (c *Client) call(ctx context.Context, req request, out interface{}) error {
+	resp, err := c.send(ctx, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if out == nil {
+		_, err = io.Copy(io.Discard, resp.Body)
+		return err
+	}
+
+	envelope := struct {
+		Data interface{} `json:"data"`
+	}{Data: out}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return fmt.Errorf("decoding %s %s response: %w", req.method, req.path, err)
+	}
+	return nil
+}
+
+// send performs req with retries and returns the first successful response.
+// The caller must close its body. Failed responses are turned into *Error.
+func (c *Client) send(ctx context.Context, req request) (*http.Response, error) {
+	// One key for every attempt, so the server applies the request once
+	var idempotencyKey string
+	if req.method == http.MethodPost {
+		idempotencyKey = newIdempotencyKey()
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := c.do(ctx, req, idempotencyKey)
+
+		var apiErr *Error
+		if err == nil {
+			if resp.StatusCode < 400 {
+				return resp, nil
+			}
+			apiErr = decodeError(resp)
+			resp.Body.Close()
+			err = apiErr
+		}
+
+		if attempt >= c.MaxRetries || !retryable(ctx, err, apiErr) {
+			return nil, err
+		}
+
+		wait := backoff(attempt)
+		if apiErr != nil && apiErr.RetryAfter > wait {
+			wait = apiErr.RetryAfter
+		}
+		if err := sleepContext(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (c *Client) do(ctx context.Context, req request, idempotencyKey string) (*http.Response, error) {
+	u := c.BaseURL + req.path
+	if len(req.query) > 0 {
+		u += "?" + req.query.Encode()
+	}
+
+	var body io.Reader
+	if req.body != nil {
+		body = bytes.NewReader(req.body)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, u, body)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.accept != "" {
+		httpReq.Header.Set("Accept", req.accept)
+	} else {
+		httpReq.Header.Set("Accept", "application/json")
+	}
+	if c.Token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	if c.UserAgent != "" {
+		httpReq.Header.Set("User-Agent", c.UserAgent)
+	}
+	if req.contentType != "" {
+		httpReq.Header.Set("Content-Type", req.contentType)
+	}
+	if idempotencyKey != "" {
+		httpReq.Header.Set("Idempotency-Key", idempotencyKey)
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return httpClient.Do(httpReq)
+}
+
+// retryable reports whether a failed attempt is worth repeating. Network
+// errors are, unless the caller gave up; of the API's answers only rate
+// limiting, gateway failures and an earlier attempt still running are.
+func retryable(ctx context.Context, err error, apiErr *Error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if apiErr == nil {
+		return err != nil
+	}
+	if apiErr.InProgress {
+		return true
+	}
+	switch apiErr.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// backoff is 250ms doubled per attempt, capped at 8 seconds
+func backoff(attempt int) time.Duration {
+	d := time.Duration(250*math.Pow(2, float64(attempt))) * time.Millisecond
+	if d > 8*time.Second {
+		d = 8 * time.Second
+	}
+	return d
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func newIdempotencyKey() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		// crypto/rand does not fail on supported platforms
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+// pathf builds a request path, escaping each argument as a path segment
+func pathf(format string, args ...string) string {
+	escaped := make([]interface{}, len(args))
+	for i, arg := range args {
+		escaped[i] = url.PathEscape(arg)
+	}
+	return fmt.Sprintf(format, escaped...)
+}
+
+// callJSON sends body, if any, as JSON and decodes the response into out
+func (c *Client) callJSON(ctx context.Context, method, path string, body, out interface{}) error {
+	req, err := jsonRequest(method, path, body)
+	if err != nil {
+		return err
+	}
+	return c.call(ctx, req, out)
+}
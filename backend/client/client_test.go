@@ -0,0 +1,651 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/egeuysall/cove/client"
+	"github.com/egeuysall/cove/internal/api"
+	supabase "github.com/egeuysall/cove/internal/supabase/generated"
+	"github.com/egeuysall/cove/internal/utils"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const jwtSecret = "client-test-secret"
+
+var (
+	testUserID  = pgtype.UUID{Bytes: [16]byte{0x11}, Valid: true}
+	testGroupID = pgtype.UUID{Bytes: [16]byte{0x22}, Valid: true}
+)
+
+type (
+	queryFunc func(args []interface{}) ([][]interface{}, error)
+	execFunc  func(args []interface{}) (int64, error)
+)
+
+// fakeDB stands in for Postgres behind the real router. Queries are matched
+// on the "-- name:" line sqlc puts at the top of each one; rate limiting
+// always allows and idempotency keys are kept in memory.
+type fakeDB struct {
+	mu      sync.Mutex
+	queries map[string]queryFunc
+	execs   map[string]execFunc
+	calls   map[string]int
+	keys    map[string]supabase.IdempotencyKey
+}
+
+func newFakeDB() *fakeDB {
+	db := &fakeDB{
+		queries: map[string]queryFunc{},
+		execs:   map[string]execFunc{},
+		calls:   map[string]int{},
+		keys:    map[string]supabase.IdempotencyKey{},
+	}
+
+	db.queries["TakeRateLimitToken"] = func([]interface{}) ([][]interface{}, error) {
+		return [][]interface{}{{float64(100), true}}, nil
+	}
+
+	db.queries["ClaimIdempotencyKey"] = func(args []interface{}) ([][]interface{}, error) {
+		key := args[1].(string)
+		if _, ok := db.keys[key]; ok {
+			return nil, nil
+		}
+		stored := supabase.IdempotencyKey{
+			UserID:      args[0].(pgtype.UUID),
+			Key:         key,
+			Fingerprint: args[2].(string),
+			Status:      "pending",
+			ExpiresAt:   args[3].(pgtype.Timestamptz),
+		}
+		db.keys[key] = stored
+		return [][]interface{}{idempotencyRow(stored)}, nil
+	}
+	db.queries["GetIdempotencyKey"] = func(args []interface{}) ([][]interface{}, error) {
+		stored, ok := db.keys[args[1].(string)]
+		if !ok {
+			return nil, nil
+		}
+		return [][]interface{}{idempotencyRow(stored)}, nil
+	}
+	db.execs["CompleteIdempotencyKey"] = func(args []interface{}) (int64, error) {
+		key := args[1].(string)
+		stored := db.keys[key]
+		stored.Status = "completed"
+		stored.ResponseStatus = args[2].(pgtype.Int4)
+		stored.ContentType = args[3].(pgtype.Text)
+		stored.ResponseBody = append([]byte(nil), args[4].([]byte)...)
+		db.keys[key] = stored
+		return 1, nil
+	}
+	db.execs["ReleaseIdempotencyKey"] = func(args []interface{}) (int64, error) {
+		delete(db.keys, args[1].(string))
+		return 1, nil
+	}
+
+	return db
+}
+
+func idempotencyRow(k supabase.IdempotencyKey) []interface{} {
+	return []interface{}{k.UserID, k.Key, k.Fingerprint, k.Status, k.ResponseStatus, k.ContentType, k.ResponseBody, k.LockedAt, k.CreatedAt, k.ExpiresAt}
+}
+
+func queryName(sql string) string {
+	fields := strings.Fields(sql)
+	if len(fields) < 3 || fields[0] != "--" || fields[1] != "name:" {
+		return sql
+	}
+	return fields[2]
+}
+
+func (db *fakeDB) count(name string) int {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return db.calls[name]
+}
+
+func (db *fakeDB) run(sql string, args []interface{}) ([][]interface{}, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	name := queryName(sql)
+	db.calls[name]++
+
+	fn, ok := db.queries[name]
+	if !ok {
+		return nil, fmt.Errorf("fakeDB: unexpected query %s", name)
+	}
+	return fn(args)
+}
+
+func (db *fakeDB) Exec(_ context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	name := queryName(sql)
+	db.calls[name]++
+
+	fn, ok := db.execs[name]
+	if !ok {
+		return pgconn.CommandTag{}, fmt.Errorf("fakeDB: unexpected exec %s", name)
+	}
+	affected, err := fn(args)
+	return pgconn.NewCommandTag(fmt.Sprintf("UPDATE %d", affected)), err
+}
+
+func (db *fakeDB) QueryRow(_ context.Context, sql string, args ...interface{}) pgx.Row {
+	rows, err := db.run(sql, args)
+	if err == nil && len(rows) == 0 {
+		err = pgx.ErrNoRows
+	}
+	return &fakeRows{values: rows, err: err, index: -1}
+}
+
+func (db *fakeDB) Query(_ context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	rows, err := db.run(sql, args)
+	if err != nil {
+		return nil, err
+	}
+	return &fakeRows{values: rows, index: -1}, nil
+}
+
+func (db *fakeDB) CopyFrom(context.Context, pgx.Identifier, []string, pgx.CopyFromSource) (int64, error) {
+	return 0, errors.New("fakeDB: unexpected copy")
+}
+
+// fakeRows serves both QueryRow, which scans the first row, and Query
+type fakeRows struct {
+	values [][]interface{}
+	err    error
+	index  int
+}
+
+func (r *fakeRows) Next() bool {
+	if r.err != nil || r.index+1 >= len(r.values) {
+		return false
+	}
+	r.index++
+	return true
+}
+
+func (r *fakeRows) Scan(dest ...interface{}) error {
+	if r.err != nil {
+		return r.err
+	}
+	if r.index < 0 && !r.Next() {
+		return pgx.ErrNoRows
+	}
+
+	row := r.values[r.index]
+	if len(row) != len(dest) {
+		return fmt.Errorf("fakeRows: scanning %d columns into %d values", len(row), len(dest))
+	}
+	for i, value := range row {
+		target := reflect.ValueOf(dest[i]).Elem()
+		if value == nil {
+			target.Set(reflect.Zero(target.Type()))
+			continue
+		}
+		v := reflect.ValueOf(value)
+		if !v.Type().AssignableTo(target.Type()) {
+			return fmt.Errorf("fakeRows: column %d is %s, not %s", i, v.Type(), target.Type())
+		}
+		target.Set(v)
+	}
+	return nil
+}
+
+func (r *fakeRows) Close()                                       {}
+func (r *fakeRows) Err() error                                   { return r.err }
+func (r *fakeRows) CommandTag() pgconn.CommandTag                { return pgconn.CommandTag{} }
+func (r *fakeRows) FieldDescriptions() []pgconn.FieldDescription { return nil }
+func (r *fakeRows) Values() ([]interface{}, error)               { return r.values[r.index], nil }
+func (r *fakeRows) RawValues() [][]byte                          { return nil }
+func (r *fakeRows) Conn() *pgx.Conn                              { return nil }
+
+// newTestClient serves the real router over db and returns a client signed
+// in as testUserID
+func newTestClient(t *testing.T, db *fakeDB) *client.Client {
+	t.Helper()
+	t.Setenv("SUPABASE_JWT_SECRET", jwtSecret)
+
+	previous := utils.Queries
+	utils.Queries = supabase.New(db)
+	t.Cleanup(func() { utils.Queries = previous })
+
+	server := httptest.NewServer(api.Router())
+	t.Cleanup(server.Close)
+
+	c := client.New(server.URL, testToken(t, utils.UUIDToString(testUserID)))
+	c.HTTPClient = server.Client()
+	return c
+}
+
+func testToken(t *testing.T, userID string) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub": userID,
+		"iss": "test",
+		"aud": "authenticated",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+	signed, err := token.SignedString([]byte(jwtSecret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func TestResponsesAreUnwrapped(t *testing.T) {
+	db := newFakeDB()
+	db.execs["MarkAllNotificationsRead"] = func([]interface{}) (int64, error) { return 3, nil }
+	db.queries["ListNotifications"] = func([]interface{}) ([][]interface{}, error) { return nil, nil }
+	db.queries["CountUnreadNotifications"] = func([]interface{}) ([][]interface{}, error) {
+		return [][]interface{}{{int64(7)}}, nil
+	}
+
+	c := newTestClient(t, db)
+	ctx := context.Background()
+
+	read, err := c.MarkAllNotificationsRead(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if read.Marked != 3 {
+		t.Errorf("Marked = %d, want 3", read.Marked)
+	}
+
+	unread, err := c.UnreadNotificationCount(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if unread != 7 {
+		t.Errorf("UnreadNotificationCount = %d, want 7", unread)
+	}
+}
+
+func TestErrorsAreTyped(t *testing.T) {
+	db := newFakeDB()
+	db.queries["MarkNotificationRead"] = func([]interface{}) ([][]interface{}, error) { return nil, nil }
+
+	c := newTestClient(t, db)
+	ctx := context.Background()
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := c.MarkNotificationRead(ctx, "33333333-3333-3333-3333-333333333333")
+
+		var apiErr *client.Error
+		if !errors.As(err, &apiErr) {
+			t.Fatalf("error %v is not a *client.Error", err)
+		}
+		if apiErr.StatusCode != http.StatusNotFound || apiErr.Message != "Notification not found" {
+			t.Errorf("got %d %q", apiErr.StatusCode, apiErr.Message)
+		}
+		if !errors.Is(err, client.ErrNotFound) || errors.Is(err, client.ErrConflict) {
+			t.Errorf("error %v does not match only ErrNotFound", err)
+		}
+	})
+
+	t.Run("invalid request", func(t *testing.T) {
+		_, err := c.MarkNotificationRead(ctx, "not-a-uuid")
+
+		var apiErr *client.Error
+		if !errors.As(err, &apiErr) {
+			t.Fatalf("error %v is not a *client.Error", err)
+		}
+		if !errors.Is(err, client.ErrInvalidRequest) {
+			t.Errorf("error %v does not match ErrInvalidRequest", err)
+		}
+		if len(apiErr.Details) != 1 || apiErr.Details[0].In != "path" || apiErr.Details[0].Name != "id" {
+			t.Errorf("Details = %+v, want one problem with the id path parameter", apiErr.Details)
+		}
+	})
+
+	t.Run("unauthorized", func(t *testing.T) {
+		signedOut := *c
+		signedOut.Token = "not-a-jwt"
+
+		_, err := signedOut.MarkAllNotificationsRead(ctx)
+		if !errors.Is(err, client.ErrUnauthorized) {
+			t.Errorf("error %v does not match ErrUnauthorized", err)
+		}
+	})
+}
+
+func TestInProgressIsNotConflict(t *testing.T) {
+	conflict := func(inProgress bool) *client.Client {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			if inProgress {
+				w.Header().Set("Retry-After", "1")
+				w.Header().Set("Idempotent-In-Progress", "true")
+			}
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprint(w, `{"error":"conflict"}`)
+		}))
+		t.Cleanup(server.Close)
+
+		c := client.New(server.URL, "")
+		c.MaxRetries = 0
+		return c
+	}
+	ctx := context.Background()
+
+	_, err := conflict(true).MarkAllNotificationsRead(ctx)
+	if !errors.Is(err, client.ErrInProgress) || errors.Is(err, client.ErrConflict) {
+		t.Errorf("in-progress 409 %v: want ErrInProgress and not ErrConflict", err)
+	}
+
+	_, err = conflict(false).MarkAllNotificationsRead(ctx)
+	if !errors.Is(err, client.ErrConflict) || errors.Is(err, client.ErrInProgress) {
+		t.Errorf("plain 409 %v: want ErrConflict and not ErrInProgress", err)
+	}
+}
+
+func TestCursorPagination(t *testing.T) {
+	const total = 250
+	start := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+
+	// Newest first, as the query orders them
+	var notifications [][]interface{}
+	for i := total - 1; i >= 0; i-- {
+		notifications = append(notifications, []interface{}{
+			pgtype.UUID{Bytes: [16]byte{0x33, byte(i >> 8), byte(i)}, Valid: true},
+			testUserID,
+			"new_link",
+			testGroupID,
+			pgtype.UUID{},
+			pgtype.UUID{},
+			pgtype.Timestamptz{Time: start.Add(time.Duration(i) * time.Minute), Valid: true},
+			pgtype.Timestamptz{},
+			pgtype.Text{String: "Friends", Valid: true},
+			pgtype.Text{String: fmt.Sprintf("https://example.com/%d", i), Valid: true},
+			pgtype.Text{},
+		})
+	}
+
+	db := newFakeDB()
+	db.queries["ListNotifications"] = func(args []interface{}) ([][]interface{}, error) {
+		cursor := args[2].(pgtype.Timestamptz)
+		limit := int(args[4].(int32))
+
+		var page [][]interface{}
+		for _, row := range notifications {
+			createdAt := row[6].(pgtype.Timestamptz)
+			if cursor.Valid && !createdAt.Time.Before(cursor.Time) {
+				continue
+			}
+			if len(page) == limit {
+				break
+			}
+			page = append(page, row)
+		}
+		return page, nil
+	}
+	db.queries["CountUnreadNotifications"] = func([]interface{}) ([][]interface{}, error) {
+		return [][]interface{}{{int64(total)}}, nil
+	}
+
+	c := newTestClient(t, db)
+	ctx := context.Background()
+
+	var urls []string
+	for notification, err := range c.ListNotifications(ctx, false) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		urls = append(urls, notification.LinkURL)
+	}
+
+	if len(urls) != total {
+		t.Fatalf("iterated %d notifications, want %d", len(urls), total)
+	}
+	for i, url := range urls {
+		if want := fmt.Sprintf("https://example.com/%d", total-1-i); url != want {
+			t.Fatalf("notification %d is %s, want %s", i, url, want)
+		}
+	}
+	if pages := db.count("ListNotifications"); pages != 3 {
+		t.Errorf("fetched %d pages, want 3", pages)
+	}
+
+	// Stopping early does not fetch the pages after
+	for range c.ListNotifications(ctx, false) {
+		break
+	}
+	if pages := db.count("ListNotifications"); pages != 4 {
+		t.Errorf("breaking out fetched %d more pages, want 1", pages-3)
+	}
+}
+
+func TestOffsetPagination(t *testing.T) {
+	const total = 150
+	reportedAt := pgtype.Timestamptz{Time: time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC), Valid: true}
+
+	var queue [][]interface{}
+	for i := 0; i < total; i++ {
+		queue = append(queue, []interface{}{
+			pgtype.UUID{Bytes: [16]byte{0x44, byte(i)}, Valid: true},
+			testUserID,
+			fmt.Sprintf("https://example.com/%d", i),
+			pgtype.Text{},
+			pgtype.Text{},
+			"ok",
+			reportedAt,
+			pgtype.Timestamptz{},
+			int32(total - i),
+			[]string{"spam"},
+			reportedAt,
+		})
+	}
+
+	db := newFakeDB()
+	db.queries["IsGroupAdmin"] = func([]interface{}) ([][]interface{}, error) {
+		return [][]interface{}{{true}}, nil
+	}
+	db.queries["GetModerationQueue"] = func(args []interface{}) ([][]interface{}, error) {
+		limit := int(args[1].(int32))
+		offset := int(args[2].(int32))
+		if offset >= len(queue) {
+			return nil, nil
+		}
+		return queue[offset:min(offset+limit, len(queue))], nil
+	}
+
+	c := newTestClient(t, db)
+
+	var seen int
+	for item, err := range c.ModerationQueue(context.Background(), utils.UUIDToString(testGroupID)) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := fmt.Sprintf("https://example.com/%d", seen); item.Link.URL != want {
+			t.Fatalf("item %d is %s, want %s", seen, item.Link.URL, want)
+		}
+		seen++
+	}
+
+	if seen != total {
+		t.Fatalf("iterated %d items, want %d", seen, total)
+	}
+	// Two full or partial pages, then an empty one to find the end
+	if pages := db.count("GetModerationQueue"); pages != 3 {
+		t.Errorf("fetched %d pages, want 3", pages)
+	}
+}
+
+// dropFirstResponse loses the response to the first request after the server
+// has handled it, like a connection cut on the way back, and records the
+// headers each attempt was sent and answered with
+type dropFirstResponse struct {
+	next http.RoundTripper
+
+	mu       sync.Mutex
+	keys     []string
+	replayed []string
+}
+
+func (d *dropFirstResponse) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := d.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.keys = append(d.keys, req.Header.Get("Idempotency-Key"))
+	d.replayed = append(d.replayed, resp.Header.Get("Idempotent-Replayed"))
+
+	if len(d.keys) == 1 {
+		resp.Body.Close()
+		return nil, errors.New("connection reset by peer")
+	}
+	return resp, nil
+}
+
+func TestRetriesReuseIdempotencyKey(t *testing.T) {
+	db := newFakeDB()
+	db.execs["MarkAllNotificationsRead"] = func([]interface{}) (int64, error) { return 4, nil }
+
+	c := newTestClient(t, db)
+	transport := &dropFirstResponse{next: c.HTTPClient.Transport}
+	c.HTTPClient = &http.Client{Transport: transport}
+
+	read, err := c.MarkAllNotificationsRead(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if read.Marked != 4 {
+		t.Errorf("Marked = %d, want 4 from the replayed response", read.Marked)
+	}
+
+	if len(transport.keys) != 2 {
+		t.Fatalf("sent %d attempts, want 2", len(transport.keys))
+	}
+	if transport.keys[0] == "" || transport.keys[0] != transport.keys[1] {
+		t.Errorf("attempts sent Idempotency-Keys %q, want one key for both", transport.keys)
+	}
+	if transport.replayed[1] != "true" {
+		t.Errorf("retry was not answered from the stored response")
+	}
+	if runs := db.count("MarkAllNotificationsRead"); runs != 1 {
+		t.Errorf("handler ran %d times, want 1", runs)
+	}
+}
+
+func TestStreamDeliversReplies(t *testing.T) {
+	replierID := pgtype.UUID{Bytes: [16]byte{0x55}, Valid: true}
+	linkID := pgtype.UUID{Bytes: [16]byte{0x66}, Valid: true}
+	createdAt := pgtype.Timestamptz{Time: time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC), Valid: true}
+
+	db := newFakeDB()
+	db.queries["GetLinkByID"] = func([]interface{}) ([][]interface{}, error) {
+		return [][]interface{}{{
+			linkID, testGroupID, testUserID, "https://example.com/post", pgtype.Text{String: "A post", Valid: true},
+			pgtype.Text{}, createdAt, []string(nil), "ok", pgtype.Int4{}, pgtype.Text{}, pgtype.Timestamptz{},
+			int32(0), pgtype.Timestamptz{}, "abc123", pgtype.Text{}, pgtype.Timestamptz{}, pgtype.UUID{},
+			createdAt, pgtype.Text{String: "example.com", Valid: true}, pgtype.Text{},
+		}}, nil
+	}
+	db.queries["IsUserInGroup"] = func([]interface{}) ([][]interface{}, error) {
+		return [][]interface{}{{true}}, nil
+	}
+	db.queries["GetVisibleProfiles"] = func([]interface{}) ([][]interface{}, error) { return nil, nil }
+
+	var replies int
+	db.queries["CreateLinkReply"] = func(args []interface{}) ([][]interface{}, error) {
+		replies++
+		id := pgtype.UUID{Bytes: [16]byte{0x77, byte(replies)}, Valid: true}
+		return [][]interface{}{{id, args[0], args[1], args[2], createdAt}}, nil
+	}
+
+	stored := map[pgtype.UUID]supabase.Notification{}
+	db.queries["CreateNotification"] = func(args []interface{}) ([][]interface{}, error) {
+		n := supabase.Notification{
+			ID:        pgtype.UUID{Bytes: [16]byte{0x88, byte(len(stored))}, Valid: true},
+			UserID:    args[0].(pgtype.UUID),
+			Kind:      args[1].(string),
+			GroupID:   args[2].(pgtype.UUID),
+			LinkID:    args[3].(pgtype.UUID),
+			ActorID:   args[4].(pgtype.UUID),
+			CreatedAt: createdAt,
+		}
+		stored[n.ID] = n
+		return [][]interface{}{{n.ID, n.UserID, n.Kind, n.GroupID, n.LinkID, n.ActorID, n.CreatedAt, n.ReadAt}}, nil
+	}
+	db.queries["GetNotification"] = func(args []interface{}) ([][]interface{}, error) {
+		n, ok := stored[args[0].(pgtype.UUID)]
+		if !ok || n.UserID != args[1].(pgtype.UUID) {
+			return nil, nil
+		}
+		return [][]interface{}{{
+			n.ID, n.UserID, n.Kind, n.GroupID, n.LinkID, n.ActorID, n.CreatedAt, n.ReadAt,
+			pgtype.Text{String: "Friends", Valid: true},
+			pgtype.Text{String: "https://example.com/post", Valid: true},
+			pgtype.Text{String: "A post", Valid: true},
+		}}, nil
+	}
+
+	poster := newTestClient(t, db)
+	replier := client.New(poster.BaseURL, testToken(t, utils.UUIDToString(replierID)))
+	replier.HTTPClient = poster.HTTPClient
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// The stream connects in the background, so keep replying until it
+	// receives one
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for ctx.Err() == nil {
+			_, err := replier.CreateLinkReply(ctx, utils.UUIDToString(linkID), "Nice find")
+			if err != nil && ctx.Err() == nil {
+				t.Errorf("replying: %v", err)
+				return
+			}
+			select {
+			case <-ctx.Done():
+			case <-time.After(50 * time.Millisecond):
+			}
+		}
+	}()
+	defer wg.Wait()
+	defer cancel()
+
+	var received *client.Notification
+	for notification, err := range poster.StreamNotifications(ctx) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		received = &notification
+		break
+	}
+
+	if received == nil {
+		t.Fatal("stream ended without a notification")
+	}
+	if received.Kind != "reply" || received.LinkID != utils.UUIDToString(linkID) {
+		t.Errorf("got a %s notification for link %s, want a reply to %s", received.Kind, received.LinkID, utils.UUIDToString(linkID))
+	}
+	if received.Actor == nil || received.Actor.UserID != utils.UUIDToString(replierID) {
+		t.Errorf("notification actor is %+v, want the replier", received.Actor)
+	}
+	if received.GroupName != "Friends" || received.LinkTitle != "A post" {
+		t.Errorf("notification names group %q and link %q", received.GroupName, received.LinkTitle)
+	}
+}
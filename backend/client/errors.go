@@ -0,0 +1,102 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Sentinels for the API's error classes, for use with errors.Is:
+//
+//	if errors.Is(err, client.ErrNotFound) { ... }
+var (
+	ErrInvalidRequest = errors.New("invalid request")
+	ErrUnauthorized   = errors.New("unauthorized")
+	ErrForbidden      = errors.New("forbidden")
+	ErrNotFound       = errors.New("not found")
+	ErrConflict       = errors.New("conflict")
+	ErrRateLimited    = errors.New("rate limited")
+	ErrServer         = errors.New("server error")
+	// ErrInProgress is a 409 saying an earlier attempt of the same request
+	// is still running. It is retried, and does not match ErrConflict.
+	ErrInProgress = errors.New("request in progress")
+)
+
+// Error is a failed API response, decoded from the {"error": ...} envelope.
+// Details is set when the request did not match the API specification.
+type Error struct {
+	StatusCode int
+	Message    string
+	Details    []ValidationError
+	// RetryAfter is how long the server asked the client to wait, if it did
+	RetryAfter time.Duration
+	// InProgress is set on the 409 for a retry whose first attempt has not
+	// finished yet
+	InProgress bool
+}
+
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("cove: %d %s", e.StatusCode, e.Message)
+	for _, d := range e.Details {
+		if d.Name != "" {
+			msg += fmt.Sprintf("; %s %s: %s", d.In, d.Name, d.Message)
+		} else {
+			msg += fmt.Sprintf("; %s: %s", d.In, d.Message)
+		}
+	}
+	return msg
+}
+
+// Is matches the sentinel for the error's status code
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrInvalidRequest:
+		return e.StatusCode == http.StatusBadRequest || e.StatusCode == http.StatusUnprocessableEntity
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict && !e.InProgress
+	case ErrInProgress:
+		return e.StatusCode == http.StatusConflict && e.InProgress
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrServer:
+		return e.StatusCode >= 500
+	}
+	return false
+}
+
+// decodeError reads a failed response. Bodies that are not the API's error
+// envelope, such as a proxy's error page, fall back to the status text.
+func decodeError(resp *http.Response) *Error {
+	apiErr := &Error{
+		StatusCode: resp.StatusCode,
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+		InProgress: resp.Header.Get("Idempotent-In-Progress") == "true",
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+
+	var envelope struct {
+		Error   string            `json:"error"`
+		Details []ValidationError `json:"details"`
+	}
+	if json.Unmarshal(body, &envelope) == nil && envelope.Error != "" {
+		apiErr.Message = envelope.Error
+		apiErr.Details = envelope.Details
+	} else if text := strings.TrimSpace(string(body)); text != "" && len(text) <= 200 && !strings.HasPrefix(text, "<") {
+		apiErr.Message = text
+	} else {
+		apiErr.Message = http.StatusText(resp.StatusCode)
+	}
+
+	return apiErr
+}
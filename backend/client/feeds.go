@@ -0,0 +1,46 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// CreateFeedToken creates a token for subscribing to a group's feed in a
+// feed reader. The token and feed URLs are only returned here.
+func (c *Client) CreateFeedToken(ctx context.Context, groupID string) (*FeedToken, error) {
+	var token FeedToken
+	if err := c.callJSON(ctx, http.MethodPost, pathf("/v1/groups/%s/feed-tokens", groupID), nil, &token); err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (c *Client) ListFeedTokens(ctx context.Context, groupID string) ([]FeedToken, error) {
+	var tokens []FeedToken
+	err := c.callJSON(ctx, http.MethodGet, pathf("/v1/groups/%s/feed-tokens", groupID), nil, &tokens)
+	return tokens, err
+}
+
+func (c *Client) RevokeFeedToken(ctx context.Context, groupID, tokenID string) error {
+	return c.callJSON(ctx, http.MethodDelete, pathf("/v1/groups/%s/feed-tokens/%s", groupID, tokenID), nil, nil)
+}
+
+// CreateFeedSource subscribes a group to an external RSS or Atom feed,
+// whose new entries are posted to the group
+func (c *Client) CreateFeedSource(ctx context.Context, groupID string, req CreateFeedSourceRequest) (*FeedSource, error) {
+	var source FeedSource
+	if err := c.callJSON(ctx, http.MethodPost, pathf("/v1/groups/%s/sources", groupID), req, &source); err != nil {
+		return nil, err
+	}
+	return &source, nil
+}
+
+func (c *Client) ListFeedSources(ctx context.Context, groupID string) ([]FeedSource, error) {
+	var sources []FeedSource
+	err := c.callJSON(ctx, http.MethodGet, pathf("/v1/groups/%s/sources", groupID), nil, &sources)
+	return sources, err
+}
+
+func (c *Client) DeleteFeedSource(ctx context.Context, groupID, sourceID string) error {
+	return c.callJSON(ctx, http.MethodDelete, pathf("/v1/groups/%s/sources/%s", groupID, sourceID), nil, nil)
+}
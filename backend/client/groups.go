@@ -0,0 +1,78 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+func (c *Client) CreateGroup(ctx context.Context, name string) (*Group, error) {
+	body := struct {
+		Name string `json:"name"`
+	}{name}
+
+	var group Group
+	err := c.callJSON(ctx, http.MethodPost, "/v1/groups", body, &group)
+	if err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+// ListGroups returns the groups the caller belongs to, newest first
+func (c *Client) ListGroups(ctx context.Context) ([]Group, error) {
+	var groups []Group
+	err := c.callJSON(ctx, http.MethodGet, "/v1/groups", nil, &groups)
+	return groups, err
+}
+
+func (c *Client) GetGroup(ctx context.Context, groupID string) (*Group, error) {
+	var group Group
+	err := c.callJSON(ctx, http.MethodGet, pathf("/v1/groups/%s", groupID), nil, &group)
+	if err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+// DeleteGroup deletes a group the caller created, with everything in it
+func (c *Client) DeleteGroup(ctx context.Context, groupID string) error {
+	return c.callJSON(ctx, http.MethodDelete, pathf("/v1/groups/%s", groupID), nil, nil)
+}
+
+// UpdateGroup applies patch to a group's profile and settings as a JSON Merge
+// Patch: keys left out are unchanged and a nil value clears one, for example
+//
+//	c.UpdateGroup(ctx, id, map[string]interface{}{
+//		"emoji":    nil,
+//		"settings": map[string]interface{}{"who_can_post": "admins"},
+//	})
+func (c *Client) UpdateGroup(ctx context.Context, groupID string, patch map[string]interface{}) (*Group, error) {
+	req, err := jsonRequest(http.MethodPatch, pathf("/v1/groups/%s", groupID), patch)
+	if err != nil {
+		return nil, err
+	}
+	req.contentType = "application/merge-patch+json"
+
+	var group Group
+	err = c.call(ctx, req, &group)
+	if err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+// AddGroupMember adds another user to a group the caller may invite to
+func (c *Client) AddGroupMember(ctx context.Context, groupID, userID string) error {
+	body := struct {
+		UserID string `json:"user_id"`
+	}{userID}
+
+	return c.callJSON(ctx, http.MethodPost, pathf("/v1/groups/%s/members", groupID), body, nil)
+}
+
+// ListGroupMembers returns a group's members with their profiles
+func (c *Client) ListGroupMembers(ctx context.Context, groupID string) ([]Member, error) {
+	var members []Member
+	err := c.callJSON(ctx, http.MethodGet, pathf("/v1/groups/%s/members", groupID), nil, &members)
+	return members, err
+}
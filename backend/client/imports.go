@@ -0,0 +1,95 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+)
+
+// ImportResult is the outcome of ImportLinks. Small files are imported
+// straight away and set Report; larger ones set Job, to be polled with
+// GetImportJob until it is done or failed.
+type ImportResult struct {
+	Report *ImportReport
+	Job    *ImportJob
+}
+
+// ImportLinks imports a Netscape bookmarks file, Pocket CSV export or
+// Pinboard JSON export into a group. Format may be empty to have the server
+// detect it. The file is read into memory so the upload can be retried.
+func (c *Client) ImportLinks(ctx context.Context, groupID, filename string, file io.Reader, format string) (*ImportResult, error) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	if format != "" {
+		if err := form.WriteField("format", format); err != nil {
+			return nil, err
+		}
+	}
+	part, err := form.CreateFormFile("file", filename)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(part, file); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", filename, err)
+	}
+	if err := form.Close(); err != nil {
+		return nil, err
+	}
+
+	req := request{
+		method:      http.MethodPost,
+		path:        pathf("/v1/groups/%s/import", groupID),
+		body:        body.Bytes(),
+		contentType: form.FormDataContentType(),
+	}
+	resp, err := c.send(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	result := &ImportResult{}
+	var envelope struct {
+		Data interface{} `json:"data"`
+	}
+	if resp.StatusCode == http.StatusAccepted {
+		result.Job = &ImportJob{}
+		envelope.Data = result.Job
+	} else {
+		result.Report = &ImportReport{}
+		envelope.Data = result.Report
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return nil, fmt.Errorf("decoding import response: %w", err)
+	}
+	return result, nil
+}
+
+func (c *Client) GetImportJob(ctx context.Context, groupID, jobID string) (*ImportJob, error) {
+	var job ImportJob
+	if err := c.callJSON(ctx, http.MethodGet, pathf("/v1/groups/%s/import/%s", groupID, jobID), nil, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// ExportGroup streams an export of a group's links, replies, members and
+// invites. Format is json, ndjson, csv, html or zip; empty means json. The
+// caller must close the returned body.
+func (c *Client) ExportGroup(ctx context.Context, groupID, format string) (io.ReadCloser, error) {
+	req := request{method: http.MethodGet, path: pathf("/v1/groups/%s/export", groupID)}
+	if format != "" {
+		req.query = url.Values{"format": {format}}
+	}
+
+	resp, err := c.send(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
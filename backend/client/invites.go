@@ -0,0 +1,36 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+func (c *Client) CreateInvite(ctx context.Context, groupID string) (*Invite, error) {
+	var invite Invite
+	err := c.callJSON(ctx, http.MethodPost, "/v1/invites", CreateInviteRequest{GroupID: groupID}, &invite)
+	if err != nil {
+		return nil, err
+	}
+	return &invite, nil
+}
+
+func (c *Client) GetInvite(ctx context.Context, code string) (*Invite, error) {
+	var invite Invite
+	err := c.callJSON(ctx, http.MethodGet, pathf("/v1/invites/%s", code), nil, &invite)
+	if err != nil {
+		return nil, err
+	}
+	return &invite, nil
+}
+
+// AcceptInvite joins the invite's group. An invite that was already used
+// fails with ErrNotFound.
+func (c *Client) AcceptInvite(ctx context.Context, code string) error {
+	return c.callJSON(ctx, http.MethodPost, pathf("/v1/invites/%s/accept", code), nil, nil)
+}
+
+func (c *Client) ListGroupInvites(ctx context.Context, groupID string) ([]Invite, error) {
+	var invites []Invite
+	err := c.callJSON(ctx, http.MethodGet, pathf("/v1/groups/%s/invites", groupID), nil, &invites)
+	return invites, err
+}
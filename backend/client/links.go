@@ -0,0 +1,122 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"net/url"
+)
+
+// CreateLink posts a link to a group. A link already posted in the group
+// fails with ErrConflict, and one to a blocked domain with ErrForbidden.
+func (c *Client) CreateLink(ctx context.Context, req CreateLinkRequest) (*Link, error) {
+	var link Link
+	if err := c.callJSON(ctx, http.MethodPost, "/v1/links", req, &link); err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+// CreateLinks posts several links at once, each to its own group and to
+// every group in req.GroupIDs. Links that fail do not fail the call; check
+// each result's Status.
+func (c *Client) CreateLinks(ctx context.Context, req CreateLinksBatchRequest) (*CreateLinksBatchResponse, error) {
+	var batch CreateLinksBatchResponse
+	if err := c.callJSON(ctx, http.MethodPost, "/v1/links/batch", req, &batch); err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+func (c *Client) GetLink(ctx context.Context, linkID string) (*Link, error) {
+	var link Link
+	if err := c.callJSON(ctx, http.MethodGet, pathf("/v1/links/%s", linkID), nil, &link); err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+// UpdateLinkComment replaces the comment on one of the caller's links
+func (c *Client) UpdateLinkComment(ctx context.Context, linkID, comment string) (*Link, error) {
+	body := struct {
+		Comment string `json:"comment"`
+	}{comment}
+
+	var link Link
+	if err := c.callJSON(ctx, http.MethodPatch, pathf("/v1/links/%s", linkID), body, &link); err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+func (c *Client) DeleteLink(ctx context.Context, linkID string) error {
+	return c.callJSON(ctx, http.MethodDelete, pathf("/v1/links/%s", linkID), nil, nil)
+}
+
+// CreateLinkReply replies to a link. Its poster is notified, as is anyone
+// the reply mentions.
+func (c *Client) CreateLinkReply(ctx context.Context, linkID, body string) (*LinkReply, error) {
+	req := struct {
+		Body string `json:"body"`
+	}{body}
+
+	var reply LinkReply
+	if err := c.callJSON(ctx, http.MethodPost, pathf("/v1/links/%s/replies", linkID), req, &reply); err != nil {
+		return nil, err
+	}
+	return &reply, nil
+}
+
+// ListLinkReplies iterates over a link's replies, oldest first
+func (c *Client) ListLinkReplies(ctx context.Context, linkID string) iter.Seq2[LinkReply, error] {
+	query := url.Values{"limit": {"100"}}
+	return cursorPages[LinkReply](ctx, c, pathf("/v1/links/%s/replies", linkID), query)
+}
+
+func (c *Client) DeleteLinkReply(ctx context.Context, linkID, replyID string) error {
+	return c.callJSON(ctx, http.MethodDelete, pathf("/v1/links/%s/replies/%s", linkID, replyID), nil, nil)
+}
+
+func (c *Client) GetLinkSnapshot(ctx context.Context, linkID string) (*Snapshot, error) {
+	var snapshot Snapshot
+	if err := c.callJSON(ctx, http.MethodGet, pathf("/v1/links/%s/snapshot", linkID), nil, &snapshot); err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// GetLinkClicks returns click statistics; only the link's poster may see them
+func (c *Client) GetLinkClicks(ctx context.Context, linkID string) (*LinkClickStats, error) {
+	var stats LinkClickStats
+	if err := c.callJSON(ctx, http.MethodGet, pathf("/v1/links/%s/clicks", linkID), nil, &stats); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// ListLinksOptions filters ListGroupLinks. Zero values match everything.
+type ListLinksOptions struct {
+	// Status is the link checker's verdict: unchecked, ok, failing or dead
+	Status string
+	// Query is a full-text search over titles, comments, URLs and page text
+	Query string
+}
+
+// ListGroupLinks iterates over a group's links, newest first:
+//
+//	for link, err := range c.ListGroupLinks(ctx, groupID, client.ListLinksOptions{}) {
+//		if err != nil {
+//			return err
+//		}
+//		fmt.Println(link.URL)
+//	}
+func (c *Client) ListGroupLinks(ctx context.Context, groupID string, opts ListLinksOptions) iter.Seq2[Link, error] {
+	query := url.Values{}
+	if opts.Status != "" {
+		query.Set("status", opts.Status)
+	}
+	if opts.Query != "" {
+		query.Set("q", opts.Query)
+	}
+	return offsetPages[Link](ctx, c, pathf("/v1/groups/%s/links", groupID), query, 100)
+}
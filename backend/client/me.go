@@ -0,0 +1,74 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"net/url"
+)
+
+// SaveLink adds a link to the caller's private reading list
+func (c *Client) SaveLink(ctx context.Context, req SaveLinkRequest) (*SavedLink, error) {
+	var saved SavedLink
+	if err := c.callJSON(ctx, http.MethodPost, "/v1/me/saved", req, &saved); err != nil {
+		return nil, err
+	}
+	return &saved, nil
+}
+
+// ListSavedLinksOptions filters ListSavedLinks. Zero values match everything.
+type ListSavedLinksOptions struct {
+	// Status is to-read, reading or done
+	Status  string
+	GroupID string
+}
+
+// ListSavedLinks iterates over the caller's reading list, newest first
+func (c *Client) ListSavedLinks(ctx context.Context, opts ListSavedLinksOptions) iter.Seq2[SavedLink, error] {
+	query := url.Values{"limit": {"100"}}
+	if opts.Status != "" {
+		query.Set("status", opts.Status)
+	}
+	if opts.GroupID != "" {
+		query.Set("group_id", opts.GroupID)
+	}
+	return cursorPages[SavedLink](ctx, c, "/v1/me/saved", query)
+}
+
+func (c *Client) GetSavedLink(ctx context.Context, savedID string) (*SavedLink, error) {
+	var saved SavedLink
+	if err := c.callJSON(ctx, http.MethodGet, pathf("/v1/me/saved/%s", savedID), nil, &saved); err != nil {
+		return nil, err
+	}
+	return &saved, nil
+}
+
+// UpdateSavedLink changes a saved link's status or note; nil fields are left
+// as they are
+func (c *Client) UpdateSavedLink(ctx context.Context, savedID string, req UpdateSavedLinkRequest) (*SavedLink, error) {
+	var saved SavedLink
+	if err := c.callJSON(ctx, http.MethodPatch, pathf("/v1/me/saved/%s", savedID), req, &saved); err != nil {
+		return nil, err
+	}
+	return &saved, nil
+}
+
+func (c *Client) DeleteSavedLink(ctx context.Context, savedID string) error {
+	return c.callJSON(ctx, http.MethodDelete, pathf("/v1/me/saved/%s", savedID), nil, nil)
+}
+
+func (c *Client) GetDigestSettings(ctx context.Context) (*DigestSettings, error) {
+	var settings DigestSettings
+	if err := c.callJSON(ctx, http.MethodGet, "/v1/me/digest", nil, &settings); err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+func (c *Client) UpdateDigestSettings(ctx context.Context, req DigestSettingsRequest) (*DigestSettings, error) {
+	var settings DigestSettings
+	if err := c.callJSON(ctx, http.MethodPut, "/v1/me/digest", req, &settings); err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
@@ -0,0 +1,65 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/http"
+)
+
+// BlockDomain stops links to a domain being posted in a group. A "*."
+// prefix blocks its subdomains too.
+func (c *Client) BlockDomain(ctx context.Context, groupID, domain string) (*BlockedDomain, error) {
+	body := struct {
+		Domain string `json:"domain"`
+	}{domain}
+
+	var blocked BlockedDomain
+	if err := c.callJSON(ctx, http.MethodPost, pathf("/v1/groups/%s/blocked-domains", groupID), body, &blocked); err != nil {
+		return nil, err
+	}
+	return &blocked, nil
+}
+
+func (c *Client) ListBlockedDomains(ctx context.Context, groupID string) ([]BlockedDomain, error) {
+	var domains []BlockedDomain
+	err := c.callJSON(ctx, http.MethodGet, pathf("/v1/groups/%s/blocked-domains", groupID), nil, &domains)
+	return domains, err
+}
+
+func (c *Client) UnblockDomain(ctx context.Context, groupID, domain string) error {
+	return c.callJSON(ctx, http.MethodDelete, pathf("/v1/groups/%s/blocked-domains/%s", groupID, domain), nil, nil)
+}
+
+// ReportLink flags a link to its group's moderators
+func (c *Client) ReportLink(ctx context.Context, linkID, reason string) (*LinkReport, error) {
+	body := struct {
+		Reason string `json:"reason"`
+	}{reason}
+
+	var report LinkReport
+	if err := c.callJSON(ctx, http.MethodPost, pathf("/v1/links/%s/report", linkID), body, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// ModerationQueue iterates over a group's reported links, most reported
+// first
+func (c *Client) ModerationQueue(ctx context.Context, groupID string) iter.Seq2[ModerationQueueItem, error] {
+	return offsetPages[ModerationQueueItem](ctx, c, pathf("/v1/groups/%s/moderation/queue", groupID), nil, 100)
+}
+
+// ModerateLink hides, unhides or deletes a link, or dismisses its reports
+func (c *Client) ModerateLink(ctx context.Context, groupID, linkID string, req ModerateLinkRequest) (*ModerationAction, error) {
+	var action ModerationAction
+	path := pathf("/v1/groups/%s/moderation/links/%s", groupID, linkID)
+	if err := c.callJSON(ctx, http.MethodPost, path, req, &action); err != nil {
+		return nil, err
+	}
+	return &action, nil
+}
+
+// ModerationLog iterates over a group's moderation actions, newest first
+func (c *Client) ModerationLog(ctx context.Context, groupID string) iter.Seq2[ModerationAction, error] {
+	return offsetPages[ModerationAction](ctx, c, pathf("/v1/groups/%s/moderation/log", groupID), nil, 200)
+}
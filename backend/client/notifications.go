@@ -0,0 +1,134 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ErrStreamClosed ends a notification stream the server closed, which it
+// does to streams that fall behind. Reconnect and read the inbox for
+// anything created in between.
+var ErrStreamClosed = errors.New("notification stream closed")
+
+// ListNotifications iterates over the caller's inbox, newest first. With
+// unreadOnly it skips notifications already read.
+func (c *Client) ListNotifications(ctx context.Context, unreadOnly bool) iter.Seq2[Notification, error] {
+	query := url.Values{"limit": {"100"}}
+	if unreadOnly {
+		query.Set("unread", "true")
+	}
+	return cursorPages[Notification](ctx, c, "/v1/notifications", query)
+}
+
+// UnreadNotificationCount returns how many notifications the caller has not
+// read yet
+func (c *Client) UnreadNotificationCount(ctx context.Context) (int64, error) {
+	query := url.Values{"unread": {"true"}, "limit": {"1"}}
+
+	var page NotificationsPage
+	if err := c.call(ctx, request{method: http.MethodGet, path: "/v1/notifications", query: query}, &page); err != nil {
+		return 0, err
+	}
+	return page.UnreadCount, nil
+}
+
+// StreamNotifications yields the caller's notifications as they are created,
+// until ctx is done or the connection ends. A lost connection ends the
+// sequence with an error.
+func (c *Client) StreamNotifications(ctx context.Context) iter.Seq2[Notification, error] {
+	return func(yield func(Notification, error) bool) {
+		// The stream stays open for longer than any request timeout
+		streaming := *c
+		if c.HTTPClient != nil {
+			httpClient := *c.HTTPClient
+			httpClient.Timeout = 0
+			streaming.HTTPClient = &httpClient
+		}
+
+		resp, err := streaming.send(ctx, request{method: http.MethodGet, path: "/v1/notifications/stream", accept: "text/event-stream"})
+		if err != nil {
+			yield(Notification{}, err)
+			return
+		}
+		defer resp.Body.Close()
+
+		scanner := bufio.NewScanner(resp.Body)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+		var event, data string
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case line == "":
+				// A blank line ends an event
+				if event == "notification" {
+					var notification Notification
+					if err := json.Unmarshal([]byte(data), &notification); err != nil {
+						yield(Notification{}, fmt.Errorf("decoding notification event: %w", err))
+						return
+					}
+					if !yield(notification, nil) {
+						return
+					}
+				}
+				event, data = "", ""
+			case strings.HasPrefix(line, "event:"):
+				event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+			case strings.HasPrefix(line, "data:"):
+				if data != "" {
+					data += "\n"
+				}
+				data += strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " ")
+			}
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+		if err := scanner.Err(); err != nil {
+			yield(Notification{}, err)
+			return
+		}
+		yield(Notification{}, ErrStreamClosed)
+	}
+}
+
+func (c *Client) MarkNotificationRead(ctx context.Context, notificationID string) (*NotificationsRead, error) {
+	var read NotificationsRead
+	if err := c.callJSON(ctx, http.MethodPost, pathf("/v1/notifications/%s/read", notificationID), nil, &read); err != nil {
+		return nil, err
+	}
+	return &read, nil
+}
+
+func (c *Client) MarkAllNotificationsRead(ctx context.Context) (*NotificationsRead, error) {
+	var read NotificationsRead
+	if err := c.callJSON(ctx, http.MethodPost, "/v1/notifications/read-all", nil, &read); err != nil {
+		return nil, err
+	}
+	return &read, nil
+}
+
+// MuteGroup stops new-link notifications from a group. Mentions still arrive.
+func (c *Client) MuteGroup(ctx context.Context, groupID string) (*GroupMute, error) {
+	var mute GroupMute
+	if err := c.callJSON(ctx, http.MethodPut, pathf("/v1/groups/%s/mute", groupID), nil, &mute); err != nil {
+		return nil, err
+	}
+	return &mute, nil
+}
+
+func (c *Client) UnmuteGroup(ctx context.Context, groupID string) (*GroupMute, error) {
+	var mute GroupMute
+	if err := c.callJSON(ctx, http.MethodDelete, pathf("/v1/groups/%s/mute", groupID), nil, &mute); err != nil {
+		return nil, err
+	}
+	return &mute, nil
+}
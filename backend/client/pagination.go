@@ -0,0 +1,79 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/url"
+	"strconv"
+)
+
+// offsetPages walks an endpoint paginated with limit and offset, yielding
+// each item in turn. The offset advances by the page size rather than the
+// number of items returned, because the server may drop items from a page
+// after paging, such as links to instance-blocked domains. Iteration stops
+// at the first empty page or error.
+func offsetPages[T any](ctx context.Context, c *Client, path string, query url.Values, pageSize int) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		q := cloneValues(query)
+		q.Set("limit", strconv.Itoa(pageSize))
+
+		for offset := 0; ; offset += pageSize {
+			q.Set("offset", strconv.Itoa(offset))
+
+			var page []T
+			if err := c.call(ctx, request{method: "GET", path: path, query: q}, &page); err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			if len(page) == 0 {
+				return
+			}
+
+			for _, item := range page {
+				if !yield(item, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// cursorPages walks an endpoint that returns a PageResponse, following
+// next_cursor until it runs out
+func cursorPages[T any](ctx context.Context, c *Client, path string, query url.Values) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		q := cloneValues(query)
+
+		for {
+			var page struct {
+				Items      []T    `json:"items"`
+				NextCursor string `json:"next_cursor"`
+			}
+			if err := c.call(ctx, request{method: "GET", path: path, query: q}, &page); err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+
+			for _, item := range page.Items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+
+			if page.NextCursor == "" {
+				return
+			}
+			q.Set("cursor", page.NextCursor)
+		}
+	}
+}
+
+func cloneValues(v url.Values) url.Values {
+	out := url.Values{}
+	for key, values := range v {
+		out[key] = append([]string(nil), values...)
+	}
+	return out
+}
@@ -0,0 +1,41 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// GetMe returns the caller's profile
+func (c *Client) GetMe(ctx context.Context) (*Profile, error) {
+	var profile Profile
+	if err := c.callJSON(ctx, http.MethodGet, "/v1/me", nil, &profile); err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// UpdateMe applies patch to the caller's profile as a JSON Merge Patch: keys
+// left out are unchanged and a nil value clears one
+func (c *Client) UpdateMe(ctx context.Context, patch map[string]interface{}) (*Profile, error) {
+	req, err := jsonRequest(http.MethodPatch, "/v1/me", patch)
+	if err != nil {
+		return nil, err
+	}
+	req.contentType = "application/merge-patch+json"
+
+	var profile Profile
+	if err := c.call(ctx, req, &profile); err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// GetUser returns the profile of someone who shares a group with the caller.
+// Anyone else is reported as ErrNotFound.
+func (c *Client) GetUser(ctx context.Context, userID string) (*Profile, error) {
+	var profile Profile
+	if err := c.callJSON(ctx, http.MethodGet, pathf("/v1/users/%s", userID), nil, &profile); err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
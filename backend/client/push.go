@@ -0,0 +1,44 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// GetPushKey returns the VAPID public key browsers subscribe with. Servers
+// without push configured answer with ErrServer.
+func (c *Client) GetPushKey(ctx context.Context) (*PushKey, error) {
+	var key PushKey
+	if err := c.callJSON(ctx, http.MethodGet, "/v1/push/key", nil, &key); err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// SubscribePush registers a push subscription for the caller
+func (c *Client) SubscribePush(ctx context.Context, req CreatePushSubscriptionRequest) (*PushSubscription, error) {
+	var subscription PushSubscription
+	if err := c.callJSON(ctx, http.MethodPost, "/v1/push/subscriptions", req, &subscription); err != nil {
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+func (c *Client) ListPushSubscriptions(ctx context.Context) ([]PushSubscription, error) {
+	var subscriptions []PushSubscription
+	err := c.callJSON(ctx, http.MethodGet, "/v1/push/subscriptions", nil, &subscriptions)
+	return subscriptions, err
+}
+
+func (c *Client) DeletePushSubscription(ctx context.Context, subscriptionID string) error {
+	return c.callJSON(ctx, http.MethodDelete, pathf("/v1/push/subscriptions/%s", subscriptionID), nil, nil)
+}
+
+// TestPushSubscription queues a test notification to one subscription
+func (c *Client) TestPushSubscription(ctx context.Context, subscriptionID string) (*PushSubscription, error) {
+	var subscription PushSubscription
+	if err := c.callJSON(ctx, http.MethodPost, pathf("/v1/push/subscriptions/%s/test", subscriptionID), nil, &subscription); err != nil {
+		return nil, err
+	}
+	return &subscription, nil
+}
@@ -0,0 +1,68 @@
+package client
+
+import (
+	"github.com/egeuysall/cove/internal/models"
+)
+
+// The API's request and response bodies. They alias the server's own types
+// so the two cannot drift apart.
+type (
+	Group         = models.GroupResponse
+	GroupSettings = models.GroupSettings
+
+	Profile = models.ProfileResponse
+	Member  = models.MemberResponse
+
+	Notification      = models.NotificationResponse
+	NotificationsPage = models.NotificationsPageResponse
+	NotificationsRead = models.MarkNotificationsReadResponse
+	GroupMute         = models.GroupMuteResponse
+
+	PushKey                       = models.PushKeyResponse
+	PushSubscriptionKeys          = models.PushSubscriptionKeys
+	CreatePushSubscriptionRequest = models.CreatePushSubscriptionRequest
+	PushSubscription              = models.PushSubscriptionResponse
+
+	CreateInviteRequest = models.CreateInviteRequest
+	Invite              = models.InviteResponse
+
+	CreateLinkRequest        = models.CreateLinkRequest
+	BatchLinkItem            = models.BatchLinkItem
+	CreateLinksBatchRequest  = models.CreateLinksBatchRequest
+	BatchLinkResult          = models.BatchLinkResult
+	CreateLinksBatchResponse = models.CreateLinksBatchResponse
+	Link                     = models.LinkResponse
+	LinkReply                = models.LinkReplyResponse
+	Snapshot                 = models.SnapshotResponse
+	LinkClickStats           = models.LinkClickStatsResponse
+	LinkClickDay             = models.LinkClickDay
+
+	SaveLinkRequest        = models.SaveLinkRequest
+	UpdateSavedLinkRequest = models.UpdateSavedLinkRequest
+	SavedLink              = models.SavedLinkResponse
+
+	DigestSettingsRequest = models.DigestSettingsRequest
+	DigestSettings        = models.DigestSettingsResponse
+
+	CreateWebhookRequest = models.CreateWebhookRequest
+	UpdateWebhookRequest = models.UpdateWebhookRequest
+	Webhook              = models.WebhookResponse
+	WebhookDelivery      = models.WebhookDeliveryResponse
+
+	FeedToken               = models.FeedTokenResponse
+	CreateFeedSourceRequest = models.CreateFeedSourceRequest
+	FeedSource              = models.FeedSourceResponse
+
+	ImportRowError = models.ImportRowError
+	ImportReport   = models.ImportReport
+	ImportJob      = models.ImportJobResponse
+
+	BlockedDomain = models.BlockedDomainResponse
+
+	LinkReport          = models.LinkReportResponse
+	ModerationQueueItem = models.ModerationQueueItem
+	ModerateLinkRequest = models.ModerateLinkRequest
+	ModerationAction    = models.ModerationActionResponse
+
+	ValidationError = models.ValidationError
+)
@@ -0,0 +1,60 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// CreateWebhook registers a webhook on a group. The signing secret is only
+// returned here.
+func (c *Client) CreateWebhook(ctx context.Context, groupID string, req CreateWebhookRequest) (*Webhook, error) {
+	var webhook Webhook
+	if err := c.callJSON(ctx, http.MethodPost, pathf("/v1/groups/%s/webhooks", groupID), req, &webhook); err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+func (c *Client) ListWebhooks(ctx context.Context, groupID string) ([]Webhook, error) {
+	var webhooks []Webhook
+	err := c.callJSON(ctx, http.MethodGet, pathf("/v1/groups/%s/webhooks", groupID), nil, &webhooks)
+	return webhooks, err
+}
+
+func (c *Client) UpdateWebhook(ctx context.Context, groupID, webhookID string, req UpdateWebhookRequest) (*Webhook, error) {
+	var webhook Webhook
+	if err := c.callJSON(ctx, http.MethodPatch, pathf("/v1/groups/%s/webhooks/%s", groupID, webhookID), req, &webhook); err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+func (c *Client) DeleteWebhook(ctx context.Context, groupID, webhookID string) error {
+	return c.callJSON(ctx, http.MethodDelete, pathf("/v1/groups/%s/webhooks/%s", groupID, webhookID), nil, nil)
+}
+
+// ListWebhookDeliveries returns a webhook's most recent deliveries, newest
+// first. A limit of zero uses the server's default of 50; at most 200 are
+// returned.
+func (c *Client) ListWebhookDeliveries(ctx context.Context, groupID, webhookID string, limit int) ([]WebhookDelivery, error) {
+	req := request{method: http.MethodGet, path: pathf("/v1/groups/%s/webhooks/%s/deliveries", groupID, webhookID)}
+	if limit > 0 {
+		req.query = url.Values{"limit": {strconv.Itoa(limit)}}
+	}
+
+	var deliveries []WebhookDelivery
+	err := c.call(ctx, req, &deliveries)
+	return deliveries, err
+}
+
+// RedeliverWebhookDelivery queues a copy of a past delivery and returns it
+func (c *Client) RedeliverWebhookDelivery(ctx context.Context, groupID, webhookID, deliveryID string) (*WebhookDelivery, error) {
+	var delivery WebhookDelivery
+	path := pathf("/v1/groups/%s/webhooks/%s/deliveries/%s/redeliver", groupID, webhookID, deliveryID)
+	if err := c.callJSON(ctx, http.MethodPost, path, nil, &delivery); err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
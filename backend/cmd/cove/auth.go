@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// session is Supabase's answer to a token grant
+type session struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
+}
+
+func runLogin(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("login", flag.ContinueOnError)
+	apiURL := fs.String("api", os.Getenv("COVE_API_URL"), "Cove API URL")
+	supabaseURL := fs.String("supabase-url", os.Getenv("COVE_SUPABASE_URL"), "Supabase project URL")
+	anonKey := fs.String("anon-key", os.Getenv("COVE_SUPABASE_ANON_KEY"), "Supabase anon key")
+	email := fs.String("email", "", "account email")
+	token := fs.Bool("token", false, "paste an access token instead of signing in with a password")
+	if _, err := parseArgs(fs, args); err != nil {
+		return err
+	}
+
+	// Keep settings from an earlier login unless overridden
+	cfg, err := loadConfig()
+	if err != nil {
+		cfg = &config{}
+	}
+	if *apiURL != "" {
+		cfg.APIURL = *apiURL
+	}
+	if *supabaseURL != "" {
+		cfg.SupabaseURL = strings.TrimRight(*supabaseURL, "/")
+	}
+	if *anonKey != "" {
+		cfg.AnonKey = *anonKey
+	}
+	if cfg.APIURL == "" {
+		return errors.New("the API URL is required: pass --api or set COVE_API_URL")
+	}
+
+	stdin := bufio.NewReader(os.Stdin)
+
+	if *token {
+		// Accounts signed up through OAuth have no password; their token
+		// can be copied from the web app
+		accessToken, err := prompt(stdin, "Access token: ", true)
+		if err != nil {
+			return err
+		}
+		cfg.AccessToken = accessToken
+		cfg.RefreshToken = ""
+		cfg.ExpiresAt = time.Time{}
+	} else {
+		if cfg.SupabaseURL == "" || cfg.AnonKey == "" {
+			return errors.New("signing in needs --supabase-url and --anon-key, or use --token")
+		}
+		if *email == "" {
+			if *email, err = prompt(stdin, "Email: ", false); err != nil {
+				return err
+			}
+		}
+		password, err := prompt(stdin, "Password: ", true)
+		if err != nil {
+			return err
+		}
+
+		s, err := grantToken(ctx, cfg, "password", map[string]string{"email": *email, "password": password})
+		if err != nil {
+			return err
+		}
+		cfg.setSession(s)
+	}
+
+	if err := cfg.save(); err != nil {
+		return fmt.Errorf("saving login: %w", err)
+	}
+
+	fmt.Fprintln(os.Stderr, "Logged in.")
+	return nil
+}
+
+func runLogout() error {
+	if err := removeConfig(); err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stderr, "Logged out.")
+	return nil
+}
+
+func (cfg *config) setSession(s *session) {
+	cfg.AccessToken = s.AccessToken
+	cfg.RefreshToken = s.RefreshToken
+	cfg.ExpiresAt = time.Now().Add(time.Duration(s.ExpiresIn) * time.Second)
+}
+
+// refresh swaps the refresh token for a new access token shortly before the
+// old one expires, and saves the result
+func (cfg *config) refresh(ctx context.Context) error {
+	if cfg.RefreshToken == "" || cfg.ExpiresAt.IsZero() || time.Until(cfg.ExpiresAt) > time.Minute {
+		return nil
+	}
+
+	s, err := grantToken(ctx, cfg, "refresh_token", map[string]string{"refresh_token": cfg.RefreshToken})
+	if err != nil {
+		return fmt.Errorf("refreshing login (run `cove login` again): %w", err)
+	}
+	cfg.setSession(s)
+	return cfg.save()
+}
+
+// grantToken calls Supabase Auth's token endpoint
+func grantToken(ctx context.Context, cfg *config, grantType string, body map[string]string) (*session, error) {
+	encoded, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	u := cfg.SupabaseURL + "/auth/v1/token?grant_type=" + grantType
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(encoded))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("apikey", cfg.AnonKey)
+
+	resp, err := (&http.Client{Timeout: 30 * time.Second}).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		// Supabase has answered with both shapes over time
+		var failure struct {
+			Description string `json:"error_description"`
+			Msg         string `json:"msg"`
+		}
+		json.NewDecoder(resp.Body).Decode(&failure)
+		if failure.Description != "" {
+			return nil, errors.New(failure.Description)
+		}
+		if failure.Msg != "" {
+			return nil, errors.New(failure.Msg)
+		}
+		return nil, fmt.Errorf("sign in failed: %s", resp.Status)
+	}
+
+	var s session
+	if err := json.NewDecoder(resp.Body).Decode(&s); err != nil {
+		return nil, fmt.Errorf("decoding session: %w", err)
+	}
+	return &s, nil
+}
+
+// prompt reads a line from stdin, without echo for secrets when stdin is a
+// terminal
+func prompt(stdin *bufio.Reader, label string, secret bool) (string, error) {
+	fmt.Fprint(os.Stderr, label)
+
+	if secret && term.IsTerminal(int(os.Stdin.Fd())) {
+		value, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Fprintln(os.Stderr)
+		return strings.TrimSpace(string(value)), err
+	}
+
+	line, err := stdin.ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
@@ -0,0 +1,289 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/egeuysall/cove/client"
+	"github.com/google/uuid"
+)
+
+func runGroups(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("groups", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print JSON")
+	if _, err := parseArgs(fs, args); err != nil {
+		return err
+	}
+
+	c, err := newClient(ctx)
+	if err != nil {
+		return err
+	}
+	groups, err := c.ListGroups(ctx)
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]string, 0, len(groups))
+	for _, g := range groups {
+		rows = append(rows, []string{g.ID, g.Name, g.CreatedAt.Local().Format("2006-01-02")})
+	}
+	return newPrinter(*asJSON).print(groups, []string{"ID", "NAME", "CREATED"}, rows)
+}
+
+func runPost(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("post", flag.ContinueOnError)
+	group := fs.String("g", "", "group ID or name; separate several with commas to cross-post")
+	comment := fs.String("m", "", "comment")
+	title := fs.String("t", "", "title; fetched from the page when empty")
+	asJSON := fs.Bool("json", false, "print JSON")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 || *group == "" {
+		return errors.New("usage: cove post <url> -g <group>[,<group>...] [-m comment] [-t title]")
+	}
+
+	c, err := newClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	var groupIDs []string
+	for _, name := range strings.Split(*group, ",") {
+		groupID, err := resolveGroup(ctx, c, strings.TrimSpace(name))
+		if err != nil {
+			return err
+		}
+		groupIDs = append(groupIDs, groupID)
+	}
+
+	if len(groupIDs) > 1 {
+		return postToGroups(ctx, c, positional[0], *title, *comment, groupIDs, newPrinter(*asJSON))
+	}
+
+	link, err := c.CreateLink(ctx, client.CreateLinkRequest{
+		GroupID: groupIDs[0],
+		URL:     positional[0],
+		Title:   *title,
+		Comment: *comment,
+	})
+	if errors.Is(err, client.ErrConflict) {
+		return errors.New("that link has already been posted in this group")
+	}
+	if err != nil {
+		return err
+	}
+
+	rows := [][]string{{link.ID, truncate(link.Title, 50), link.URL, link.ShortURL}}
+	return newPrinter(*asJSON).print(link, []string{"ID", "TITLE", "URL", "SHORT URL"}, rows)
+}
+
+// postToGroups cross-posts one link in a single batch request
+func postToGroups(ctx context.Context, c *client.Client, url, title, comment string, groupIDs []string, p printer) error {
+	batch, err := c.CreateLinks(ctx, client.CreateLinksBatchRequest{
+		Links:    []client.BatchLinkItem{{URL: url, Title: title, Comment: comment}},
+		GroupIDs: groupIDs,
+	})
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]string, 0, len(batch.Results))
+	for _, result := range batch.Results {
+		detail := result.Error
+		if result.Link != nil && result.Status == "created" {
+			detail = result.Link.ShortURL
+		}
+		rows = append(rows, []string{result.GroupID, result.Status, detail})
+	}
+	if err := p.print(batch, []string{"GROUP", "STATUS", "DETAIL"}, rows); err != nil {
+		return err
+	}
+
+	if batch.Failed > 0 {
+		return fmt.Errorf("%d of %d posts failed", batch.Failed, len(batch.Results))
+	}
+	return nil
+}
+
+func runFeed(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("feed", flag.ContinueOnError)
+	limit := fs.Int("n", 20, "number of links to show")
+	status := fs.String("status", "", "only links the checker marked unchecked, ok, failing or dead")
+	query := fs.String("q", "", "search titles, comments and page text")
+	asJSON := fs.Bool("json", false, "print JSON")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return errors.New("usage: cove feed <group> [-n count] [-q search] [--status status]")
+	}
+
+	c, err := newClient(ctx)
+	if err != nil {
+		return err
+	}
+	groupID, err := resolveGroup(ctx, c, positional[0])
+	if err != nil {
+		return err
+	}
+
+	links := []client.Link{}
+	opts := client.ListLinksOptions{Status: *status, Query: *query}
+	for link, err := range c.ListGroupLinks(ctx, groupID, opts) {
+		if err != nil {
+			return err
+		}
+		if len(links) >= *limit {
+			break
+		}
+		links = append(links, link)
+	}
+
+	rows := make([][]string, 0, len(links))
+	for _, l := range links {
+		title := l.Title
+		if title == "" {
+			title = l.URL
+		}
+		rows = append(rows, []string{ago(l.CreatedAt), posterName(l), truncate(title, 50), l.URL, truncate(l.Comment, 40)})
+	}
+	return newPrinter(*asJSON).print(links, []string{"POSTED", "BY", "TITLE", "URL", "COMMENT"}, rows)
+}
+
+func runInvite(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("invite", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print JSON")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 2 || (positional[0] != "create" && positional[0] != "accept") {
+		return errors.New("usage: cove invite create <group> | cove invite accept <code>")
+	}
+
+	c, err := newClient(ctx)
+	if err != nil {
+		return err
+	}
+	p := newPrinter(*asJSON)
+
+	if positional[0] == "accept" {
+		if err := c.AcceptInvite(ctx, positional[1]); err != nil {
+			if errors.Is(err, client.ErrNotFound) {
+				return errors.New("that invite does not exist or has already been used")
+			}
+			return err
+		}
+		if p.json {
+			return p.print(map[string]string{"message": "Successfully joined group"}, nil, nil)
+		}
+		fmt.Fprintln(p.out, "Joined the group.")
+		return nil
+	}
+
+	groupID, err := resolveGroup(ctx, c, positional[1])
+	if err != nil {
+		return err
+	}
+	invite, err := c.CreateInvite(ctx, groupID)
+	if err != nil {
+		return err
+	}
+	return p.print(invite, []string{"CODE", "GROUP"}, [][]string{{invite.Code, invite.GroupID}})
+}
+
+func runExport(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	format := fs.String("f", "json", "format: json, ndjson, csv, html or zip")
+	output := fs.String("o", "-", "file to write, or - for standard output")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return errors.New("usage: cove export <group> [-f format] [-o file]")
+	}
+
+	c, err := newClient(ctx)
+	if err != nil {
+		return err
+	}
+	groupID, err := resolveGroup(ctx, c, positional[0])
+	if err != nil {
+		return err
+	}
+
+	body, err := c.ExportGroup(ctx, groupID, *format)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	if *output == "-" {
+		_, err = io.Copy(os.Stdout, body)
+		return err
+	}
+
+	f, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, body); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Exported to %s\n", *output)
+	return nil
+}
+
+// resolveGroup accepts a group ID, or the name of one of the caller's groups
+func resolveGroup(ctx context.Context, c *client.Client, group string) (string, error) {
+	if _, err := uuid.Parse(group); err == nil {
+		return group, nil
+	}
+
+	groups, err := c.ListGroups(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	var matches []client.Group
+	for _, g := range groups {
+		if strings.EqualFold(g.Name, group) {
+			matches = append(matches, g)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("you are not in a group named %q", group)
+	case 1:
+		return matches[0].ID, nil
+	}
+	return "", fmt.Errorf("%d of your groups are named %q; use its ID from `cove groups`", len(matches), group)
+}
+
+// posterName is who posted a link as the feed shows it: their display name,
+// else a short form of their user ID, or "feed" for links a bot posted
+func posterName(l client.Link) string {
+	switch {
+	case l.Poster != nil && l.Poster.DisplayName != "":
+		return truncate(l.Poster.DisplayName, 20)
+	case l.UserID != "":
+		return l.UserID[:8]
+	default:
+		return "feed"
+	}
+}
@@ -0,0 +1,110 @@
+// Command cove shares and browses Cove links from the terminal.
+//
+//	cove login --api https://api.example.com --supabase-url ... --anon-key ...
+//	cove groups
+//	cove post https://go.dev/blog -g friends,work -m "worth a read"
+//	cove feed friends
+//	cove invite create friends
+//	cove invite accept <code>
+//	cove export friends -f zip -o friends.zip
+//
+// Every command that prints takes --json for machine-readable output.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/egeuysall/cove/client"
+)
+
+const usage = `Usage: cove <command> [arguments]
+
+Commands:
+  login                  sign in and store a token in the user config directory
+  logout                 forget the stored token
+  groups                 list your groups
+  post <url> -g <group>  share a link; -m adds a comment, -t a title, and
+                         -g takes several groups separated by commas
+  feed <group>           show a group's latest links; -n sets how many
+  invite create <group>  create an invite code
+  invite accept <code>   join a group with an invite code
+  export <group>         export a group; -f picks the format, -o the file
+
+Groups may be given by ID or by name. Add --json to print JSON.
+`
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := run(ctx, os.Args[1:]); err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintln(os.Stderr, "cove:", err)
+		}
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string) error {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		fmt.Fprint(os.Stderr, usage)
+		return nil
+	}
+
+	command, args := args[0], args[1:]
+	switch command {
+	case "login":
+		return runLogin(ctx, args)
+	case "logout":
+		return runLogout()
+	case "groups":
+		return runGroups(ctx, args)
+	case "post":
+		return runPost(ctx, args)
+	case "feed":
+		return runFeed(ctx, args)
+	case "invite":
+		return runInvite(ctx, args)
+	case "export":
+		return runExport(ctx, args)
+	}
+
+	fmt.Fprint(os.Stderr, usage)
+	return fmt.Errorf("unknown command %q", command)
+}
+
+// newClient builds an API client from the stored login, refreshing its
+// token first if it is about to expire
+func newClient(ctx context.Context) (*client.Client, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.refresh(ctx); err != nil {
+		return nil, err
+	}
+	return client.New(cfg.APIURL, cfg.AccessToken), nil
+}
+
+// parseArgs parses flags wherever they appear among the positional
+// arguments, which the flag package alone stops at, and returns the
+// positional arguments
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
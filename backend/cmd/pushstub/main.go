@@ -0,0 +1,76 @@
+// Command pushstub is a stand-in push service for developing Web Push
+// locally. It makes up a subscription, prints it, and then checks the VAPID
+// token on every message it is sent and prints the decrypted payload.
+//
+//	go run ./cmd/pushstub
+//	go run ./cmd/pushstub -status 410   # answer as if the subscription expired
+//
+// Run the server with VAPID_PRIVATE_KEY set and PUSH_ALLOW_LOCAL_ENDPOINTS=true,
+// then POST the printed subscription to /v1/push/subscriptions.
+package main
+
+import (
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/egeuysall/cove/internal/models"
+	"github.com/egeuysall/cove/internal/webpush"
+)
+
+func main() {
+	addr := flag.String("addr", "127.0.0.1:8095", "address to listen on")
+	status := flag.Int("status", http.StatusCreated, "status to answer messages with")
+	flag.Parse()
+
+	private, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	authSecret := make([]byte, 16)
+	token := make([]byte, 12)
+	if _, err := rand.Read(authSecret); err != nil {
+		log.Fatal(err)
+	}
+	if _, err := rand.Read(token); err != nil {
+		log.Fatal(err)
+	}
+
+	path := "/push/" + base64.RawURLEncoding.EncodeToString(token)
+	subscription := models.CreatePushSubscriptionRequest{
+		Endpoint: "http://" + *addr + path,
+		Keys: models.PushSubscriptionKeys{
+			P256dh: base64.RawURLEncoding.EncodeToString(private.PublicKey().Bytes()),
+			Auth:   base64.RawURLEncoding.EncodeToString(authSecret),
+		},
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	fmt.Println("Subscription:")
+	if err := encoder.Encode(subscription); err != nil {
+		log.Fatal(err)
+	}
+
+	http.HandleFunc("POST "+path, func(w http.ResponseWriter, r *http.Request) {
+		payload, err := webpush.Receive(r, private, authSecret)
+		if err != nil {
+			log.Printf("rejected message: %v", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		log.Printf("message (TTL %s, urgency %s): %s", r.Header.Get("TTL"), r.Header.Get("Urgency"), payload)
+		w.WriteHeader(*status)
+	})
+
+	log.Printf("Push stub listening on http://%s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, nil))
+}
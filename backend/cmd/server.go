@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"github.com/egeuysall/cove/internal/utils"
 	"log"
@@ -8,8 +9,22 @@ import (
 	"os"
 
 	"github.com/egeuysall/cove/internal/api"
+	"github.com/egeuysall/cove/internal/blocklist"
+	"github.com/egeuysall/cove/internal/clicks"
+	"github.com/egeuysall/cove/internal/digest"
+	"github.com/egeuysall/cove/internal/feedsources"
+	"github.com/egeuysall/cove/internal/groups"
+	"github.com/egeuysall/cove/internal/idempotency"
+	"github.com/egeuysall/cove/internal/linkcheck"
+	"github.com/egeuysall/cove/internal/links"
+	"github.com/egeuysall/cove/internal/notifications"
+	"github.com/egeuysall/cove/internal/openapi"
+	"github.com/egeuysall/cove/internal/ratelimit"
+	"github.com/egeuysall/cove/internal/snapshot"
 	supabase "github.com/egeuysall/cove/internal/supabase"
 	generated "github.com/egeuysall/cove/internal/supabase/generated"
+	"github.com/egeuysall/cove/internal/webhooks"
+	"github.com/egeuysall/cove/internal/webpush"
 	"github.com/joho/godotenv"
 )
 
@@ -24,10 +39,45 @@ func main() {
 
 	queries := generated.New(dbConn)
 
-	utils.Init(queries)
+	utils.Init(dbConn, queries)
+
+	if path := os.Getenv("BLOCKLIST_FILE"); path != "" {
+		go blocklist.NewWatcher(path).Start(context.Background())
+	}
+
+	go links.BackfillHosts(context.Background(), queries)
+	go webhooks.NewDispatcher(queries).Start(context.Background())
+	go feedsources.NewPoller(queries, nil).Start(context.Background())
+	go linkcheck.NewChecker(queries, nil).Start(context.Background())
+	go snapshot.NewArchiver(queries, nil).Start(context.Background())
+	go clicks.NewFlusher(queries).Start(context.Background())
+	go ratelimit.NewPruner(queries).Start(context.Background())
+	go idempotency.NewPruner(queries).Start(context.Background())
+	go groups.NewRetentionPruner(queries).Start(context.Background())
+	go notifications.NewPruner(queries).Start(context.Background())
+
+	if digestCfg, ok := digest.ConfigFromEnv(); ok {
+		worker := digest.NewWorker(digestCfg, queries, digest.NewSMTPMailer(digestCfg))
+		go worker.Start(context.Background())
+		log.Printf("Digest worker started, sending through %s:%s", digestCfg.SMTPHost, digestCfg.SMTPPort)
+	}
+
+	pushCfg, ok, err := webpush.ConfigFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if ok {
+		go webpush.NewDispatcher(pushCfg, queries, nil).Start(context.Background())
+		go webpush.NewPruner(queries).Start(context.Background())
+		log.Printf("Web Push dispatcher started with VAPID key %s", pushCfg.PublicKey)
+	}
 
 	router := api.Router()
 
+	if err := openapi.CheckRoutes(router); err != nil {
+		log.Fatal(err)
+	}
+
 	portStr := os.Getenv("PORT")
 
 	if portStr == "" {
@@ -5,11 +5,18 @@ import (
 
 	"github.com/egeuysall/cove/internal/handlers"
 	appmid "github.com/egeuysall/cove/internal/middleware"
+	"github.com/egeuysall/cove/internal/openapi"
+	"github.com/egeuysall/cove/internal/ratelimit"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
-	"github.com/go-chi/httprate"
 )
 
+// requestTimeout bounds every route except streaming ones
+const requestTimeout = 3 * time.Second
+
+// batchRequestTimeout bounds link batches
+const batchRequestTimeout = 15 * time.Second
+
 func Router() *chi.Mux {
 	r := chi.NewRouter()
 
@@ -17,44 +24,157 @@ func Router() *chi.Mux {
 	r.Use(
 		middleware.Recoverer,
 		middleware.RealIP,
-		middleware.Timeout(3*time.Second),
-		middleware.NoCache,
 		middleware.Compress(5),
-		httprate.LimitByIP(30, time.Minute),
 		appmid.SetContentType(),
 		appmid.Cors(),
 	)
 
+	// Requests are checked against the spec only once they are authenticated
+	// and counted, so anonymous callers cannot probe the schema for free and
+	// invalid requests still use up the caller's limit
+	validate := appmid.ValidateRequest(openapi.Doc)
+
+	r.With(appmid.RateLimit(ratelimit.Public)).Get("/openapi.json", openapi.HandleSpec)
+
+	// Feed readers poll with conditional requests, which NoCache would strip.
+	// FeedToken runs first so readers sharing an IP are limited per token.
+	r.With(middleware.Timeout(requestTimeout), appmid.FeedToken(), appmid.RateLimit(ratelimit.Public), validate).Get("/feeds/groups/{id}.{format}", handlers.HandleGroupFeed)
+
+	// Proxied images carry their own cache headers; the fetch is bounded by
+	// the proxy's client timeout rather than the request timeout
+	r.With(appmid.RateLimit(ratelimit.Public), validate).Get("/img/{signature}/{encoded}", handlers.HandleImageProxy)
+
 	// Public routes
-	r.Get("/", handlers.HandleRoot)
-	r.Get("/ping", handlers.HandlePing)
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.NoCache)
+		r.Use(middleware.Timeout(requestTimeout))
+		r.Use(appmid.RateLimit(ratelimit.Public))
+		r.Use(validate)
+
+		r.Get("/", handlers.HandleRoot)
+		r.Get("/ping", handlers.HandlePing)
+		r.Get("/digest/unsubscribe", handlers.HandleDigestUnsubscribePage)
+		r.Post("/digest/unsubscribe", handlers.HandleDigestUnsubscribe)
+		r.Get("/r/{shortID}", handlers.HandleShortLinkRedirect)
+	})
 
-	// Protected API v1 routes
+	// Protected API v1 routes. Nothing is cached by default; GETs that answer
+	// conditional requests opt into revalidation instead.
 	r.Route("/v1", func(r chi.Router) {
 		r.Use(appmid.RequireAuth())
+		r.Use(appmid.RateLimitByMethod(ratelimit.Read, ratelimit.Write))
+		r.Use(validate)
+		r.Use(appmid.Idempotency())
+		r.Use(appmid.CacheControl(appmid.CacheNoStore))
+
+		// Exports stream for as long as the group takes, and notification
+		// streams for as long as the client stays, so both skip the request
+		// timeout
+		r.Get("/groups/{id}/export", handlers.HandleExportGroup)
+		r.Get("/notifications/stream", handlers.HandleNotificationStream)
+
+		// Batches expand every URL they post, which takes longer than one link
+		r.With(middleware.Timeout(batchRequestTimeout), appmid.RateLimit(ratelimit.CreateLink)).Post("/links/batch", handlers.HandleCreateLinksBatch)
+
+		revalidate := appmid.CacheControl(appmid.CacheRevalidate)
+
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.Timeout(requestTimeout))
+
+			// Groups
+			r.Post("/groups", handlers.HandleCreateGroup)
+			r.With(revalidate).Get("/groups", handlers.HandleGetGroupsByUser)
+			r.Get("/groups/{id}", handlers.HandleGetGroupById)
+			r.Patch("/groups/{id}", handlers.HandleUpdateGroup)
+			r.Delete("/groups/{id}", handlers.HandleDeleteGroup)
+
+			// Group Members
+			r.Post("/groups/{id}/members", handlers.HandleAddUserToGroup)
+			r.With(revalidate).Get("/groups/{id}/members", handlers.HandleGetGroupMembers)
+			r.Put("/groups/{id}/mute", handlers.HandleMuteGroup)
+			r.Delete("/groups/{id}/mute", handlers.HandleUnmuteGroup)
+
+			// Invites
+			r.Post("/invites", handlers.HandleCreateInvite)
+			r.Get("/invites/{code}", handlers.HandleGetInviteByCode)
+			r.Post("/invites/{code}/accept", handlers.HandleAcceptInviteByCode)
+			r.Get("/groups/{id}/invites", handlers.HandleGetInvitesByGroup)
+
+			// Feed tokens
+			r.Post("/groups/{id}/feed-tokens", handlers.HandleCreateFeedToken)
+			r.Get("/groups/{id}/feed-tokens", handlers.HandleGetFeedTokens)
+			r.Delete("/groups/{id}/feed-tokens/{tid}", handlers.HandleRevokeFeedToken)
+
+			// External feed sources
+			r.Post("/groups/{id}/sources", handlers.HandleCreateFeedSource)
+			r.Get("/groups/{id}/sources", handlers.HandleGetFeedSources)
+			r.Delete("/groups/{id}/sources/{sid}", handlers.HandleDeleteFeedSource)
+
+			// Webhooks
+			r.Post("/groups/{id}/webhooks", handlers.HandleCreateWebhook)
+			r.Get("/groups/{id}/webhooks", handlers.HandleGetWebhooks)
+			r.Patch("/groups/{id}/webhooks/{hid}", handlers.HandleUpdateWebhook)
+			r.Delete("/groups/{id}/webhooks/{hid}", handlers.HandleDeleteWebhook)
+			r.Get("/groups/{id}/webhooks/{hid}/deliveries", handlers.HandleGetWebhookDeliveries)
+			r.Post("/groups/{id}/webhooks/{hid}/deliveries/{did}/redeliver", handlers.HandleRedeliverWebhookDelivery)
+
+			// Domain blocklist
+			r.Post("/groups/{id}/blocked-domains", handlers.HandleBlockDomain)
+			r.Get("/groups/{id}/blocked-domains", handlers.HandleGetBlockedDomains)
+			r.Delete("/groups/{id}/blocked-domains/{domain}", handlers.HandleUnblockDomain)
+
+			// Moderation
+			r.Get("/groups/{id}/moderation/queue", handlers.HandleGetModerationQueue)
+			r.Post("/groups/{id}/moderation/links/{linkID}", handlers.HandleModerateLink)
+			r.Get("/groups/{id}/moderation/log", handlers.HandleGetModerationLog)
+
+			// Links
+			r.With(appmid.RateLimit(ratelimit.CreateLink)).Post("/links", handlers.HandleCreateLink)
+			r.Get("/links/{id}", handlers.HandleGetLinkById)
+			r.Get("/links/{id}/snapshot", handlers.HandleGetLinkSnapshot)
+			r.Get("/links/{id}/clicks", handlers.HandleGetLinkClicks)
+			r.Post("/links/{id}/report", handlers.HandleReportLink)
+			r.With(revalidate).Get("/groups/{groupID}/links", handlers.HandleGetLinksByGroup)
+			r.Patch("/links/{id}", handlers.HandleUpdateLinkComment)
+			r.Delete("/links/{id}", handlers.HandleDeleteLink)
+
+			// Replies
+			r.Post("/links/{id}/replies", handlers.HandleCreateLinkReply)
+			r.Get("/links/{id}/replies", handlers.HandleGetLinkReplies)
+			r.Delete("/links/{id}/replies/{rid}", handlers.HandleDeleteLinkReply)
+
+			// Imports
+			r.With(appmid.RateLimit(ratelimit.Import)).Post("/groups/{id}/import", handlers.HandleImportLinks)
+			r.Get("/groups/{id}/import/{jobID}", handlers.HandleGetImportJob)
+
+			// Profiles
+			r.Get("/me", handlers.HandleGetMe)
+			r.Patch("/me", handlers.HandleUpdateMe)
+			r.Get("/users/{id}", handlers.HandleGetUser)
+
+			// Notifications
+			r.Get("/notifications", handlers.HandleGetNotifications)
+			r.Post("/notifications/read-all", handlers.HandleMarkAllNotificationsRead)
+			r.Post("/notifications/{id}/read", handlers.HandleMarkNotificationRead)
+
+			// Web Push
+			r.Get("/push/key", handlers.HandleGetPushKey)
+			r.Post("/push/subscriptions", handlers.HandleCreatePushSubscription)
+			r.Get("/push/subscriptions", handlers.HandleGetPushSubscriptions)
+			r.Delete("/push/subscriptions/{id}", handlers.HandleDeletePushSubscription)
+			r.Post("/push/subscriptions/{id}/test", handlers.HandleTestPushSubscription)
+
+			// Saved links (personal reading list)
+			r.Post("/me/saved", handlers.HandleSaveLink)
+			r.Get("/me/saved", handlers.HandleGetSavedLinks)
+			r.Get("/me/saved/{id}", handlers.HandleGetSavedLink)
+			r.Patch("/me/saved/{id}", handlers.HandleUpdateSavedLink)
+			r.Delete("/me/saved/{id}", handlers.HandleDeleteSavedLink)
 
-		// Groups
-		r.Post("/groups", handlers.HandleCreateGroup)
-		r.Get("/groups", handlers.HandleGetGroupsByUser)
-		r.Get("/groups/{id}", handlers.HandleGetGroupById)
-		r.Delete("/groups/{id}", handlers.HandleDeleteGroup)
-
-		// Group Members
-		r.Post("/groups/{id}/members", handlers.HandleAddUserToGroup)
-		r.Get("/groups/{id}/members", handlers.HandleGetGroupMembers)
-
-		// Invites
-		r.Post("/invites", handlers.HandleCreateInvite)
-		r.Get("/invites/{code}", handlers.HandleGetInviteByCode)
-		r.Post("/invites/{code}/accept", handlers.HandleAcceptInviteByCode)
-		r.Get("/groups/{id}/invites", handlers.HandleGetInvitesByGroup)
-
-		// Links
-		r.Post("/links", handlers.HandleCreateLink)
-		r.Get("/links/{id}", handlers.HandleGetLinkById)
-		r.Get("/groups/{groupID}/links", handlers.HandleGetLinksByGroup)
-		r.Patch("/links/{id}", handlers.HandleUpdateLinkComment)
-		r.Delete("/links/{id}", handlers.HandleDeleteLink)
+			// Email digests
+			r.Get("/me/digest", handlers.HandleGetDigestSettings)
+			r.Put("/me/digest", handlers.HandleUpdateDigestSettings)
+		})
 	})
 
 	return r
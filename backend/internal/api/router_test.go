@@ -0,0 +1,60 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/egeuysall/cove/internal/api"
+	"github.com/egeuysall/cove/internal/openapi"
+)
+
+func TestRoutesMatchSpec(t *testing.T) {
+	err := openapi.CheckRoutes(api.Router())
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUnauthenticatedRequestsAreNotValidated(t *testing.T) {
+	t.Setenv("SUPABASE_JWT_SECRET", "test-secret")
+
+	// The body is missing every required field, which would be a 400 if the
+	// request were validated before authentication
+	req := httptest.NewRequest(http.MethodPost, "/v1/links", strings.NewReader(`{"unknown": true}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	api.Router().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusUnauthorized, rec.Body)
+	}
+	if strings.Contains(rec.Body.String(), "specification") {
+		t.Errorf("unauthenticated response describes the schema: %s", rec.Body)
+	}
+}
+
+func TestCorsExposesIdempotencyHeaders(t *testing.T) {
+	t.Setenv("SUPABASE_JWT_SECRET", "test-secret")
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/links", strings.NewReader(`{}`))
+	req.Header.Set("Origin", "http://localhost:3000")
+	rec := httptest.NewRecorder()
+
+	api.Router().ServeHTTP(rec, req)
+
+	exposed := strings.Split(rec.Header().Get("Access-Control-Expose-Headers"), ", ")
+	for _, header := range []string{"Idempotent-Replayed", "Idempotent-In-Progress", "Retry-After"} {
+		found := false
+		for _, h := range exposed {
+			if strings.EqualFold(h, header) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("%s is not exposed to browsers: %q", header, exposed)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package blocklist
+
+import (
+	"errors"
+	"net/netip"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// ErrInvalidDomain is returned for entries that are not a plain host name
+var ErrInvalidDomain = errors.New("invalid domain")
+
+// Normalize turns a blocklist entry into its canonical form: lowercase,
+// punycode, without a trailing dot. A leading "*." means the entry covers
+// every subdomain as well, which is reported separately.
+func Normalize(entry string) (domain string, includeSubdomains bool, err error) {
+	domain = strings.ToLower(strings.TrimSpace(entry))
+	if rest, ok := strings.CutPrefix(domain, "*."); ok {
+		domain = rest
+		includeSubdomains = true
+	}
+	domain = strings.TrimSuffix(domain, ".")
+
+	if domain == "" || strings.ContainsAny(domain, "/:@*?# ") {
+		return "", false, ErrInvalidDomain
+	}
+	if _, err := netip.ParseAddr(domain); err == nil {
+		// IP literals have no subdomains
+		return domain, false, nil
+	}
+
+	domain, err = idna.Lookup.ToASCII(domain)
+	if err != nil || !strings.Contains(domain, ".") {
+		return "", false, ErrInvalidDomain
+	}
+
+	return domain, includeSubdomains, nil
+}
+
+// Host extracts the normalized host of rawURL, or "" when there is none
+func Host(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(parsed.Hostname()), ".")
+	if ascii, err := idna.Lookup.ToASCII(host); err == nil {
+		host = ascii
+	}
+	return host
+}
@@ -0,0 +1,176 @@
+package blocklist
+
+import (
+	"bufio"
+	"context"
+	"log"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	supabase "github.com/egeuysall/cove/internal/supabase/generated"
+)
+
+// reloadInterval is how often the instance file is checked for changes
+const reloadInterval = 30 * time.Second
+
+// List is an immutable set of instance-wide blocked domains. Every entry
+// covers its subdomains too, since operators block whole sites.
+type List struct {
+	domains map[string]struct{}
+	version int64
+}
+
+var current atomic.Pointer[List]
+
+// Blocked returns the instance entry that host falls under, walking up its
+// labels, or "" when it is allowed
+func (l *List) Blocked(host string) string {
+	if l == nil || host == "" {
+		return ""
+	}
+
+	for candidate := host; ; {
+		if _, ok := l.domains[candidate]; ok {
+			return candidate
+		}
+
+		dot := strings.IndexByte(candidate, '.')
+		if dot < 0 {
+			return ""
+		}
+		candidate = candidate[dot+1:]
+	}
+}
+
+// Len is the number of domains on the list
+func (l *List) Len() int {
+	if l == nil {
+		return 0
+	}
+	return len(l.domains)
+}
+
+// Version changes whenever a new list is loaded, for use in ETags
+func (l *List) Version() int64 {
+	if l == nil {
+		return 0
+	}
+	return l.version
+}
+
+// Current is the instance list in effect, which may be empty
+func Current() *List {
+	return current.Load()
+}
+
+// BlockedByInstance checks rawURL's host against the current instance list
+func BlockedByInstance(rawURL string) string {
+	return Current().Blocked(Host(rawURL))
+}
+
+// Parse reads one domain per line. Blank lines, "#" comments and the
+// address column of hosts-file style lines ("0.0.0.0 example.com") are
+// ignored, as are entries that do not normalize.
+func Parse(contents string) *List {
+	list := &List{domains: make(map[string]struct{})}
+
+	scanner := bufio.NewScanner(strings.NewReader(contents))
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		entry := fields[len(fields)-1]
+
+		domain, _, err := Normalize(entry)
+		if err != nil || domain == "localhost" {
+			continue
+		}
+		list.domains[domain] = struct{}{}
+	}
+
+	return list
+}
+
+// Watcher keeps the instance list in sync with a file on disk, so operators
+// can edit it without restarting the server
+type Watcher struct {
+	path     string
+	interval time.Duration
+	modTime  time.Time
+	size     int64
+}
+
+// NewWatcher builds a watcher for path. The file is loaded straight away so
+// the list is in effect before the first request is served.
+func NewWatcher(path string) *Watcher {
+	w := &Watcher{path: path, interval: reloadInterval}
+	w.reload()
+	return w
+}
+
+// Start polls the file until ctx is cancelled
+func (w *Watcher) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.reload()
+		}
+	}
+}
+
+func (w *Watcher) reload() {
+	info, err := os.Stat(w.path)
+	if err != nil {
+		// A missing or unreadable file keeps the last good list
+		log.Printf("blocklist: cannot read %s: %v", w.path, err)
+		return
+	}
+	if info.ModTime().Equal(w.modTime) && info.Size() == w.size {
+		return
+	}
+
+	contents, err := os.ReadFile(w.path)
+	if err != nil {
+		log.Printf("blocklist: cannot read %s: %v", w.path, err)
+		return
+	}
+
+	list := Parse(string(contents))
+	list.version = time.Now().UnixNano()
+	current.Store(list)
+	w.modTime = info.ModTime()
+	w.size = info.Size()
+
+	log.Printf("blocklist: loaded %d domains from %s", list.Len(), w.path)
+}
+
+// FilterLinks drops links whose URL or final URL is on the instance list,
+// reusing the slice's backing array
+func FilterLinks(links []supabase.Link) []supabase.Link {
+	list := Current()
+	if list.Len() == 0 {
+		return links
+	}
+
+	kept := links[:0]
+	for _, link := range links {
+		if list.Blocked(Host(link.Url)) != "" {
+			continue
+		}
+		if link.FinalUrl.Valid && list.Blocked(Host(link.FinalUrl.String)) != "" {
+			continue
+		}
+		kept = append(kept, link)
+	}
+	return kept
+}
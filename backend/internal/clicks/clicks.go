@@ -0,0 +1,175 @@
+// Package clicks counts link opens through the /r short-link redirect.
+// Clicks are buffered in memory and written in batches so the redirect never
+// waits on the database.
+package clicks
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"log"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	supabase "github.com/egeuysall/cove/internal/supabase/generated"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// maxPending caps the number of distinct buffered rows. Clicks beyond it are
+// dropped rather than letting memory grow if the database is unreachable.
+const maxPending = 50_000
+
+type clickKey struct {
+	linkID  pgtype.UUID
+	userID  pgtype.UUID
+	groupID pgtype.UUID
+	day     time.Time
+}
+
+type clickCount struct {
+	count int32
+	last  time.Time
+}
+
+var (
+	mu      sync.Mutex
+	pending = map[clickKey]*clickCount{}
+)
+
+// Record buffers one click. userID is invalid for anonymous clicks.
+func Record(linkID, userID, groupID pgtype.UUID, at time.Time) {
+	key := clickKey{
+		linkID:  linkID,
+		userID:  userID,
+		groupID: groupID,
+		day:     at.UTC().Truncate(24 * time.Hour),
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	entry, ok := pending[key]
+	if !ok {
+		if len(pending) >= maxPending {
+			return
+		}
+		entry = &clickCount{}
+		pending[key] = entry
+	}
+	entry.count++
+	if at.After(entry.last) {
+		entry.last = at
+	}
+}
+
+// Flush writes every buffered click in one statement. On failure the clicks
+// are put back so the next flush retries them.
+func Flush(ctx context.Context, queries *supabase.Queries) error {
+	mu.Lock()
+	batch := pending
+	pending = map[clickKey]*clickCount{}
+	mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+
+	params := supabase.RecordClicksParams{}
+	for key, entry := range batch {
+		params.LinkIds = append(params.LinkIds, key.linkID)
+		params.UserIds = append(params.UserIds, key.userID)
+		params.GroupIds = append(params.GroupIds, key.groupID)
+		params.Days = append(params.Days, pgtype.Date{Time: key.day, Valid: true})
+		params.Counts = append(params.Counts, entry.count)
+		params.LastClicked = append(params.LastClicked, pgtype.Timestamptz{Time: entry.last, Valid: true})
+	}
+
+	err := queries.RecordClicks(ctx, params)
+	if err != nil {
+		mu.Lock()
+		for key, entry := range batch {
+			if current, ok := pending[key]; ok {
+				current.count += entry.count
+				if entry.last.After(current.last) {
+					current.last = entry.last
+				}
+			} else if len(pending) < maxPending {
+				pending[key] = entry
+			}
+		}
+		mu.Unlock()
+	}
+	return err
+}
+
+// Flusher periodically writes buffered clicks
+type Flusher struct {
+	queries  *supabase.Queries
+	interval time.Duration
+}
+
+func NewFlusher(queries *supabase.Queries) *Flusher {
+	return &Flusher{
+		queries:  queries,
+		interval: 10 * time.Second,
+	}
+}
+
+// Start flushes until ctx is cancelled, then flushes once more
+func (f *Flusher) Start(ctx context.Context) {
+	ticker := time.NewTicker(f.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			f.flush(context.Background())
+			return
+		case <-ticker.C:
+			f.flush(ctx)
+		}
+	}
+}
+
+func (f *Flusher) flush(ctx context.Context) {
+	err := Flush(ctx, f.queries)
+	if err != nil {
+		log.Printf("clicks: failed to flush: %v", err)
+	}
+}
+
+func signature(shortID, userID string) string {
+	secret := strings.TrimSpace(os.Getenv("CLICK_SECRET"))
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("click:" + shortID + ":" + userID))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:16])
+}
+
+// ShortURL builds the redirect address for a link. When CLICK_SECRET is set
+// it carries the viewer's ID and a signature, so opens can be attributed
+// without a login on the redirect itself.
+func ShortURL(publicURL, shortID, userID string) string {
+	shortURL := strings.TrimRight(publicURL, "/") + "/r/" + shortID
+
+	if userID == "" || strings.TrimSpace(os.Getenv("CLICK_SECRET")) == "" {
+		return shortURL
+	}
+
+	query := url.Values{}
+	query.Set("u", userID)
+	query.Set("s", signature(shortID, userID))
+	return shortURL + "?" + query.Encode()
+}
+
+// VerifyUser checks the viewer signature produced by ShortURL
+func VerifyUser(shortID, userID, sig string) bool {
+	if userID == "" || strings.TrimSpace(os.Getenv("CLICK_SECRET")) == "" {
+		return false
+	}
+	return hmac.Equal([]byte(signature(shortID, userID)), []byte(sig))
+}
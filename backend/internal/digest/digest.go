@@ -0,0 +1,183 @@
+package digest
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	supabase "github.com/egeuysall/cove/internal/supabase/generated"
+	"github.com/egeuysall/cove/internal/utils"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	FrequencyDaily  = "daily"
+	FrequencyWeekly = "weekly"
+	FrequencyOff    = "off"
+)
+
+// Config holds everything the digest worker needs to render and deliver mail
+type Config struct {
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	From         string
+	PublicURL    string
+	Interval     time.Duration
+}
+
+// ConfigFromEnv reads the SMTP settings. Pointing SMTP_HOST and SMTP_PORT at
+// a local sink such as Mailpit (localhost:1025) is enough for development.
+// DIGEST_SECRET must be set too: without it every unsubscribe link in a
+// digest would fail verification, so no digests are sent at all.
+func ConfigFromEnv() (Config, bool) {
+	cfg := Config{
+		SMTPHost:     os.Getenv("SMTP_HOST"),
+		SMTPPort:     os.Getenv("SMTP_PORT"),
+		SMTPUsername: os.Getenv("SMTP_USERNAME"),
+		SMTPPassword: os.Getenv("SMTP_PASSWORD"),
+		From:         os.Getenv("DIGEST_FROM"),
+		PublicURL:    os.Getenv("PUBLIC_URL"),
+		Interval:     5 * time.Minute,
+	}
+
+	if cfg.SMTPHost == "" {
+		return cfg, false
+	}
+
+	if strings.TrimSpace(os.Getenv("DIGEST_SECRET")) == "" {
+		log.Printf("digest: SMTP_HOST is set but DIGEST_SECRET is not; not sending digests")
+		return cfg, false
+	}
+
+	if cfg.SMTPPort == "" {
+		cfg.SMTPPort = "25"
+	}
+
+	if cfg.From == "" {
+		cfg.From = "Cove <digest@cove.egeuysal.com>"
+	}
+
+	return cfg, true
+}
+
+// Worker periodically sends digests to every subscriber whose slot has passed
+type Worker struct {
+	cfg     Config
+	queries *supabase.Queries
+	mailer  Mailer
+	now     func() time.Time
+}
+
+func NewWorker(cfg Config, queries *supabase.Queries, mailer Mailer) *Worker {
+	return &Worker{
+		cfg:     cfg,
+		queries: queries,
+		mailer:  mailer,
+		now:     time.Now,
+	}
+}
+
+// Start runs the worker until ctx is cancelled
+func (wk *Worker) Start(ctx context.Context) {
+	ticker := time.NewTicker(wk.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		wk.RunOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce sends every digest that is currently due
+func (wk *Worker) RunOnce(ctx context.Context) {
+	subs, err := wk.queries.ListActiveDigestSubscriptions(ctx)
+	if err != nil {
+		log.Printf("digest: failed to list subscriptions: %v", err)
+		return
+	}
+
+	// Postgres keeps microseconds; sends compare against the stored value
+	now := wk.now().Truncate(time.Microsecond)
+	for _, sub := range subs {
+		if !IsDue(sub, now) {
+			continue
+		}
+
+		err := wk.send(ctx, sub, now)
+		if err != nil {
+			log.Printf("digest: failed to send to %s: %v", utils.UUIDToString(sub.UserID), err)
+		}
+	}
+}
+
+// send delivers one digest. Every instance runs a worker, so the digest is
+// claimed first by moving last_sent_at forward only if no other instance
+// has; if delivery then fails the claim is given back for the next run.
+func (wk *Worker) send(ctx context.Context, sub supabase.DigestSubscription, now time.Time) error {
+	claimed, err := wk.queries.MarkDigestSent(ctx, supabase.MarkDigestSentParams{
+		LastSentAt:     pgtype.Timestamptz{Time: now, Valid: true},
+		UserID:         sub.UserID,
+		PreviousSentAt: sub.LastSentAt,
+	})
+	if err != nil {
+		return fmt.Errorf("claim: %w", err)
+	}
+	if claimed == 0 {
+		return nil
+	}
+
+	err = wk.deliver(ctx, sub, now)
+	if err != nil {
+		_, releaseErr := wk.queries.MarkDigestSent(ctx, supabase.MarkDigestSentParams{
+			LastSentAt:     sub.LastSentAt,
+			UserID:         sub.UserID,
+			PreviousSentAt: pgtype.Timestamptz{Time: now, Valid: true},
+		})
+		if releaseErr != nil {
+			log.Printf("digest: failed to release %s: %v", utils.UUIDToString(sub.UserID), releaseErr)
+		}
+	}
+	return err
+}
+
+func (wk *Worker) deliver(ctx context.Context, sub supabase.DigestSubscription, now time.Time) error {
+	since := sub.LastSentAt.Time
+	if !sub.LastSentAt.Valid {
+		since = now.Add(-period(sub.Frequency))
+	}
+
+	links, err := wk.queries.GetDigestLinks(ctx, supabase.GetDigestLinksParams{
+		UserID: sub.UserID,
+		Since:  pgtype.Timestamptz{Time: since, Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("gather links: %w", err)
+	}
+
+	// Nothing new means no mail, but the window still moves forward
+	if len(links) == 0 {
+		return nil
+	}
+
+	msg, err := Render(sub, links, since, wk.cfg.PublicURL)
+	if err != nil {
+		return fmt.Errorf("render: %w", err)
+	}
+
+	msg.From = wk.cfg.From
+	err = wk.mailer.Send(msg)
+	if err != nil {
+		return fmt.Errorf("deliver: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,245 @@
+package digest
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"testing"
+)
+
+var testMessage = Message{
+	From:           "Cove <digest@cove.test>",
+	To:             "reader@example.com",
+	Subject:        "Your Cove daily digest: 2 new links — café",
+	Text:           "Plain body with a long line that quoted-printable has to wrap somewhere past seventy-six characters",
+	HTML:           "<p>HTML body = café</p>",
+	UnsubscribeURL: "https://cove.test/digest/unsubscribe?user=u1&sig=abc",
+}
+
+// readParts parses a built message and returns its headers and the decoded
+// bodies of its parts keyed by content type
+func readParts(t *testing.T, raw []byte) (mail.Header, map[string]string) {
+	t.Helper()
+
+	parsed, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatalf("message does not parse: %v", err)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("Content-Type = %q, want multipart/alternative", parsed.Header.Get("Content-Type"))
+	}
+
+	bodies := map[string]string{}
+	reader := multipart.NewReader(parsed.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("reading part: %v", err)
+		}
+
+		// The reader undoes quoted-printable itself
+		body, err := io.ReadAll(part)
+		if err != nil {
+			t.Fatalf("reading part: %v", err)
+		}
+		bodies[part.Header.Get("Content-Type")] = string(body)
+	}
+
+	return parsed.Header, bodies
+}
+
+func TestBuildMessage(t *testing.T) {
+	raw, err := BuildMessage(testMessage)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, line := range strings.Split(string(raw), "\r\n") {
+		if len(line) > 998 {
+			t.Fatalf("line of %d bytes exceeds the SMTP limit", len(line))
+		}
+	}
+
+	header, bodies := readParts(t, raw)
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(header.Get("Subject"))
+	if err != nil || subject != testMessage.Subject {
+		t.Errorf("Subject decodes to %q, want %q", subject, testMessage.Subject)
+	}
+	if got := header.Get("List-Unsubscribe"); got != "<"+testMessage.UnsubscribeURL+">" {
+		t.Errorf("List-Unsubscribe = %q", got)
+	}
+	if got := header.Get("List-Unsubscribe-Post"); got != "List-Unsubscribe=One-Click" {
+		t.Errorf("List-Unsubscribe-Post = %q", got)
+	}
+	if _, err := header.Date(); err != nil {
+		t.Errorf("Date header: %v", err)
+	}
+
+	if got := bodies["text/plain; charset=utf-8"]; got != testMessage.Text {
+		t.Errorf("text part = %q, want %q", got, testMessage.Text)
+	}
+	if got := bodies["text/html; charset=utf-8"]; got != testMessage.HTML {
+		t.Errorf("HTML part = %q, want %q", got, testMessage.HTML)
+	}
+}
+
+func TestBuildMessageWithoutUnsubscribe(t *testing.T) {
+	msg := testMessage
+	msg.UnsubscribeURL = ""
+
+	raw, err := BuildMessage(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	header, _ := readParts(t, raw)
+	if got := header.Get("List-Unsubscribe"); got != "" {
+		t.Errorf("List-Unsubscribe = %q, want none", got)
+	}
+	if got := header.Get("List-Unsubscribe-Post"); got != "" {
+		t.Errorf("List-Unsubscribe-Post = %q, want none", got)
+	}
+}
+
+// received is one transaction accepted by smtpSink
+type received struct {
+	auth string
+	from string
+	to   []string
+	data []byte
+}
+
+// smtpSink accepts a single SMTP session on a loopback port, advertising
+// AUTH PLAIN, and reports what it was sent
+func smtpSink(t *testing.T) (host, port string, result <-chan received) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	done := make(chan received, 1)
+	go func() {
+		nc, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		conn := textproto.NewConn(nc)
+		defer conn.Close()
+
+		var got received
+		conn.PrintfLine("220 localhost ESMTP sink")
+		for {
+			line, err := conn.ReadLine()
+			if err != nil {
+				return
+			}
+
+			verb, arg, _ := strings.Cut(line, " ")
+			switch strings.ToUpper(verb) {
+			case "EHLO":
+				conn.PrintfLine("250-localhost")
+				conn.PrintfLine("250 AUTH PLAIN")
+			case "AUTH":
+				got.auth = strings.TrimPrefix(arg, "PLAIN ")
+				conn.PrintfLine("235 2.7.0 Authentication successful")
+			case "MAIL":
+				got.from = arg
+				conn.PrintfLine("250 OK")
+			case "RCPT":
+				got.to = append(got.to, arg)
+				conn.PrintfLine("250 OK")
+			case "DATA":
+				conn.PrintfLine("354 Go ahead")
+				got.data, err = io.ReadAll(conn.DotReader())
+				if err != nil {
+					return
+				}
+				conn.PrintfLine("250 OK")
+			case "QUIT":
+				conn.PrintfLine("221 Bye")
+				done <- got
+				return
+			default:
+				conn.PrintfLine("250 OK")
+			}
+		}
+	}()
+
+	host, port, err = net.SplitHostPort(listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return host, port, done
+}
+
+func TestSMTPMailerSend(t *testing.T) {
+	host, port, result := smtpSink(t)
+
+	mailer := NewSMTPMailer(Config{SMTPHost: host, SMTPPort: port})
+	err := mailer.Send(testMessage)
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	got := <-result
+	if got.auth != "" {
+		t.Errorf("authenticated without a username")
+	}
+	if got.from != "FROM:<digest@cove.test>" {
+		t.Errorf("MAIL %s", got.from)
+	}
+	if len(got.to) != 1 || got.to[0] != "TO:<reader@example.com>" {
+		t.Errorf("RCPT %v", got.to)
+	}
+
+	_, bodies := readParts(t, got.data)
+	if bodies["text/plain; charset=utf-8"] != testMessage.Text {
+		t.Errorf("delivered text part = %q", bodies["text/plain; charset=utf-8"])
+	}
+}
+
+func TestSMTPMailerAuthenticates(t *testing.T) {
+	host, port, result := smtpSink(t)
+
+	mailer := NewSMTPMailer(Config{
+		SMTPHost:     host,
+		SMTPPort:     port,
+		SMTPUsername: "cove",
+		SMTPPassword: "secret",
+	})
+	err := mailer.Send(testMessage)
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	got := <-result
+	credentials, err := base64.StdEncoding.DecodeString(got.auth)
+	if err != nil || string(credentials) != "\x00cove\x00secret" {
+		t.Errorf("AUTH PLAIN sent %q", credentials)
+	}
+}
+
+func TestSMTPMailerRejectsBadAddresses(t *testing.T) {
+	mailer := NewSMTPMailer(Config{SMTPHost: "127.0.0.1", SMTPPort: "1"})
+
+	msg := testMessage
+	msg.To = "not an address"
+	if err := mailer.Send(msg); err == nil || !strings.Contains(err.Error(), "invalid to address") {
+		t.Errorf("Send to a bad address = %v", err)
+	}
+}
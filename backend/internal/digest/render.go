@@ -0,0 +1,99 @@
+package digest
+
+import (
+	"bytes"
+	"embed"
+	htmltemplate "html/template"
+	"strconv"
+	texttemplate "text/template"
+	"time"
+
+	supabase "github.com/egeuysall/cove/internal/supabase/generated"
+	"github.com/egeuysall/cove/internal/utils"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+var (
+	htmlTemplate = htmltemplate.Must(htmltemplate.ParseFS(templateFS, "templates/digest.html.tmpl"))
+	textTemplate = texttemplate.Must(texttemplate.ParseFS(templateFS, "templates/digest.txt.tmpl"))
+)
+
+type digestLink struct {
+	URL     string
+	Title   string
+	Comment string
+}
+
+type digestGroup struct {
+	ID    pgtype.UUID
+	Name  string
+	Links []digestLink
+}
+
+type digestData struct {
+	Period         string
+	Since          time.Time
+	LinkCount      int
+	Groups         []digestGroup
+	UnsubscribeURL string
+}
+
+// Render builds the HTML and plain-text bodies for one subscriber. Links must
+// be ordered by group, which GetDigestLinks guarantees.
+func Render(sub supabase.DigestSubscription, links []supabase.GetDigestLinksRow, since time.Time, publicURL string) (Message, error) {
+	loc, err := time.LoadLocation(sub.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	data := digestData{
+		Period:         sub.Frequency,
+		Since:          since.In(loc),
+		LinkCount:      len(links),
+		UnsubscribeURL: UnsubscribeURL(publicURL, utils.UUIDToString(sub.UserID)),
+	}
+
+	for _, link := range links {
+		// Groups may share a name, so a new group starts when the ID changes
+		if len(data.Groups) == 0 || data.Groups[len(data.Groups)-1].ID != link.GroupID {
+			data.Groups = append(data.Groups, digestGroup{ID: link.GroupID, Name: link.GroupName})
+		}
+
+		group := &data.Groups[len(data.Groups)-1]
+		group.Links = append(group.Links, digestLink{
+			URL:     link.Url,
+			Title:   link.Title.String,
+			Comment: link.Comment.String,
+		})
+	}
+
+	var htmlBody, textBody bytes.Buffer
+
+	err = htmlTemplate.Execute(&htmlBody, data)
+	if err != nil {
+		return Message{}, err
+	}
+
+	err = textTemplate.Execute(&textBody, data)
+	if err != nil {
+		return Message{}, err
+	}
+
+	return Message{
+		To:             sub.Email,
+		Subject:        "Your Cove " + data.Period + " digest: " + pluralLinks(len(links)),
+		Text:           textBody.String(),
+		HTML:           htmlBody.String(),
+		UnsubscribeURL: data.UnsubscribeURL,
+	}, nil
+}
+
+func pluralLinks(n int) string {
+	if n == 1 {
+		return "1 new link"
+	}
+	return strconv.Itoa(n) + " new links"
+}
@@ -0,0 +1,51 @@
+package digest
+
+import (
+	"time"
+
+	supabase "github.com/egeuysall/cove/internal/supabase/generated"
+)
+
+func period(frequency string) time.Duration {
+	if frequency == FrequencyWeekly {
+		return 7 * 24 * time.Hour
+	}
+	return 24 * time.Hour
+}
+
+// LatestSlot returns the most recent scheduled send time at or before now in
+// the subscriber's timezone. Weekly digests go out on Mondays.
+func LatestSlot(sub supabase.DigestSubscription, now time.Time) time.Time {
+	loc, err := time.LoadLocation(sub.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	local := now.In(loc)
+	slot := time.Date(local.Year(), local.Month(), local.Day(), int(sub.SendHour), 0, 0, 0, loc)
+
+	if local.Before(slot) {
+		slot = slot.AddDate(0, 0, -1)
+	}
+
+	if sub.Frequency == FrequencyWeekly {
+		for slot.Weekday() != time.Monday {
+			slot = slot.AddDate(0, 0, -1)
+		}
+	}
+
+	return slot
+}
+
+// IsDue reports whether sub has not yet received the digest for its latest slot
+func IsDue(sub supabase.DigestSubscription, now time.Time) bool {
+	if sub.Frequency == FrequencyOff {
+		return false
+	}
+
+	if !sub.LastSentAt.Valid {
+		return true
+	}
+
+	return sub.LastSentAt.Time.Before(LatestSlot(sub, now))
+}
@@ -0,0 +1,98 @@
+package digest
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	supabase "github.com/egeuysall/cove/internal/supabase/generated"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func utc(value string) time.Time {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestLatestSlot(t *testing.T) {
+	tests := []struct {
+		name      string
+		frequency string
+		timezone  string
+		sendHour  int32
+		now       string
+		want      string
+	}{
+		{"daily after the hour", FrequencyDaily, "UTC", 8, "2025-03-10T09:00:00Z", "2025-03-10T08:00:00Z"},
+		{"daily on the hour", FrequencyDaily, "UTC", 8, "2025-03-10T08:00:00Z", "2025-03-10T08:00:00Z"},
+		{"daily before the hour", FrequencyDaily, "UTC", 8, "2025-03-10T07:59:59Z", "2025-03-09T08:00:00Z"},
+		{"unknown timezone is UTC", FrequencyDaily, "Mars/Olympus", 8, "2025-03-10T09:00:00Z", "2025-03-10T08:00:00Z"},
+		{"local date ahead of UTC", FrequencyDaily, "Asia/Tokyo", 7, "2025-03-09T23:00:00Z", "2025-03-09T22:00:00Z"},
+		{"local date behind UTC", FrequencyDaily, "America/Los_Angeles", 20, "2025-01-16T02:00:00Z", "2025-01-15T04:00:00Z"},
+
+		// New York springs forward on 2025-03-09 and falls back on 2025-11-02
+		{"after spring forward", FrequencyDaily, "America/New_York", 8, "2025-03-09T12:30:00Z", "2025-03-09T12:00:00Z"},
+		{"slot before spring forward", FrequencyDaily, "America/New_York", 8, "2025-03-09T11:30:00Z", "2025-03-08T13:00:00Z"},
+		{"after fall back", FrequencyDaily, "America/New_York", 8, "2025-11-02T14:00:00Z", "2025-11-02T13:00:00Z"},
+		{"slot before fall back", FrequencyDaily, "America/New_York", 8, "2025-11-02T12:30:00Z", "2025-11-01T12:00:00Z"},
+
+		// Berlin springs forward on 2025-03-30
+		{"weekly midweek", FrequencyWeekly, "Europe/Berlin", 9, "2025-03-26T10:00:00Z", "2025-03-24T08:00:00Z"},
+		{"weekly across spring forward", FrequencyWeekly, "Europe/Berlin", 9, "2025-04-02T10:00:00Z", "2025-03-31T07:00:00Z"},
+		{"weekly on Monday before the hour", FrequencyWeekly, "Europe/Berlin", 9, "2025-03-31T06:30:00Z", "2025-03-24T08:00:00Z"},
+		{"weekly on Monday after the hour", FrequencyWeekly, "Europe/Berlin", 9, "2025-03-31T07:30:00Z", "2025-03-31T07:00:00Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub := supabase.DigestSubscription{
+				Frequency: tt.frequency,
+				Timezone:  tt.timezone,
+				SendHour:  tt.sendHour,
+			}
+
+			got := LatestSlot(sub, utc(tt.now))
+			if want := utc(tt.want); !got.Equal(want) {
+				t.Errorf("LatestSlot at %s = %s, want %s", tt.now, got.UTC().Format(time.RFC3339), tt.want)
+			}
+		})
+	}
+}
+
+func TestIsDue(t *testing.T) {
+	now := utc("2025-11-02T14:00:00Z")
+	slot := utc("2025-11-02T13:00:00Z")
+
+	tests := []struct {
+		name       string
+		frequency  string
+		lastSentAt pgtype.Timestamptz
+		want       bool
+	}{
+		{"never sent", FrequencyDaily, pgtype.Timestamptz{}, true},
+		{"sent before the slot", FrequencyDaily, pgtype.Timestamptz{Time: slot.Add(-time.Minute), Valid: true}, true},
+		{"sent at the slot", FrequencyDaily, pgtype.Timestamptz{Time: slot, Valid: true}, false},
+		{"sent after the slot", FrequencyDaily, pgtype.Timestamptz{Time: slot.Add(time.Minute), Valid: true}, false},
+		{"weekly sent this week", FrequencyWeekly, pgtype.Timestamptz{Time: utc("2025-10-27T13:00:00Z"), Valid: true}, false},
+		{"weekly sent last week", FrequencyWeekly, pgtype.Timestamptz{Time: utc("2025-10-20T12:00:00Z"), Valid: true}, true},
+		{"off", FrequencyOff, pgtype.Timestamptz{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub := supabase.DigestSubscription{
+				Frequency:  tt.frequency,
+				Timezone:   "America/New_York",
+				SendHour:   8,
+				LastSentAt: tt.lastSentAt,
+			}
+
+			if got := IsDue(sub, now); got != tt.want {
+				t.Errorf("IsDue = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package digest
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	htmltemplate "html/template"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+)
+
+func unsubscribeSignature(userID string) string {
+	secret := strings.TrimSpace(os.Getenv("DIGEST_SECRET"))
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("digest-unsubscribe:" + userID))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// UnsubscribeURL builds a signed one-click link that needs no login
+func UnsubscribeURL(publicURL, userID string) string {
+	query := url.Values{}
+	query.Set("user", userID)
+	query.Set("sig", unsubscribeSignature(userID))
+
+	return strings.TrimRight(publicURL, "/") + "/digest/unsubscribe?" + query.Encode()
+}
+
+// VerifyUnsubscribe checks a signature produced by UnsubscribeURL. It always
+// fails when DIGEST_SECRET is unset so links cannot be forged.
+func VerifyUnsubscribe(userID, sig string) bool {
+	if strings.TrimSpace(os.Getenv("DIGEST_SECRET")) == "" {
+		return false
+	}
+
+	expected := unsubscribeSignature(userID)
+	return hmac.Equal([]byte(expected), []byte(sig))
+}
+
+var unsubscribeTemplate = htmltemplate.Must(htmltemplate.ParseFS(templateFS, "templates/unsubscribe.html.tmpl"))
+
+// RenderUnsubscribePage writes the page an unsubscribe link opens. Until done
+// it only asks for confirmation, with a form that posts back to the same
+// link, so mail scanners that follow links do not unsubscribe anyone.
+func RenderUnsubscribePage(w io.Writer, done bool) error {
+	return unsubscribeTemplate.Execute(w, struct{ Done bool }{done})
+}
@@ -0,0 +1,81 @@
+package digest
+
+import (
+	"net/url"
+	"testing"
+)
+
+const testUserID = "0b6b3c1e-5d8a-4f53-9a51-7f0c2e8d4a10"
+
+// signedLink parses a fresh unsubscribe URL and returns it with its user and
+// sig query values
+func signedLink(t *testing.T, publicURL string) (*url.URL, string, string) {
+	t.Helper()
+
+	link, err := url.Parse(UnsubscribeURL(publicURL, testUserID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return link, link.Query().Get("user"), link.Query().Get("sig")
+}
+
+func TestUnsubscribeURL(t *testing.T) {
+	t.Setenv("DIGEST_SECRET", "first secret")
+
+	link, user, sig := signedLink(t, "https://cove.test/")
+	if link.Scheme != "https" || link.Host != "cove.test" || link.Path != "/digest/unsubscribe" {
+		t.Errorf("UnsubscribeURL = %s", link)
+	}
+	if user != testUserID {
+		t.Errorf("user = %q, want %q", user, testUserID)
+	}
+	if !VerifyUnsubscribe(user, sig) {
+		t.Errorf("a freshly signed link does not verify")
+	}
+}
+
+func TestVerifyUnsubscribe(t *testing.T) {
+	t.Setenv("DIGEST_SECRET", "first secret")
+	_, user, sig := signedLink(t, "https://cove.test")
+
+	tampered := "A" + sig[1:]
+	if tampered == sig {
+		tampered = "B" + sig[1:]
+	}
+
+	tests := []struct {
+		name   string
+		secret string
+		user   string
+		sig    string
+		want   bool
+	}{
+		{"valid", "first secret", user, sig, true},
+		{"secret padded with whitespace", "  first secret\n", user, sig, true},
+		{"other user", "first secret", "5c1f9a2e-0d4b-4c7e-8f63-2a9b1d0e7c54", sig, false},
+		{"tampered signature", "first secret", user, tampered, false},
+		{"empty signature", "first secret", user, "", false},
+		{"rotated secret", "second secret", user, sig, false},
+		{"no secret", "", user, sig, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("DIGEST_SECRET", tt.secret)
+
+			if got := VerifyUnsubscribe(tt.user, tt.sig); got != tt.want {
+				t.Errorf("VerifyUnsubscribe = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnsubscribeSignatureWithoutSecretIsRejected(t *testing.T) {
+	// A link signed while the secret was unset must not verify later either
+	t.Setenv("DIGEST_SECRET", "")
+	_, user, sig := signedLink(t, "https://cove.test")
+
+	if VerifyUnsubscribe(user, sig) {
+		t.Errorf("a link signed without a secret verifies")
+	}
+}
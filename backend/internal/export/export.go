@@ -0,0 +1,266 @@
+// Package export streams a group's links, replies, members and invites out
+// of the database in several archive formats.
+package export
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/egeuysall/cove/internal/utils"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	FormatJSON   = "json"
+	FormatNDJSON = "ndjson"
+	FormatCSV    = "csv"
+	FormatHTML   = "html"
+	FormatZIP    = "zip"
+
+	// fetchSize is how many rows are pulled from a cursor at a time, which
+	// bounds memory use regardless of group size
+	fetchSize = 500
+)
+
+func IsValidFormat(format string) bool {
+	switch format {
+	case FormatJSON, FormatNDJSON, FormatCSV, FormatHTML, FormatZIP:
+		return true
+	}
+	return false
+}
+
+type Group struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Link struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	Title     string    `json:"title,omitempty"`
+	Comment   string    `json:"comment,omitempty"`
+	Tags      []string  `json:"tags"`
+	PostedBy  string    `json:"posted_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Reply struct {
+	ID        string    `json:"id"`
+	LinkID    string    `json:"link_id"`
+	Body      string    `json:"body"`
+	PostedBy  string    `json:"posted_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Member struct {
+	UserID   string    `json:"user_id"`
+	Role     string    `json:"role"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+type Invite struct {
+	Code      string    `json:"code"`
+	UsedBy    string    `json:"used_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Source reads one group inside a read-only repeatable read transaction, so
+// every section of an export, and every file in a ZIP, sees the same snapshot.
+type Source struct {
+	tx         pgx.Tx
+	groupID    pgtype.UUID
+	viewerID   pgtype.UUID
+	showHidden bool
+	Group      Group
+	ExportedAt time.Time
+}
+
+// Open starts the export transaction and loads the group. Hidden links are
+// only exported if viewerID posted them or showHidden is set. It returns
+// pgx.ErrNoRows when the group does not exist.
+func Open(ctx context.Context, db *pgxpool.Pool, groupID, viewerID pgtype.UUID, showHidden bool) (*Source, error) {
+	tx, err := db.BeginTx(ctx, pgx.TxOptions{
+		IsoLevel:   pgx.RepeatableRead,
+		AccessMode: pgx.ReadOnly,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	source := &Source{
+		tx:         tx,
+		groupID:    groupID,
+		viewerID:   viewerID,
+		showHidden: showHidden,
+		ExportedAt: time.Now().UTC(),
+	}
+
+	var name string
+	var createdAt pgtype.Timestamptz
+	err = tx.QueryRow(ctx, "SELECT name, created_at FROM groups WHERE id = $1", groupID).Scan(&name, &createdAt)
+	if err != nil {
+		_ = tx.Rollback(ctx)
+		return nil, err
+	}
+
+	source.Group = Group{
+		ID:        utils.UUIDToString(groupID),
+		Name:      name,
+		CreatedAt: createdAt.Time,
+	}
+
+	return source, nil
+}
+
+// Close ends the transaction. Nothing is written, so it always rolls back.
+func (s *Source) Close(ctx context.Context) error {
+	return s.tx.Rollback(ctx)
+}
+
+// Links calls fn for every link in the group the viewer can see, oldest first
+func (s *Source) Links(ctx context.Context, fn func(Link) error) error {
+	query := `SELECT id, url, title, comment, tags, user_id, created_at FROM links
+WHERE group_id = $1
+  AND (hidden_at IS NULL OR user_id = $2 OR $3::bool)
+ORDER BY created_at, id`
+
+	return s.each(ctx, "export_links", query, []interface{}{s.groupID, s.viewerID, s.showHidden}, func(rows pgx.Rows) error {
+		var id, userID pgtype.UUID
+		var title, comment pgtype.Text
+		var createdAt pgtype.Timestamptz
+		var link Link
+
+		err := rows.Scan(&id, &link.URL, &title, &comment, &link.Tags, &userID, &createdAt)
+		if err != nil {
+			return err
+		}
+
+		link.ID = utils.UUIDToString(id)
+		link.Title = title.String
+		link.Comment = comment.String
+		link.PostedBy = utils.UUIDToString(userID)
+		link.CreatedAt = createdAt.Time
+		if link.Tags == nil {
+			link.Tags = []string{}
+		}
+
+		return fn(link)
+	})
+}
+
+// Replies calls fn for every reply under a link the viewer can see, oldest
+// first
+func (s *Source) Replies(ctx context.Context, fn func(Reply) error) error {
+	query := `SELECT r.id, r.link_id, r.body, r.user_id, r.created_at FROM link_replies r
+JOIN links l ON l.id = r.link_id
+WHERE l.group_id = $1
+  AND (l.hidden_at IS NULL OR l.user_id = $2 OR $3::bool)
+ORDER BY r.created_at, r.id`
+
+	return s.each(ctx, "export_replies", query, []interface{}{s.groupID, s.viewerID, s.showHidden}, func(rows pgx.Rows) error {
+		var id, linkID, userID pgtype.UUID
+		var createdAt pgtype.Timestamptz
+		var reply Reply
+
+		err := rows.Scan(&id, &linkID, &reply.Body, &userID, &createdAt)
+		if err != nil {
+			return err
+		}
+
+		reply.ID = utils.UUIDToString(id)
+		reply.LinkID = utils.UUIDToString(linkID)
+		reply.PostedBy = utils.UUIDToString(userID)
+		reply.CreatedAt = createdAt.Time
+
+		return fn(reply)
+	})
+}
+
+// Members calls fn for every member of the group in join order
+func (s *Source) Members(ctx context.Context, fn func(Member) error) error {
+	query := `SELECT user_id, role, joined_at FROM group_members
+WHERE group_id = $1
+ORDER BY joined_at, user_id`
+
+	return s.each(ctx, "export_members", query, []interface{}{s.groupID}, func(rows pgx.Rows) error {
+		var userID pgtype.UUID
+		var joinedAt pgtype.Timestamptz
+		var member Member
+
+		err := rows.Scan(&userID, &member.Role, &joinedAt)
+		if err != nil {
+			return err
+		}
+
+		member.UserID = utils.UUIDToString(userID)
+		member.JoinedAt = joinedAt.Time
+
+		return fn(member)
+	})
+}
+
+// Invites calls fn for every invite created for the group, oldest first
+func (s *Source) Invites(ctx context.Context, fn func(Invite) error) error {
+	query := `SELECT code, used_by, created_at FROM invites
+WHERE group_id = $1
+ORDER BY created_at, code`
+
+	return s.each(ctx, "export_invites", query, []interface{}{s.groupID}, func(rows pgx.Rows) error {
+		var usedBy pgtype.UUID
+		var createdAt pgtype.Timestamptz
+		var invite Invite
+
+		err := rows.Scan(&invite.Code, &usedBy, &createdAt)
+		if err != nil {
+			return err
+		}
+
+		invite.UsedBy = utils.UUIDToString(usedBy)
+		invite.CreatedAt = createdAt.Time
+
+		return fn(invite)
+	})
+}
+
+// each declares a server-side cursor for query with args and fetches it in
+// batches of fetchSize, calling scan once per row
+func (s *Source) each(ctx context.Context, cursor, query string, args []interface{}, scan func(pgx.Rows) error) error {
+	_, err := s.tx.Exec(ctx, "DECLARE "+cursor+" NO SCROLL CURSOR FOR "+query, args...)
+	if err != nil {
+		return err
+	}
+	defer s.tx.Exec(ctx, "CLOSE "+cursor)
+
+	fetch := fmt.Sprintf("FETCH %d FROM %s", fetchSize, cursor)
+
+	for {
+		rows, err := s.tx.Query(ctx, fetch)
+		if err != nil {
+			return err
+		}
+
+		count := 0
+		for rows.Next() {
+			count++
+			err = scan(rows)
+			if err != nil {
+				rows.Close()
+				return err
+			}
+		}
+		rows.Close()
+
+		if rows.Err() != nil {
+			return rows.Err()
+		}
+
+		if count < fetchSize {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package export
+
+import (
+	"context"
+	"embed"
+	"html/template"
+	"io"
+)
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+// The archive template defines one block per row type so rows can be
+// rendered as they are read instead of collected first
+var archiveTemplate = template.Must(template.ParseFS(templateFS, "templates/archive.html.tmpl"))
+
+// WriteHTML writes a self-contained static page with inline styles and no
+// external assets, suitable for opening straight from disk
+func (s *Source) WriteHTML(ctx context.Context, w io.Writer) error {
+	err := archiveTemplate.ExecuteTemplate(w, "header", s)
+	if err != nil {
+		return err
+	}
+
+	err = writeHTMLSection(w, "Links", func() error {
+		return s.Links(ctx, func(link Link) error {
+			return archiveTemplate.ExecuteTemplate(w, "link", link)
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	err = writeHTMLSection(w, "Replies", func() error {
+		return s.Replies(ctx, func(reply Reply) error {
+			return archiveTemplate.ExecuteTemplate(w, "reply", reply)
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	err = writeHTMLSection(w, "Members", func() error {
+		return s.Members(ctx, func(member Member) error {
+			return archiveTemplate.ExecuteTemplate(w, "member", member)
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	err = writeHTMLSection(w, "Invites", func() error {
+		return s.Invites(ctx, func(invite Invite) error {
+			return archiveTemplate.ExecuteTemplate(w, "invite", invite)
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	return archiveTemplate.ExecuteTemplate(w, "footer", s)
+}
+
+func writeHTMLSection(w io.Writer, title string, rows func() error) error {
+	err := archiveTemplate.ExecuteTemplate(w, "section", title)
+	if err != nil {
+		return err
+	}
+
+	err = rows()
+	if err != nil {
+		return err
+	}
+
+	return archiveTemplate.ExecuteTemplate(w, "end-section", nil)
+}
@@ -0,0 +1,320 @@
+package export
+
+import (
+	"archive/zip"
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+)
+
+// ContentType returns the Content-Type header for a format
+func ContentType(format string) string {
+	switch format {
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatHTML:
+		return "text/html; charset=utf-8"
+	case FormatZIP:
+		return "application/zip"
+	}
+	return "application/json"
+}
+
+// Filename suggests a download name such as cove-reading-club-20250730.zip
+func (s *Source) Filename(format string) string {
+	slug := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + ('a' - 'A')
+		}
+		return '-'
+	}, s.Group.Name)
+	slug = strings.Trim(slug, "-")
+	if slug == "" {
+		slug = "group"
+	}
+
+	return "cove-" + slug + "-" + s.ExportedAt.Format("20060102") + "." + format
+}
+
+// Write streams the export in format to w
+func (s *Source) Write(ctx context.Context, w io.Writer, format string) error {
+	buffered := bufio.NewWriter(w)
+
+	var err error
+	switch format {
+	case FormatNDJSON:
+		err = s.WriteNDJSON(ctx, buffered)
+	case FormatCSV:
+		err = s.WriteLinksCSV(ctx, buffered)
+	case FormatHTML:
+		err = s.WriteHTML(ctx, buffered)
+	case FormatZIP:
+		err = s.WriteZIP(ctx, buffered)
+	default:
+		err = s.WriteJSON(ctx, buffered)
+	}
+	if err != nil {
+		return err
+	}
+
+	return buffered.Flush()
+}
+
+// WriteJSON writes a single JSON document with group, links, replies,
+// members and invites keys. Arrays are written element by element as rows arrive.
+func (s *Source) WriteJSON(ctx context.Context, w io.Writer) error {
+	group, err := json.Marshal(s.Group)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, `{"exported_at":"`+s.ExportedAt.Format(time.RFC3339)+`","group":`+string(group))
+	if err != nil {
+		return err
+	}
+
+	err = writeJSONArray(w, "links", func(item func(interface{}) error) error {
+		return s.Links(ctx, func(link Link) error { return item(link) })
+	})
+	if err != nil {
+		return err
+	}
+
+	err = writeJSONArray(w, "replies", func(item func(interface{}) error) error {
+		return s.Replies(ctx, func(reply Reply) error { return item(reply) })
+	})
+	if err != nil {
+		return err
+	}
+
+	err = writeJSONArray(w, "members", func(item func(interface{}) error) error {
+		return s.Members(ctx, func(member Member) error { return item(member) })
+	})
+	if err != nil {
+		return err
+	}
+
+	err = writeJSONArray(w, "invites", func(item func(interface{}) error) error {
+		return s.Invites(ctx, func(invite Invite) error { return item(invite) })
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, "}\n")
+	return err
+}
+
+func writeJSONArray(w io.Writer, key string, produce func(item func(interface{}) error) error) error {
+	_, err := io.WriteString(w, `,"`+key+`":[`)
+	if err != nil {
+		return err
+	}
+
+	first := true
+	err = produce(func(v interface{}) error {
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		if !first {
+			encoded = append([]byte{','}, encoded...)
+		}
+		first = false
+		_, err = w.Write(encoded)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, "]")
+	return err
+}
+
+type ndjsonRecord struct {
+	Type string      `json:"type"`
+	Data interface{} `json:"data"`
+}
+
+// WriteNDJSON writes one {"type":...,"data":...} object per line: the group
+// first, then each link, reply, member and invite
+func (s *Source) WriteNDJSON(ctx context.Context, w io.Writer) error {
+	encoder := json.NewEncoder(w)
+
+	err := encoder.Encode(ndjsonRecord{Type: "group", Data: s.Group})
+	if err != nil {
+		return err
+	}
+
+	err = s.Links(ctx, func(link Link) error {
+		return encoder.Encode(ndjsonRecord{Type: "link", Data: link})
+	})
+	if err != nil {
+		return err
+	}
+
+	err = s.Replies(ctx, func(reply Reply) error {
+		return encoder.Encode(ndjsonRecord{Type: "reply", Data: reply})
+	})
+	if err != nil {
+		return err
+	}
+
+	err = s.Members(ctx, func(member Member) error {
+		return encoder.Encode(ndjsonRecord{Type: "member", Data: member})
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.Invites(ctx, func(invite Invite) error {
+		return encoder.Encode(ndjsonRecord{Type: "invite", Data: invite})
+	})
+}
+
+// WriteLinksCSV writes the group's links, including comments and tags. Tags
+// are joined with "|" as in Pocket exports.
+func (s *Source) WriteLinksCSV(ctx context.Context, w io.Writer) error {
+	writer := csv.NewWriter(w)
+
+	err := writer.Write([]string{"id", "url", "title", "comment", "tags", "posted_by", "created_at"})
+	if err != nil {
+		return err
+	}
+
+	err = s.Links(ctx, func(link Link) error {
+		return writer.Write([]string{
+			link.ID,
+			link.URL,
+			link.Title,
+			link.Comment,
+			strings.Join(link.Tags, "|"),
+			link.PostedBy,
+			link.CreatedAt.UTC().Format(time.RFC3339),
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func (s *Source) WriteRepliesCSV(ctx context.Context, w io.Writer) error {
+	writer := csv.NewWriter(w)
+
+	err := writer.Write([]string{"id", "link_id", "body", "posted_by", "created_at"})
+	if err != nil {
+		return err
+	}
+
+	err = s.Replies(ctx, func(reply Reply) error {
+		return writer.Write([]string{
+			reply.ID,
+			reply.LinkID,
+			reply.Body,
+			reply.PostedBy,
+			reply.CreatedAt.UTC().Format(time.RFC3339),
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func (s *Source) WriteMembersCSV(ctx context.Context, w io.Writer) error {
+	writer := csv.NewWriter(w)
+
+	err := writer.Write([]string{"user_id", "role", "joined_at"})
+	if err != nil {
+		return err
+	}
+
+	err = s.Members(ctx, func(member Member) error {
+		return writer.Write([]string{
+			member.UserID,
+			member.Role,
+			member.JoinedAt.UTC().Format(time.RFC3339),
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func (s *Source) WriteInvitesCSV(ctx context.Context, w io.Writer) error {
+	writer := csv.NewWriter(w)
+
+	err := writer.Write([]string{"code", "used_by", "created_at"})
+	if err != nil {
+		return err
+	}
+
+	err = s.Invites(ctx, func(invite Invite) error {
+		return writer.Write([]string{
+			invite.Code,
+			invite.UsedBy,
+			invite.CreatedAt.UTC().Format(time.RFC3339),
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteZIP bundles the JSON document, one CSV per section and the HTML
+// archive. Entries are compressed as they are written, so nothing is held
+// in memory.
+func (s *Source) WriteZIP(ctx context.Context, w io.Writer) error {
+	archive := zip.NewWriter(w)
+
+	entries := []struct {
+		name  string
+		write func(context.Context, io.Writer) error
+	}{
+		{"archive.json", s.WriteJSON},
+		{"links.csv", s.WriteLinksCSV},
+		{"replies.csv", s.WriteRepliesCSV},
+		{"members.csv", s.WriteMembersCSV},
+		{"invites.csv", s.WriteInvitesCSV},
+		{"index.html", s.WriteHTML},
+	}
+
+	for _, entry := range entries {
+		file, err := archive.CreateHeader(&zip.FileHeader{
+			Name:     entry.name,
+			Method:   zip.Deflate,
+			Modified: s.ExportedAt,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = entry.write(ctx, file)
+		if err != nil {
+			return err
+		}
+	}
+
+	return archive.Close()
+}
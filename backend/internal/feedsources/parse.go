@@ -0,0 +1,187 @@
+package feedsources
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Entry is one item from an external RSS or Atom feed
+type Entry struct {
+	GUID      string
+	URL       string
+	Title     string
+	Published time.Time
+}
+
+// Parsed is a fetched feed reduced to what the poller needs
+type Parsed struct {
+	Title   string
+	Entries []Entry
+}
+
+var ErrUnknownFormat = errors.New("document is not an RSS or Atom feed")
+
+type rssDoc struct {
+	Channel struct {
+		Title string    `xml:"title"`
+		Items []rssItem `xml:"item"`
+	} `xml:"channel"`
+	// RSS 1.0 (RDF) puts items next to the channel rather than inside it
+	Items []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title   string `xml:"title"`
+	Link    string `xml:"link"`
+	GUID    string `xml:"guid"`
+	PubDate string `xml:"pubDate"`
+	Date    string `xml:"http://purl.org/dc/elements/1.1/ date"`
+}
+
+type atomDoc struct {
+	Title   string      `xml:"title"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+}
+
+type atomEntry struct {
+	ID        string     `xml:"id"`
+	Title     string     `xml:"title"`
+	Links     []atomLink `xml:"link"`
+	Published string     `xml:"published"`
+	Updated   string     `xml:"updated"`
+}
+
+// Parse decodes RSS 2.0, RSS 1.0 or Atom, detected from the root element
+func Parse(body []byte) (Parsed, error) {
+	root, err := rootElement(body)
+	if err != nil {
+		return Parsed{}, err
+	}
+
+	switch root {
+	case "rss", "RDF":
+		return parseRSS(body)
+	case "feed":
+		return parseAtom(body)
+	}
+	return Parsed{}, ErrUnknownFormat
+}
+
+func rootElement(body []byte) (string, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	decoder.Strict = false
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return "", ErrUnknownFormat
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name.Local, nil
+		}
+	}
+}
+
+func newDecoder(body []byte) *xml.Decoder {
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	decoder.Strict = false
+	decoder.Entity = xml.HTMLEntity
+	return decoder
+}
+
+func parseRSS(body []byte) (Parsed, error) {
+	var doc rssDoc
+	err := newDecoder(body).Decode(&doc)
+	if err != nil {
+		return Parsed{}, err
+	}
+
+	parsed := Parsed{Title: strings.TrimSpace(doc.Channel.Title)}
+
+	items := append(doc.Channel.Items, doc.Items...)
+	for _, item := range items {
+		entry := Entry{
+			GUID:      strings.TrimSpace(item.GUID),
+			URL:       strings.TrimSpace(item.Link),
+			Title:     strings.TrimSpace(item.Title),
+			Published: parseTime(item.PubDate, item.Date),
+		}
+		if entry.GUID == "" {
+			entry.GUID = entry.URL
+		}
+		if entry.URL == "" || entry.GUID == "" {
+			continue
+		}
+		parsed.Entries = append(parsed.Entries, entry)
+	}
+
+	return parsed, nil
+}
+
+func parseAtom(body []byte) (Parsed, error) {
+	var doc atomDoc
+	err := newDecoder(body).Decode(&doc)
+	if err != nil {
+		return Parsed{}, err
+	}
+
+	parsed := Parsed{Title: strings.TrimSpace(doc.Title)}
+
+	for _, item := range doc.Entries {
+		entry := Entry{
+			GUID:      strings.TrimSpace(item.ID),
+			Title:     strings.TrimSpace(item.Title),
+			Published: parseTime(item.Published, item.Updated),
+		}
+
+		for _, link := range item.Links {
+			if link.Rel == "" || link.Rel == "alternate" {
+				entry.URL = strings.TrimSpace(link.Href)
+				break
+			}
+		}
+
+		if entry.GUID == "" {
+			entry.GUID = entry.URL
+		}
+		if entry.URL == "" || entry.GUID == "" {
+			continue
+		}
+		parsed.Entries = append(parsed.Entries, entry)
+	}
+
+	return parsed, nil
+}
+
+var timeLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC3339,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2006-01-02T15:04:05Z07:00",
+}
+
+func parseTime(values ...string) time.Time {
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		for _, layout := range timeLayouts {
+			t, err := time.Parse(layout, value)
+			if err == nil {
+				return t
+			}
+		}
+	}
+	return time.Time{}
+}
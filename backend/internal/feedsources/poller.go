@@ -0,0 +1,252 @@
+package feedsources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/egeuysall/cove/internal/links"
+	"github.com/egeuysall/cove/internal/netguard"
+	supabase "github.com/egeuysall/cove/internal/supabase/generated"
+	"github.com/egeuysall/cove/internal/utils"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	// initialBackfill is how many existing entries are posted the first time a
+	// source is fetched; the rest are only marked as seen
+	initialBackfill = 3
+	// maxPostsPerPoll keeps a burst of new entries from flooding the group
+	maxPostsPerPoll = 10
+	maxFeedBytes    = 5 << 20
+	batchSize       = 10
+)
+
+// Poller fetches due feed sources and posts their new entries as bot links
+type Poller struct {
+	queries  *supabase.Queries
+	client   *http.Client
+	interval time.Duration
+	// create posts a link; tests replace it to avoid expanding URLs
+	create func(context.Context, *supabase.Queries, supabase.CreateLinkParams) (supabase.Link, error)
+}
+
+// NewPoller builds a poller. Feed URLs are chosen by group admins, so a nil
+// client means netguard.Client; pass one to poll a feed on localhost.
+func NewPoller(queries *supabase.Queries, client *http.Client) *Poller {
+	if client == nil {
+		client = netguard.Client(20 * time.Second)
+	}
+
+	return &Poller{
+		queries:  queries,
+		client:   client,
+		interval: time.Minute,
+		create:   links.Create,
+	}
+}
+
+// Start runs the poller until ctx is cancelled
+func (p *Poller) Start(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		p.RunOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce polls every source that is currently due
+func (p *Poller) RunOnce(ctx context.Context) {
+	sources, err := p.queries.ClaimDueFeedSources(ctx, batchSize)
+	if err != nil {
+		log.Printf("feedsources: failed to claim sources: %v", err)
+		return
+	}
+
+	for _, source := range sources {
+		p.Poll(ctx, source)
+	}
+}
+
+type fetchResult struct {
+	notModified  bool
+	body         []byte
+	etag         string
+	lastModified string
+}
+
+// Poll fetches one source and records the outcome on it
+func (p *Poller) Poll(ctx context.Context, source supabase.GroupFeedSource) {
+	record := supabase.RecordFeedFetchParams{
+		Etag:         source.Etag,
+		LastModified: source.LastModified,
+		ID:           source.ID,
+	}
+
+	result, err := p.fetch(ctx, source)
+	if err == nil && !result.notModified {
+		var title string
+		title, err = p.ingest(ctx, source, result.body)
+		record.Title = utils.TextOrNull(title)
+
+		// A feed that failed to ingest keeps its old validators, so the next
+		// poll fetches it in full rather than getting a 304
+		if err == nil {
+			record.Etag = utils.TextOrNull(result.etag)
+			record.LastModified = utils.TextOrNull(result.lastModified)
+		}
+	}
+
+	if err != nil {
+		record.LastError = pgtype.Text{String: err.Error(), Valid: true}
+	}
+
+	err = p.queries.RecordFeedFetch(ctx, record)
+	if err != nil {
+		log.Printf("feedsources: failed to record fetch of %s: %v", source.Url, err)
+	}
+}
+
+func (p *Poller) fetch(ctx context.Context, source supabase.GroupFeedSource) (fetchResult, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source.Url, nil)
+	if err != nil {
+		return fetchResult{}, err
+	}
+
+	req.Header.Set("User-Agent", "Cove-FeedPoller/1.0")
+	req.Header.Set("Accept", "application/rss+xml, application/atom+xml, application/xml;q=0.9, */*;q=0.8")
+	if source.Etag.Valid {
+		req.Header.Set("If-None-Match", source.Etag.String)
+	}
+	if source.LastModified.Valid {
+		req.Header.Set("If-Modified-Since", source.LastModified.String)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fetchResult{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return fetchResult{notModified: true}, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fetchResult{}, fmt.Errorf("feed responded with %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxFeedBytes+1))
+	if err != nil {
+		return fetchResult{}, err
+	}
+	if len(body) > maxFeedBytes {
+		return fetchResult{}, fmt.Errorf("feed is larger than %d bytes", maxFeedBytes)
+	}
+
+	return fetchResult{
+		body:         body,
+		etag:         resp.Header.Get("ETag"),
+		lastModified: resp.Header.Get("Last-Modified"),
+	}, nil
+}
+
+// ingest dedupes entries by GUID and posts the new ones, oldest first. It
+// returns the feed's title.
+func (p *Poller) ingest(ctx context.Context, source supabase.GroupFeedSource, body []byte) (string, error) {
+	parsed, err := Parse(body)
+	if err != nil {
+		return "", err
+	}
+
+	seenBefore, err := p.queries.HasFeedSourceEntries(ctx, source.ID)
+	if err != nil {
+		return parsed.Title, err
+	}
+
+	entries := parsed.Entries
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Published.Before(entries[j].Published)
+	})
+
+	var fresh []Entry
+	for _, entry := range entries {
+		inserted, err := p.queries.InsertFeedSourceEntry(ctx, supabase.InsertFeedSourceEntryParams{
+			SourceID: source.ID,
+			Guid:     entry.GUID,
+		})
+		if err != nil {
+			return parsed.Title, err
+		}
+		if inserted == 1 {
+			fresh = append(fresh, entry)
+		}
+	}
+
+	limit := maxPostsPerPoll
+	if !seenBefore {
+		limit = initialBackfill
+	}
+	if len(fresh) > limit {
+		fresh = fresh[len(fresh)-limit:]
+	}
+
+	feedTitle := parsed.Title
+	if feedTitle == "" {
+		feedTitle = source.Url
+	}
+
+	for _, entry := range fresh {
+		p.post(ctx, source, feedTitle, entry)
+	}
+
+	return parsed.Title, nil
+}
+
+func (p *Poller) post(ctx context.Context, source supabase.GroupFeedSource, feedTitle string, entry Entry) {
+	link, err := p.create(ctx, p.queries, supabase.CreateLinkParams{
+		GroupID: source.GroupID,
+		Url:     entry.URL,
+		Title:   utils.TextOrNull(entry.Title),
+		Comment: utils.TextOrNull("From " + feedTitle),
+	})
+	if errors.Is(err, links.ErrRejected) || errors.Is(err, links.ErrDuplicate) {
+		// Retrying would give the same answer, so the entry stays seen
+		log.Printf("feedsources: skipped %s: %v", entry.URL, err)
+		return
+	}
+	if err != nil {
+		log.Printf("feedsources: failed to post %s: %v", entry.URL, err)
+
+		// Forget the entry so the next poll retries it
+		err = p.queries.DeleteFeedSourceEntry(ctx, supabase.DeleteFeedSourceEntryParams{
+			SourceID: source.ID,
+			Guid:     entry.GUID,
+		})
+		if err != nil {
+			log.Printf("feedsources: failed to release entry %s: %v", entry.GUID, err)
+		}
+		return
+	}
+
+	err = p.queries.SetFeedSourceEntryLink(ctx, supabase.SetFeedSourceEntryLinkParams{
+		LinkID:   link.ID,
+		SourceID: source.ID,
+		Guid:     entry.GUID,
+	})
+	if err != nil {
+		log.Printf("feedsources: failed to link entry %s: %v", entry.GUID, err)
+	}
+}
@@ -0,0 +1,299 @@
+package feedsources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	supabase "github.com/egeuysall/cove/internal/supabase/generated"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// fakeDB answers the handful of queries the poller makes, keyed on the
+// "-- name:" line sqlc puts at the top of each query
+type fakeDB struct {
+	mu      sync.Mutex
+	entries map[string]bool
+	fetches []supabase.RecordFeedFetchParams
+}
+
+func newFakeDB() *fakeDB {
+	return &fakeDB{entries: map[string]bool{}}
+}
+
+func queryName(sql string) string {
+	fields := strings.Fields(sql)
+	if len(fields) < 3 || fields[0] != "--" || fields[1] != "name:" {
+		return ""
+	}
+	return fields[2]
+}
+
+func (db *fakeDB) Exec(_ context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	switch queryName(sql) {
+	case "InsertFeedSourceEntry":
+		guid := args[1].(string)
+		if db.entries[guid] {
+			return pgconn.NewCommandTag("INSERT 0 0"), nil
+		}
+		db.entries[guid] = true
+		return pgconn.NewCommandTag("INSERT 0 1"), nil
+	case "DeleteFeedSourceEntry":
+		delete(db.entries, args[1].(string))
+		return pgconn.NewCommandTag("DELETE 1"), nil
+	case "SetFeedSourceEntryLink":
+		return pgconn.NewCommandTag("UPDATE 1"), nil
+	case "RecordFeedFetch":
+		db.fetches = append(db.fetches, supabase.RecordFeedFetchParams{
+			Title:        args[0].(pgtype.Text),
+			Etag:         args[1].(pgtype.Text),
+			LastModified: args[2].(pgtype.Text),
+			LastError:    args[3].(pgtype.Text),
+			ID:           args[4].(pgtype.UUID),
+		})
+		return pgconn.NewCommandTag("UPDATE 1"), nil
+	}
+	return pgconn.CommandTag{}, fmt.Errorf("fakeDB: unexpected exec %q", queryName(sql))
+}
+
+func (db *fakeDB) QueryRow(_ context.Context, sql string, _ ...interface{}) pgx.Row {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if queryName(sql) == "HasFeedSourceEntries" {
+		return fakeRow{value: len(db.entries) > 0}
+	}
+	return fakeRow{err: fmt.Errorf("fakeDB: unexpected query %q", queryName(sql))}
+}
+
+func (db *fakeDB) Query(_ context.Context, sql string, _ ...interface{}) (pgx.Rows, error) {
+	return nil, fmt.Errorf("fakeDB: unexpected query %q", queryName(sql))
+}
+
+func (db *fakeDB) CopyFrom(context.Context, pgx.Identifier, []string, pgx.CopyFromSource) (int64, error) {
+	return 0, errors.New("fakeDB: unexpected copy")
+}
+
+func (db *fakeDB) lastFetch(t *testing.T) supabase.RecordFeedFetchParams {
+	t.Helper()
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if len(db.fetches) == 0 {
+		t.Fatal("no fetch was recorded")
+	}
+	return db.fetches[len(db.fetches)-1]
+}
+
+type fakeRow struct {
+	value bool
+	err   error
+}
+
+func (r fakeRow) Scan(dest ...interface{}) error {
+	if r.err != nil {
+		return r.err
+	}
+	*dest[0].(*bool) = r.value
+	return nil
+}
+
+// testPoller returns a poller that fetches through server's client and
+// collects the URLs it posts instead of creating links
+func testPoller(db *fakeDB, server *httptest.Server) (*Poller, *[]string) {
+	var posted []string
+
+	poller := NewPoller(supabase.New(db), server.Client())
+	poller.create = func(_ context.Context, _ *supabase.Queries, params supabase.CreateLinkParams) (supabase.Link, error) {
+		posted = append(posted, params.Url)
+		return supabase.Link{ID: pgtype.UUID{Bytes: [16]byte{2}, Valid: true}}, nil
+	}
+
+	return poller, &posted
+}
+
+func testSource(server *httptest.Server) supabase.GroupFeedSource {
+	return supabase.GroupFeedSource{
+		ID:      pgtype.UUID{Bytes: [16]byte{1}, Valid: true},
+		GroupID: pgtype.UUID{Bytes: [16]byte{3}, Valid: true},
+		Url:     server.URL + "/feed.xml",
+	}
+}
+
+// rssFeed builds an RSS 2.0 document with one item per GUID, published a
+// minute apart in the order given
+func rssFeed(guids ...string) string {
+	start := time.Date(2025, 9, 1, 12, 0, 0, 0, time.UTC)
+
+	var items strings.Builder
+	for i, guid := range guids {
+		fmt.Fprintf(&items, `<item><title>Entry %s</title><link>https://example.com/%s</link><guid>%s</guid><pubDate>%s</pubDate></item>`,
+			guid, guid, guid, start.Add(time.Duration(i)*time.Minute).Format(time.RFC1123Z))
+	}
+
+	return `<?xml version="1.0"?><rss version="2.0"><channel><title>Example</title>` + items.String() + `</channel></rss>`
+}
+
+func TestPollUsesValidatorsAndHandlesNotModified(t *testing.T) {
+	const etag = `"v1"`
+	const lastModified = "Mon, 01 Sep 2025 12:00:00 GMT"
+
+	var notModified int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == etag {
+			if got := r.Header.Get("If-Modified-Since"); got != lastModified {
+				t.Errorf("If-Modified-Since = %q, want %q", got, lastModified)
+			}
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Last-Modified", lastModified)
+		fmt.Fprint(w, rssFeed("a", "b"))
+	}))
+	defer server.Close()
+
+	db := newFakeDB()
+	poller, posted := testPoller(db, server)
+	source := testSource(server)
+
+	poller.Poll(context.Background(), source)
+
+	first := db.lastFetch(t)
+	if first.LastError.Valid {
+		t.Fatalf("first poll failed: %s", first.LastError.String)
+	}
+	if first.Etag.String != etag || first.LastModified.String != lastModified {
+		t.Fatalf("recorded validators %q, %q; want %q, %q", first.Etag.String, first.LastModified.String, etag, lastModified)
+	}
+	if first.Title.String != "Example" {
+		t.Errorf("recorded title %q, want %q", first.Title.String, "Example")
+	}
+	if len(*posted) != 2 {
+		t.Fatalf("first poll posted %v, want 2 links", *posted)
+	}
+
+	source.Etag = first.Etag
+	source.LastModified = first.LastModified
+	poller.Poll(context.Background(), source)
+
+	if notModified != 1 {
+		t.Fatalf("server answered 304 %d times, want 1", notModified)
+	}
+
+	second := db.lastFetch(t)
+	if second.LastError.Valid {
+		t.Fatalf("304 recorded an error: %s", second.LastError.String)
+	}
+	if second.Etag != first.Etag || second.LastModified != first.LastModified {
+		t.Errorf("304 changed validators to %q, %q", second.Etag.String, second.LastModified.String)
+	}
+	if len(*posted) != 2 {
+		t.Errorf("304 posted links: %v", *posted)
+	}
+}
+
+func TestPollDedupesByGUID(t *testing.T) {
+	feed := rssFeed("a", "b")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, feed)
+	}))
+	defer server.Close()
+
+	db := newFakeDB()
+	poller, posted := testPoller(db, server)
+	source := testSource(server)
+
+	poller.Poll(context.Background(), source)
+	if got := strings.Join(*posted, " "); got != "https://example.com/a https://example.com/b" {
+		t.Fatalf("first poll posted %q", got)
+	}
+
+	// An unchanged feed posts nothing
+	poller.Poll(context.Background(), source)
+	if len(*posted) != 2 {
+		t.Fatalf("unchanged feed posted %v", (*posted)[2:])
+	}
+
+	// A new entry is posted once, even when the feed repeats its GUID
+	feed = rssFeed("a", "b", "c", "c")
+	poller.Poll(context.Background(), source)
+	if got := strings.Join((*posted)[2:], " "); got != "https://example.com/c" {
+		t.Fatalf("poll with a new entry posted %q", got)
+	}
+}
+
+func TestPollBackfillsOnlyNewestEntries(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, rssFeed("a", "b", "c", "d", "e"))
+	}))
+	defer server.Close()
+
+	db := newFakeDB()
+	poller, posted := testPoller(db, server)
+
+	poller.Poll(context.Background(), testSource(server))
+
+	if got := strings.Join(*posted, " "); got != "https://example.com/c https://example.com/d https://example.com/e" {
+		t.Fatalf("first poll posted %q", got)
+	}
+	if len(db.entries) != 5 {
+		t.Errorf("marked %d entries as seen, want 5", len(db.entries))
+	}
+}
+
+func TestPollRecordsBadFeeds(t *testing.T) {
+	cases := []struct {
+		name   string
+		status int
+		body   string
+		want   string
+	}{
+		{"html page", http.StatusOK, `<!DOCTYPE html><html><body>Not a feed</body></html>`, ErrUnknownFormat.Error()},
+		{"plain text", http.StatusOK, "just some text", ErrUnknownFormat.Error()},
+		{"empty body", http.StatusOK, "", ErrUnknownFormat.Error()},
+		{"truncated rss", http.StatusOK, `<rss version="2.0"><channel><title>Cut`, "EOF"},
+		{"server error", http.StatusInternalServerError, rssFeed("a"), "feed responded with 500"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("ETag", `"bad"`)
+				w.WriteHeader(tc.status)
+				fmt.Fprint(w, tc.body)
+			}))
+			defer server.Close()
+
+			db := newFakeDB()
+			poller, posted := testPoller(db, server)
+
+			poller.Poll(context.Background(), testSource(server))
+
+			fetch := db.lastFetch(t)
+			if !fetch.LastError.Valid || !strings.Contains(fetch.LastError.String, tc.want) {
+				t.Fatalf("recorded error %q, want one containing %q", fetch.LastError.String, tc.want)
+			}
+			if fetch.Etag.Valid {
+				t.Errorf("bad feed stored ETag %q", fetch.Etag.String)
+			}
+			if len(*posted) != 0 || len(db.entries) != 0 {
+				t.Errorf("bad feed posted %v and marked %d entries", *posted, len(db.entries))
+			}
+		})
+	}
+}
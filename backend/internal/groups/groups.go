@@ -0,0 +1,239 @@
+package groups
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/egeuysall/cove/internal/models"
+	supabase "github.com/egeuysall/cove/internal/supabase/generated"
+	"github.com/egeuysall/cove/internal/utils"
+)
+
+// Who a permission setting lets through
+const (
+	AllowMembers = "members"
+	AllowAdmins  = "admins"
+)
+
+// Limits on a group's profile and settings
+const (
+	MaxNameLength        = 100
+	MaxDescriptionLength = 500
+	MaxEmojiLength       = 8
+	MaxMembersLimit      = 10000
+	MaxRetentionDays     = 3650
+)
+
+// DefaultSettings lets every member invite and post, with no cap on members
+// and links kept forever
+func DefaultSettings() models.GroupSettings {
+	return models.GroupSettings{
+		WhoCanInvite: AllowMembers,
+		WhoCanPost:   AllowMembers,
+	}
+}
+
+// ParseSettings reads a group's stored settings. Keys that are missing, which
+// is every key for a group that was never configured, take their defaults.
+func ParseSettings(raw []byte) models.GroupSettings {
+	settings := DefaultSettings()
+	if len(raw) > 0 {
+		// The column is only written after ValidateSettings, so anything that
+		// fails to parse is left at its default
+		_ = json.Unmarshal(raw, &settings)
+	}
+	if settings.WhoCanInvite == "" {
+		settings.WhoCanInvite = AllowMembers
+	}
+	if settings.WhoCanPost == "" {
+		settings.WhoCanPost = AllowMembers
+	}
+	return settings
+}
+
+func ValidateSettings(settings models.GroupSettings) error {
+	if settings.WhoCanInvite != AllowMembers && settings.WhoCanInvite != AllowAdmins {
+		return errors.New("settings.who_can_invite must be members or admins")
+	}
+	if settings.WhoCanPost != AllowMembers && settings.WhoCanPost != AllowAdmins {
+		return errors.New("settings.who_can_post must be members or admins")
+	}
+	if settings.MaxMembers < 0 || settings.MaxMembers > MaxMembersLimit {
+		return fmt.Errorf("settings.max_members must be between 0 and %d", MaxMembersLimit)
+	}
+	if settings.RetentionDays < 0 || settings.RetentionDays > MaxRetentionDays {
+		return fmt.Errorf("settings.retention_days must be between 0 and %d", MaxRetentionDays)
+	}
+	return nil
+}
+
+// IsAdminRole reports whether a group_members role counts as an admin
+func IsAdminRole(role string) bool {
+	return role == "owner" || role == "admin"
+}
+
+func allows(setting, role string) bool {
+	return setting == AllowMembers || IsAdminRole(role)
+}
+
+// CanInvite reports whether a member with role may invite people
+func CanInvite(settings models.GroupSettings, role string) bool {
+	return allows(settings.WhoCanInvite, role)
+}
+
+// CanPost reports whether a member with role may post links
+func CanPost(settings models.GroupSettings, role string) bool {
+	return allows(settings.WhoCanPost, role)
+}
+
+// IsFull reports whether a group with memberCount members has no room left
+func IsFull(settings models.GroupSettings, memberCount int64) bool {
+	return settings.MaxMembers > 0 && memberCount >= int64(settings.MaxMembers)
+}
+
+func ToResponse(group supabase.Group) models.GroupResponse {
+	return models.GroupResponse{
+		ID:          utils.UUIDToString(group.ID),
+		Name:        group.Name,
+		CreatedBy:   utils.UUIDToString(group.CreatedBy),
+		CreatedAt:   group.CreatedAt.Time,
+		Description: group.Description.String,
+		Emoji:       group.Emoji.String,
+		AvatarURL:   group.AvatarUrl.String,
+		Settings:    ParseSettings(group.Settings),
+		UpdatedAt:   group.UpdatedAt.Time,
+	}
+}
+
+// ApplyPatch applies a JSON Merge Patch (RFC 7396) to group. A null member
+// clears description, emoji or avatar_url and resets settings, or a single
+// setting, to its default. The result is validated; the returned error is
+// safe to show to the client.
+func ApplyPatch(group supabase.Group, patch []byte) (supabase.UpdateGroupParams, error) {
+	params := supabase.UpdateGroupParams{
+		ID:          group.ID,
+		Name:        group.Name,
+		Description: group.Description,
+		Emoji:       group.Emoji,
+		AvatarUrl:   group.AvatarUrl,
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(patch, &fields); err != nil || fields == nil {
+		return params, errors.New("The patch must be a JSON object")
+	}
+
+	settings := ParseSettings(group.Settings)
+
+	for key, value := range fields {
+		var err error
+		switch key {
+		case "name":
+			if utils.IsJSONNull(value) {
+				return params, errors.New("Name cannot be removed")
+			}
+			if json.Unmarshal(value, &params.Name) != nil {
+				err = errors.New("must be a string")
+			}
+		case "description":
+			params.Description, err = utils.PatchText(value)
+		case "emoji":
+			params.Emoji, err = utils.PatchText(value)
+		case "avatar_url":
+			params.AvatarUrl, err = utils.PatchText(value)
+		case "settings":
+			settings, err = patchSettings(settings, value)
+		default:
+			return params, fmt.Errorf("Unknown field %q", key)
+		}
+		if err != nil {
+			return params, fmt.Errorf("Invalid %s: %w", key, err)
+		}
+	}
+
+	params.Name = strings.TrimSpace(params.Name)
+	if err := validateProfile(params); err != nil {
+		return params, err
+	}
+	if err := ValidateSettings(settings); err != nil {
+		return params, err
+	}
+
+	raw, err := json.Marshal(settings)
+	if err != nil {
+		return params, err
+	}
+	params.Settings = raw
+
+	return params, nil
+}
+
+func patchSettings(settings models.GroupSettings, value json.RawMessage) (models.GroupSettings, error) {
+	if utils.IsJSONNull(value) {
+		return DefaultSettings(), nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(value, &fields); err != nil || fields == nil {
+		return settings, errors.New("must be an object or null")
+	}
+
+	defaults := DefaultSettings()
+	for key, raw := range fields {
+		var target interface{}
+		switch key {
+		case "who_can_invite":
+			settings.WhoCanInvite = defaults.WhoCanInvite
+			target = &settings.WhoCanInvite
+		case "who_can_post":
+			settings.WhoCanPost = defaults.WhoCanPost
+			target = &settings.WhoCanPost
+		case "max_members":
+			settings.MaxMembers = defaults.MaxMembers
+			target = &settings.MaxMembers
+		case "retention_days":
+			settings.RetentionDays = defaults.RetentionDays
+			target = &settings.RetentionDays
+		default:
+			return settings, fmt.Errorf("unknown setting %q", key)
+		}
+		if utils.IsJSONNull(raw) {
+			continue
+		}
+		if err := json.Unmarshal(raw, target); err != nil {
+			return settings, fmt.Errorf("%s has the wrong type", key)
+		}
+	}
+
+	return settings, nil
+}
+
+func validateProfile(params supabase.UpdateGroupParams) error {
+	if params.Name == "" {
+		return errors.New("Name is required")
+	}
+	if utf8.RuneCountInString(params.Name) > MaxNameLength {
+		return fmt.Errorf("Name must be at most %d characters", MaxNameLength)
+	}
+
+	if utf8.RuneCountInString(params.Description.String) > MaxDescriptionLength {
+		return fmt.Errorf("Description must be at most %d characters", MaxDescriptionLength)
+	}
+
+	if params.Emoji.Valid {
+		emoji := params.Emoji.String
+		if utf8.RuneCountInString(emoji) > MaxEmojiLength || strings.IndexFunc(emoji, unicode.IsSpace) >= 0 {
+			return errors.New("Emoji must be a single emoji")
+		}
+	}
+
+	if params.AvatarUrl.Valid && !utils.IsAvatarURL(params.AvatarUrl.String) {
+		return errors.New("Avatar URL must be an https URL")
+	}
+
+	return nil
+}
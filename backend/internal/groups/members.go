@@ -0,0 +1,94 @@
+package groups
+
+import (
+	"context"
+	"errors"
+
+	supabase "github.com/egeuysall/cove/internal/supabase/generated"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrInviteUsed    = errors.New("invite does not exist or was already used")
+	ErrAlreadyMember = errors.New("already a member of this group")
+	ErrFull          = errors.New("group is full")
+)
+
+// addMember adds userId to groupId unless that would take the group past its
+// max_members setting. queries must run in a transaction: the group's row
+// stays locked until it ends, so concurrent joins are counted one at a time.
+func addMember(ctx context.Context, queries *supabase.Queries, groupId, userId pgtype.UUID) error {
+	settings, err := queries.LockGroupSettings(ctx, groupId)
+	if err != nil {
+		return err
+	}
+
+	version, err := queries.GetGroupMembersVersion(ctx, groupId)
+	if err != nil {
+		return err
+	}
+
+	if IsFull(ParseSettings(settings), version.MemberCount) {
+		return ErrFull
+	}
+
+	added, err := queries.AddUserToGroup(ctx, supabase.AddUserToGroupParams{
+		UserID:  userId,
+		GroupID: groupId,
+	})
+	if err != nil {
+		return err
+	}
+	if added == 0 {
+		return ErrAlreadyMember
+	}
+	return nil
+}
+
+// AddMember adds userId to groupId, failing with ErrFull when the group has
+// no room and ErrAlreadyMember when they are in it already
+func AddMember(ctx context.Context, db *pgxpool.Pool, groupId, userId pgtype.UUID) error {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := addMember(ctx, supabase.New(tx), groupId, userId); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// AcceptInvite claims the invite with code for userId and adds them to its
+// group, in one transaction. Either both happen or neither does, and of
+// several concurrent accepts of one code only the first gets through.
+func AcceptInvite(ctx context.Context, db *pgxpool.Pool, code string, userId pgtype.UUID) (supabase.Invite, error) {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return supabase.Invite{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	queries := supabase.New(tx)
+
+	invite, err := queries.ClaimInvite(ctx, supabase.ClaimInviteParams{
+		UsedBy: userId,
+		Code:   code,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return supabase.Invite{}, ErrInviteUsed
+		}
+		return supabase.Invite{}, err
+	}
+
+	// Failing here rolls the claim back, leaving the invite for someone else
+	if err := addMember(ctx, queries, invite.GroupID, userId); err != nil {
+		return supabase.Invite{}, err
+	}
+
+	return invite, tx.Commit(ctx)
+}
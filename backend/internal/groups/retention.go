@@ -0,0 +1,45 @@
+package groups
+
+import (
+	"context"
+	"log"
+	"time"
+
+	supabase "github.com/egeuysall/cove/internal/supabase/generated"
+)
+
+// RetentionPruner deletes links that are older than their group's
+// retention_days setting
+type RetentionPruner struct {
+	queries  *supabase.Queries
+	interval time.Duration
+}
+
+func NewRetentionPruner(queries *supabase.Queries) *RetentionPruner {
+	return &RetentionPruner{
+		queries:  queries,
+		interval: time.Hour,
+	}
+}
+
+// Start prunes until ctx is cancelled
+func (p *RetentionPruner) Start(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := p.queries.DeleteExpiredGroupLinks(ctx)
+			if err != nil {
+				log.Printf("groups: failed to prune expired links: %v", err)
+				continue
+			}
+			if deleted > 0 {
+				log.Printf("groups: pruned %d expired links", deleted)
+			}
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/egeuysall/cove/internal/blocklist"
+	"github.com/egeuysall/cove/internal/models"
+	supabase "github.com/egeuysall/cove/internal/supabase/generated"
+	"github.com/egeuysall/cove/internal/utils"
+	"github.com/go-chi/chi/v5"
+)
+
+func toBlockedDomainResponse(blocked supabase.GroupBlockedDomain) models.BlockedDomainResponse {
+	return models.BlockedDomainResponse{
+		Domain:            blocked.Domain,
+		IncludeSubdomains: blocked.IncludeSubdomains,
+		CreatedBy:         utils.UUIDToString(blocked.CreatedBy),
+		CreatedAt:         blocked.CreatedAt.Time,
+	}
+}
+
+func HandleBlockDomain(w http.ResponseWriter, r *http.Request) {
+	groupId, userId, ok := requireGroupAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.BlockDomainRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		utils.SendError(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	domain, includeSubdomains, err := blocklist.Normalize(req.Domain)
+	if err != nil {
+		utils.SendError(w, "Domain must be a host name such as example.com or *.example.com", http.StatusBadRequest)
+		return
+	}
+
+	addParams := supabase.AddBlockedDomainParams{
+		GroupID:           groupId,
+		Domain:            domain,
+		IncludeSubdomains: includeSubdomains,
+		CreatedBy:         userId,
+	}
+
+	blocked, err := utils.Queries.AddBlockedDomain(r.Context(), addParams)
+	if err != nil {
+		utils.SendError(w, "Error blocking domain", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SendJson(w, toBlockedDomainResponse(blocked), http.StatusCreated)
+}
+
+func HandleGetBlockedDomains(w http.ResponseWriter, r *http.Request) {
+	groupId, _, ok := requireGroupAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	blocked, err := utils.Queries.ListBlockedDomains(r.Context(), groupId)
+	if err != nil {
+		utils.SendError(w, "Failed to get blocked domains", http.StatusInternalServerError)
+		return
+	}
+
+	response := make([]models.BlockedDomainResponse, 0, len(blocked))
+	for _, entry := range blocked {
+		response = append(response, toBlockedDomainResponse(entry))
+	}
+
+	utils.SendJson(w, response, http.StatusOK)
+}
+
+func HandleUnblockDomain(w http.ResponseWriter, r *http.Request) {
+	groupId, _, ok := requireGroupAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	domain, _, err := blocklist.Normalize(chi.URLParam(r, "domain"))
+	if err != nil {
+		utils.SendError(w, "Invalid domain", http.StatusBadRequest)
+		return
+	}
+
+	deleteParams := supabase.DeleteBlockedDomainParams{
+		GroupID: groupId,
+		Domain:  domain,
+	}
+
+	deleted, err := utils.Queries.DeleteBlockedDomain(r.Context(), deleteParams)
+	if err != nil {
+		utils.SendError(w, "Failed to unblock domain", http.StatusInternalServerError)
+		return
+	}
+	if deleted == 0 {
+		utils.SendError(w, "Domain is not blocked", http.StatusNotFound)
+		return
+	}
+
+	utils.SendJson(w, "Domain unblocked", http.StatusOK)
+}
@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/egeuysall/cove/internal/digest"
+	"github.com/egeuysall/cove/internal/middleware"
+	"github.com/egeuysall/cove/internal/models"
+	supabase "github.com/egeuysall/cove/internal/supabase/generated"
+	"github.com/egeuysall/cove/internal/utils"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func toDigestSettingsResponse(sub supabase.DigestSubscription) models.DigestSettingsResponse {
+	response := models.DigestSettingsResponse{
+		Email:     sub.Email,
+		Frequency: sub.Frequency,
+		Timezone:  sub.Timezone,
+		SendHour:  int(sub.SendHour),
+	}
+	if sub.LastSentAt.Valid {
+		response.LastSentAt = &sub.LastSentAt.Time
+	}
+	return response
+}
+
+func HandleGetDigestSettings(w http.ResponseWriter, r *http.Request) {
+	userIdStr, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		utils.SendError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	userId, err := utils.ParseUUID(userIdStr)
+	if err != nil {
+		utils.SendError(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	sub, err := utils.Queries.GetDigestSubscription(r.Context(), userId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			email, _ := middleware.EmailFromContext(r.Context())
+			utils.SendJson(w, models.DigestSettingsResponse{
+				Email:     email,
+				Frequency: digest.FrequencyOff,
+				Timezone:  "UTC",
+				SendHour:  8,
+			}, http.StatusOK)
+			return
+		}
+		utils.SendError(w, "Failed to get digest settings", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SendJson(w, toDigestSettingsResponse(sub), http.StatusOK)
+}
+
+func HandleUpdateDigestSettings(w http.ResponseWriter, r *http.Request) {
+	var req models.DigestSettingsRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		utils.SendError(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	switch req.Frequency {
+	case digest.FrequencyDaily, digest.FrequencyWeekly, digest.FrequencyOff:
+	default:
+		utils.SendError(w, "Frequency must be one of daily, weekly or off", http.StatusBadRequest)
+		return
+	}
+
+	if req.Timezone == "" {
+		req.Timezone = "UTC"
+	}
+
+	_, err = time.LoadLocation(req.Timezone)
+	if err != nil {
+		utils.SendError(w, "Invalid timezone", http.StatusBadRequest)
+		return
+	}
+
+	sendHour := 8
+	if req.SendHour != nil {
+		sendHour = *req.SendHour
+	}
+
+	if sendHour < 0 || sendHour > 23 {
+		utils.SendError(w, "Send hour must be between 0 and 23", http.StatusBadRequest)
+		return
+	}
+
+	userIdStr, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		utils.SendError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	userId, err := utils.ParseUUID(userIdStr)
+	if err != nil {
+		utils.SendError(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	// Digests only go to the address the account signed in with, so nobody
+	// can sign someone else's inbox up for mail
+	email, _ := middleware.EmailFromContext(r.Context())
+	if email == "" {
+		utils.SendError(w, "Your account has no email address", http.StatusBadRequest)
+		return
+	}
+
+	if req.Email != "" {
+		addr, err := mail.ParseAddress(req.Email)
+		if err != nil {
+			utils.SendError(w, "Invalid email address", http.StatusBadRequest)
+			return
+		}
+		if !strings.EqualFold(addr.Address, email) {
+			utils.SendError(w, "Digests can only be sent to your account's email address", http.StatusBadRequest)
+			return
+		}
+	}
+
+	upsertParams := supabase.UpsertDigestSubscriptionParams{
+		UserID:    userId,
+		Email:     email,
+		Frequency: req.Frequency,
+		Timezone:  req.Timezone,
+		SendHour:  int32(sendHour),
+	}
+
+	sub, err := utils.Queries.UpsertDigestSubscription(r.Context(), upsertParams)
+	if err != nil {
+		utils.SendError(w, "Failed to update digest settings", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SendJson(w, toDigestSettingsResponse(sub), http.StatusOK)
+}
+
+// verifyUnsubscribeLink checks the signed user and sig query parameters of
+// an unsubscribe link
+func verifyUnsubscribeLink(w http.ResponseWriter, r *http.Request) (pgtype.UUID, bool) {
+	userIdStr := r.URL.Query().Get("user")
+	sig := r.URL.Query().Get("sig")
+
+	if userIdStr == "" || sig == "" || !digest.VerifyUnsubscribe(userIdStr, sig) {
+		utils.SendError(w, "Invalid unsubscribe link", http.StatusForbidden)
+		return pgtype.UUID{}, false
+	}
+
+	userId, err := utils.ParseUUID(userIdStr)
+	if err != nil {
+		utils.SendError(w, "Invalid user ID", http.StatusBadRequest)
+		return pgtype.UUID{}, false
+	}
+
+	return userId, true
+}
+
+// HandleDigestUnsubscribePage serves the signed link from digest emails. It
+// only asks the reader to confirm: link scanners and prefetchers issue GETs
+// too, and must not unsubscribe anyone.
+func HandleDigestUnsubscribePage(w http.ResponseWriter, r *http.Request) {
+	if _, ok := verifyUnsubscribeLink(w, r); !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	err := digest.RenderUnsubscribePage(w, false)
+	if err != nil {
+		log.Printf("digest: failed to render unsubscribe page: %v", err)
+	}
+}
+
+// HandleDigestUnsubscribe unsubscribes the link's user. It is both the RFC
+// 8058 one-click request from mail clients and the confirmation form on the
+// unsubscribe page; the form gets a page back, mail clients get JSON.
+func HandleDigestUnsubscribe(w http.ResponseWriter, r *http.Request) {
+	userId, ok := verifyUnsubscribeLink(w, r)
+	if !ok {
+		return
+	}
+
+	err := utils.Queries.UnsubscribeDigest(r.Context(), userId)
+	if err != nil {
+		utils.SendError(w, "Failed to unsubscribe", http.StatusInternalServerError)
+		return
+	}
+
+	if strings.Contains(r.Header.Get("Accept"), "text/html") {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		err = digest.RenderUnsubscribePage(w, true)
+		if err != nil {
+			log.Printf("digest: failed to render unsubscribe page: %v", err)
+		}
+		return
+	}
+
+	utils.SendJson(w, "You have been unsubscribed from Cove digests", http.StatusOK)
+}
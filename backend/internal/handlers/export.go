@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/egeuysall/cove/internal/export"
+	supabase "github.com/egeuysall/cove/internal/supabase/generated"
+	"github.com/egeuysall/cove/internal/utils"
+	"github.com/jackc/pgx/v5"
+)
+
+// HandleExportGroup streams the group archive as an attachment. The route is
+// exempt from the request timeout, so a large group is limited only by how
+// fast the client reads.
+func HandleExportGroup(w http.ResponseWriter, r *http.Request) {
+	groupId, userId, ok := memberFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = export.FormatJSON
+	}
+
+	if !export.IsValidFormat(format) {
+		utils.SendError(w, "Format must be json, ndjson, csv, html or zip", http.StatusBadRequest)
+		return
+	}
+
+	adminParams := supabase.IsGroupAdminParams{
+		GroupID: groupId,
+		UserID:  userId,
+	}
+
+	// Moderators export hidden links too; members only their own
+	isAdmin, err := utils.Queries.IsGroupAdmin(r.Context(), adminParams)
+	if err != nil {
+		utils.SendError(w, "Error checking group role", http.StatusInternalServerError)
+		return
+	}
+
+	source, err := export.Open(r.Context(), utils.DB, groupId, userId, isAdmin)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.SendError(w, "Group not found", http.StatusNotFound)
+			return
+		}
+		utils.SendError(w, "Failed to start export", http.StatusInternalServerError)
+		return
+	}
+	defer source.Close(r.Context())
+
+	w.Header().Set("Content-Type", export.ContentType(format))
+	w.Header().Set("Content-Disposition", `attachment; filename="`+source.Filename(format)+`"`)
+	w.WriteHeader(http.StatusOK)
+
+	// Headers are already sent, so a failure part way can only be logged and
+	// the response cut short
+	err = source.Write(r.Context(), w, format)
+	if err != nil {
+		log.Printf("export: group %s as %s failed: %v", utils.UUIDToString(groupId), format, err)
+	}
+}
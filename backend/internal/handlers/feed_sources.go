@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/egeuysall/cove/internal/models"
+	supabase "github.com/egeuysall/cove/internal/supabase/generated"
+	"github.com/egeuysall/cove/internal/utils"
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	defaultFeedSourceMinutes = 30
+	minFeedSourceMinutes     = 5
+)
+
+func toFeedSourceResponse(source supabase.GroupFeedSource) models.FeedSourceResponse {
+	response := models.FeedSourceResponse{
+		ID:              utils.UUIDToString(source.ID),
+		GroupID:         utils.UUIDToString(source.GroupID),
+		URL:             source.Url,
+		Title:           source.Title.String,
+		IntervalMinutes: int(source.IntervalSeconds / 60),
+		LastError:       source.LastError.String,
+		CreatedAt:       source.CreatedAt.Time,
+	}
+	if source.LastFetchedAt.Valid {
+		response.LastFetchedAt = &source.LastFetchedAt.Time
+	}
+	return response
+}
+
+func HandleCreateFeedSource(w http.ResponseWriter, r *http.Request) {
+	groupId, userId, ok := requireGroupAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.CreateFeedSourceRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		utils.SendError(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	if !isValidLinkURL(req.URL) {
+		utils.SendError(w, "A valid http or https feed URL is required", http.StatusBadRequest)
+		return
+	}
+
+	if req.IntervalMinutes == 0 {
+		req.IntervalMinutes = defaultFeedSourceMinutes
+	}
+
+	if req.IntervalMinutes < minFeedSourceMinutes {
+		utils.SendError(w, "Interval must be at least 5 minutes", http.StatusBadRequest)
+		return
+	}
+
+	createParams := supabase.CreateFeedSourceParams{
+		GroupID:         groupId,
+		Url:             req.URL,
+		IntervalSeconds: int32(req.IntervalMinutes * 60),
+		CreatedBy:       userId,
+	}
+
+	source, err := utils.Queries.CreateFeedSource(r.Context(), createParams)
+	if err != nil {
+		if utils.IsUniqueViolation(err) {
+			utils.SendError(w, "This group already follows that feed", http.StatusConflict)
+			return
+		}
+		utils.SendError(w, "Error creating feed source", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SendJson(w, toFeedSourceResponse(source), http.StatusCreated)
+}
+
+func HandleGetFeedSources(w http.ResponseWriter, r *http.Request) {
+	groupId, _, ok := memberFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	sources, err := utils.Queries.ListFeedSourcesByGroup(r.Context(), groupId)
+	if err != nil {
+		utils.SendError(w, "Failed to get feed sources", http.StatusInternalServerError)
+		return
+	}
+
+	response := make([]models.FeedSourceResponse, 0, len(sources))
+	for _, source := range sources {
+		response = append(response, toFeedSourceResponse(source))
+	}
+
+	utils.SendJson(w, response, http.StatusOK)
+}
+
+func HandleDeleteFeedSource(w http.ResponseWriter, r *http.Request) {
+	groupId, _, ok := requireGroupAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	sourceId, err := utils.ParseUUID(chi.URLParam(r, "sid"))
+	if err != nil {
+		utils.SendError(w, "Invalid feed source ID", http.StatusBadRequest)
+		return
+	}
+
+	deleteParams := supabase.DeleteFeedSourceParams{
+		ID:      sourceId,
+		GroupID: groupId,
+	}
+
+	deleted, err := utils.Queries.DeleteFeedSource(r.Context(), deleteParams)
+	if err != nil {
+		utils.SendError(w, "Failed to delete feed source", http.StatusInternalServerError)
+		return
+	}
+	if deleted == 0 {
+		utils.SendError(w, "Feed source not found", http.StatusNotFound)
+		return
+	}
+
+	utils.SendJson(w, "Feed source deleted", http.StatusOK)
+}
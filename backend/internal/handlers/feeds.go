@@ -0,0 +1,340 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/egeuysall/cove/internal/blocklist"
+	"github.com/egeuysall/cove/internal/feeds"
+	"github.com/egeuysall/cove/internal/middleware"
+	"github.com/egeuysall/cove/internal/models"
+	supabase "github.com/egeuysall/cove/internal/supabase/generated"
+	"github.com/egeuysall/cove/internal/utils"
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const feedItemLimit = 50
+
+// publicBaseURL is where this API is reachable from the outside, used to
+// build absolute URLs that leave the app (feeds, emails).
+func publicBaseURL(r *http.Request) string {
+	if base := os.Getenv("PUBLIC_URL"); base != "" {
+		return strings.TrimRight(base, "/")
+	}
+
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
+
+func feedURL(r *http.Request, groupId, format, token string) string {
+	return publicBaseURL(r) + "/feeds/groups/" + groupId + "." + format + "?token=" + token
+}
+
+func toFeedTokenResponse(token supabase.FeedToken) models.FeedTokenResponse {
+	response := models.FeedTokenResponse{
+		ID:        utils.UUIDToString(token.ID),
+		GroupID:   utils.UUIDToString(token.GroupID),
+		CreatedAt: token.CreatedAt.Time,
+	}
+	if token.LastUsedAt.Valid {
+		response.LastUsedAt = &token.LastUsedAt.Time
+	}
+	return response
+}
+
+// memberFromRequest resolves the {id} group and checks the caller belongs to it
+func memberFromRequest(w http.ResponseWriter, r *http.Request) (pgtype.UUID, pgtype.UUID, bool) {
+	var groupId, userId pgtype.UUID
+
+	groupIdStr := chi.URLParam(r, "id")
+	if groupIdStr == "" {
+		utils.SendError(w, "Missing group ID parameter", http.StatusBadRequest)
+		return groupId, userId, false
+	}
+
+	groupId, err := utils.ParseUUID(groupIdStr)
+	if err != nil {
+		utils.SendError(w, "Invalid group ID format", http.StatusBadRequest)
+		return groupId, userId, false
+	}
+
+	userIdStr, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		utils.SendError(w, "Unauthorized", http.StatusUnauthorized)
+		return groupId, userId, false
+	}
+
+	userId, err = utils.ParseUUID(userIdStr)
+	if err != nil {
+		utils.SendError(w, "Invalid user ID", http.StatusBadRequest)
+		return groupId, userId, false
+	}
+
+	inGroupParams := supabase.IsUserInGroupParams{
+		GroupID: groupId,
+		UserID:  userId,
+	}
+
+	isMember, err := utils.Queries.IsUserInGroup(r.Context(), inGroupParams)
+	if err != nil {
+		utils.SendError(w, "Error checking group membership", http.StatusInternalServerError)
+		return groupId, userId, false
+	}
+	if !isMember {
+		utils.SendError(w, "You are not a member of this group", http.StatusForbidden)
+		return groupId, userId, false
+	}
+
+	return groupId, userId, true
+}
+
+func HandleCreateFeedToken(w http.ResponseWriter, r *http.Request) {
+	groupId, userId, ok := memberFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	token, hash, err := feeds.GenerateToken()
+	if err != nil {
+		utils.SendError(w, "Failed to generate feed token", http.StatusInternalServerError)
+		return
+	}
+
+	createParams := supabase.CreateFeedTokenParams{
+		UserID:    userId,
+		GroupID:   groupId,
+		TokenHash: hash,
+	}
+
+	feedToken, err := utils.Queries.CreateFeedToken(r.Context(), createParams)
+	if err != nil {
+		utils.SendError(w, "Error creating feed token", http.StatusInternalServerError)
+		return
+	}
+
+	groupIdStr := utils.UUIDToString(groupId)
+
+	response := toFeedTokenResponse(feedToken)
+	response.Token = token
+	response.URLs = map[string]string{
+		feeds.FormatRSS:  feedURL(r, groupIdStr, feeds.FormatRSS, token),
+		feeds.FormatAtom: feedURL(r, groupIdStr, feeds.FormatAtom, token),
+		feeds.FormatJSON: feedURL(r, groupIdStr, feeds.FormatJSON, token),
+	}
+
+	utils.SendJson(w, response, http.StatusCreated)
+}
+
+func HandleGetFeedTokens(w http.ResponseWriter, r *http.Request) {
+	groupId, userId, ok := memberFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	listParams := supabase.ListFeedTokensParams{
+		UserID:  userId,
+		GroupID: groupId,
+	}
+
+	tokens, err := utils.Queries.ListFeedTokens(r.Context(), listParams)
+	if err != nil {
+		utils.SendError(w, "Failed to get feed tokens", http.StatusInternalServerError)
+		return
+	}
+
+	response := make([]models.FeedTokenResponse, 0, len(tokens))
+	for _, token := range tokens {
+		response = append(response, toFeedTokenResponse(token))
+	}
+
+	utils.SendJson(w, response, http.StatusOK)
+}
+
+func HandleRevokeFeedToken(w http.ResponseWriter, r *http.Request) {
+	tokenId, err := utils.ParseUUID(chi.URLParam(r, "tid"))
+	if err != nil {
+		utils.SendError(w, "Invalid feed token ID", http.StatusBadRequest)
+		return
+	}
+
+	userIdStr, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		utils.SendError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	userId, err := utils.ParseUUID(userIdStr)
+	if err != nil {
+		utils.SendError(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	revokeParams := supabase.RevokeFeedTokenParams{
+		ID:     tokenId,
+		UserID: userId,
+	}
+
+	revoked, err := utils.Queries.RevokeFeedToken(r.Context(), revokeParams)
+	if err != nil {
+		utils.SendError(w, "Failed to revoke feed token", http.StatusInternalServerError)
+		return
+	}
+	if revoked == 0 {
+		utils.SendError(w, "Feed token not found", http.StatusNotFound)
+		return
+	}
+
+	utils.SendJson(w, "Feed token revoked", http.StatusOK)
+}
+
+// HandleGroupFeed serves a group as RSS, Atom or JSON Feed. Feed readers
+// cannot send a bearer JWT, so the caller is identified by the token query
+// parameter instead, and must still be a member of the group.
+func HandleGroupFeed(w http.ResponseWriter, r *http.Request) {
+	format := chi.URLParam(r, "format")
+	contentType := feeds.ContentType(format)
+	if contentType == "" {
+		utils.SendError(w, "Unknown feed format", http.StatusNotFound)
+		return
+	}
+
+	groupId, err := utils.ParseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.SendError(w, "Invalid group ID format", http.StatusBadRequest)
+		return
+	}
+
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		utils.SendError(w, "Unauthorized: missing feed token", http.StatusUnauthorized)
+		return
+	}
+
+	// The route's FeedToken middleware has already looked the token up
+	feedToken, ok := middleware.FeedTokenFromContext(r.Context())
+	if !ok || feedToken.GroupID != groupId {
+		utils.SendError(w, "Unauthorized: invalid feed token", http.StatusUnauthorized)
+		return
+	}
+
+	inGroupParams := supabase.IsUserInGroupParams{
+		GroupID: groupId,
+		UserID:  feedToken.UserID,
+	}
+
+	isMember, err := utils.Queries.IsUserInGroup(r.Context(), inGroupParams)
+	if err != nil {
+		utils.SendError(w, "Error checking group membership", http.StatusInternalServerError)
+		return
+	}
+	if !isMember {
+		utils.SendError(w, "You are no longer a member of this group", http.StatusForbidden)
+		return
+	}
+
+	group, err := utils.Queries.GetGroupByID(r.Context(), groupId)
+	if err != nil {
+		utils.SendError(w, "Failed to get group", http.StatusInternalServerError)
+		return
+	}
+
+	adminParams := supabase.IsGroupAdminParams{
+		GroupID: groupId,
+		UserID:  feedToken.UserID,
+	}
+
+	isAdmin, err := utils.Queries.IsGroupAdmin(r.Context(), adminParams)
+	if err != nil {
+		utils.SendError(w, "Error checking group role", http.StatusInternalServerError)
+		return
+	}
+
+	err = utils.Queries.TouchFeedToken(r.Context(), feedToken.ID)
+	if err != nil {
+		log.Printf("feeds: failed to update token usage: %v", err)
+	}
+
+	version, err := utils.Queries.GetGroupLinksVersion(r.Context(), groupId)
+	if err != nil {
+		utils.SendError(w, "Failed to get links", http.StatusInternalServerError)
+		return
+	}
+
+	// Readers poll often, so unchanged feeds are answered before the links
+	// are loaded and rendered
+	etag := utils.WeakETag(
+		version.LinkCount, version.LastUpdated.Time.UnixNano(),
+		version.BlockedCount, version.BlockedUpdated.Time.UnixNano(),
+		blocklist.Current().Version(), group.Name, format, token, isAdmin,
+	)
+
+	w.Header().Set("Cache-Control", "private, max-age=300")
+
+	// No Last-Modified is sent: deletes, prunes, unblocks and blocklist
+	// reloads change the feed without moving any timestamp forward, so only
+	// the ETag can tell readers about them
+	if utils.CheckNotModified(w, r, etag, time.Time{}) {
+		return
+	}
+
+	links, err := utils.Queries.GetLinksByGroup(r.Context(), supabase.GetLinksByGroupParams{
+		GroupID:    groupId,
+		ViewerID:   feedToken.UserID,
+		ShowHidden: isAdmin,
+		Limit:      feedItemLimit,
+		Offset:     0,
+	})
+	if err != nil {
+		utils.SendError(w, "Failed to get links", http.StatusInternalServerError)
+		return
+	}
+	links = blocklist.FilterLinks(links)
+
+	groupIdStr := utils.UUIDToString(groupId)
+	feed := feeds.Feed{
+		ID:          "urn:uuid:" + groupIdStr,
+		Title:       group.Name + " on Cove",
+		Description: "Links shared in " + group.Name,
+		SelfURL:     feedURL(r, groupIdStr, format, token),
+		Updated:     group.CreatedAt.Time,
+	}
+
+	for _, link := range links {
+		title := link.Title.String
+		if title == "" {
+			title = link.Url
+		}
+
+		feed.Items = append(feed.Items, feeds.Item{
+			ID:        "urn:uuid:" + utils.UUIDToString(link.ID),
+			URL:       link.Url,
+			Title:     title,
+			Content:   link.Comment.String,
+			Published: link.CreatedAt.Time,
+		})
+
+		if link.CreatedAt.Time.After(feed.Updated) {
+			feed.Updated = link.CreatedAt.Time
+		}
+	}
+
+	body, err := feeds.Render(feed, format)
+	if err != nil {
+		utils.SendError(w, "Failed to render feed", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	_, err = w.Write(body)
+	if err != nil {
+		log.Printf("feeds: failed to write response: %v", err)
+	}
+}
@@ -3,15 +3,24 @@ package handlers
 import (
 	"encoding/json"
 	"errors"
+	"github.com/egeuysall/cove/internal/groups"
 	"github.com/egeuysall/cove/internal/middleware"
 	"github.com/egeuysall/cove/internal/models"
 	supabase "github.com/egeuysall/cove/internal/supabase/generated"
 	"github.com/egeuysall/cove/internal/utils"
+	"github.com/egeuysall/cove/internal/webhooks"
 	"github.com/go-chi/chi/v5"
 	"github.com/jackc/pgx/v5"
+	"io"
 	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
 )
 
+// maxGroupPatchBytes bounds a PATCH body, which only carries a short profile
+const maxGroupPatchBytes = 16 << 10
+
 func HandleCreateGroup(w http.ResponseWriter, r *http.Request) {
 	var req models.Group
 	err := json.NewDecoder(r.Body).Decode(&req)
@@ -21,11 +30,17 @@ func HandleCreateGroup(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	req.Name = strings.TrimSpace(req.Name)
 	if req.Name == "" {
 		utils.SendError(w, "Name is required", http.StatusBadRequest)
 		return
 	}
 
+	if utf8.RuneCountInString(req.Name) > groups.MaxNameLength {
+		utils.SendError(w, "Name is too long", http.StatusBadRequest)
+		return
+	}
+
 	userIdStr, ok := middleware.UserIDFromContext(r.Context())
 
 	if !ok {
@@ -51,19 +66,19 @@ func HandleCreateGroup(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	addParams := supabase.AddUserToGroupParams{
+	addParams := supabase.AddGroupOwnerParams{
 		UserID:  userId,
 		GroupID: group.ID,
 	}
 
-	err = utils.Queries.AddUserToGroup(r.Context(), addParams)
+	err = utils.Queries.AddGroupOwner(r.Context(), addParams)
 
 	if err != nil {
 		utils.SendError(w, "Failed to add user as group member", http.StatusInternalServerError)
 		return
 	}
 
-	utils.SendJson(w, group, http.StatusCreated)
+	utils.SendJson(w, groups.ToResponse(group), http.StatusCreated)
 }
 
 func HandleGetGroupsByUser(w http.ResponseWriter, r *http.Request) {
@@ -81,13 +96,29 @@ func HandleGetGroupsByUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	groups, err := utils.Queries.GetGroupsByUser(r.Context(), userId)
+	version, err := utils.Queries.GetUserGroupsVersion(r.Context(), userId)
 	if err != nil {
 		utils.SendError(w, "Failed to get groups", http.StatusInternalServerError)
 		return
 	}
 
-	utils.SendJson(w, groups, http.StatusOK)
+	etag := utils.WeakETag(userIdStr, version.GroupCount, version.LastJoined.Time.UnixNano(), version.LastUpdated.Time.UnixNano())
+	if utils.CheckNotModified(w, r, etag, time.Time{}) {
+		return
+	}
+
+	memberOf, err := utils.Queries.GetGroupsByUser(r.Context(), userId)
+	if err != nil {
+		utils.SendError(w, "Failed to get groups", http.StatusInternalServerError)
+		return
+	}
+
+	response := make([]models.GroupResponse, len(memberOf))
+	for i, group := range memberOf {
+		response[i] = groups.ToResponse(group)
+	}
+
+	utils.SendJson(w, response, http.StatusOK)
 }
 
 func HandleGetGroupById(w http.ResponseWriter, r *http.Request) {
@@ -105,13 +136,60 @@ func HandleGetGroupById(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	_, ok := middleware.UserIDFromContext(r.Context())
+	userIdStr, ok := middleware.UserIDFromContext(r.Context())
 
 	if !ok {
 		utils.SendError(w, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
 
+	userId, err := utils.ParseUUID(userIdStr)
+
+	if err != nil {
+		utils.SendError(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	var inGroupParams = supabase.IsUserInGroupParams{
+		GroupID: groupId,
+		UserID:  userId,
+	}
+
+	isMember, err := utils.Queries.IsUserInGroup(r.Context(), inGroupParams)
+
+	if err != nil || !isMember {
+		utils.SendError(w, "You are not a member of this group", http.StatusForbidden)
+		return
+	}
+
+	group, err := utils.Queries.GetGroupByID(r.Context(), groupId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.SendError(w, "Group not found", http.StatusNotFound)
+			return
+		}
+
+		utils.SendError(w, "Failed to get group", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SendJson(w, groups.ToResponse(group), http.StatusOK)
+}
+
+// HandleUpdateGroup changes a group's profile and settings. The body is a JSON
+// Merge Patch, so only the fields it names change and null clears one.
+func HandleUpdateGroup(w http.ResponseWriter, r *http.Request) {
+	groupId, _, ok := requireGroupAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	patch, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxGroupPatchBytes))
+	if err != nil {
+		utils.SendError(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
 	group, err := utils.Queries.GetGroupByID(r.Context(), groupId)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -123,7 +201,19 @@ func HandleGetGroupById(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	utils.SendJson(w, group, http.StatusOK)
+	updateParams, err := groups.ApplyPatch(group, patch)
+	if err != nil {
+		utils.SendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	group, err = utils.Queries.UpdateGroup(r.Context(), updateParams)
+	if err != nil {
+		utils.SendError(w, "Failed to update group", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SendJson(w, groups.ToResponse(group), http.StatusOK)
 }
 
 func HandleDeleteGroup(w http.ResponseWriter, r *http.Request) {
@@ -198,14 +288,14 @@ func HandleAddUserToGroup(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var inGroupParams = supabase.IsUserInGroupParams{
+	var accessParams = supabase.GetGroupMemberAccessParams{
 		GroupID: groupId,
 		UserID:  requesterID,
 	}
 
-	isMember, err := utils.Queries.IsUserInGroup(r.Context(), inGroupParams)
+	access, err := utils.Queries.GetGroupMemberAccess(r.Context(), accessParams)
 
-	if err != nil || !isMember {
+	if err != nil || !groups.CanInvite(groups.ParseSettings(access.Settings), access.Role) {
 		utils.SendError(w, "Not authorized to add members", http.StatusForbidden)
 		return
 	}
@@ -226,16 +316,22 @@ func HandleAddUserToGroup(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var addUserParams = supabase.AddUserToGroupParams{
-		UserID:  userId,
-		GroupID: groupId,
-	}
+	err = groups.AddMember(r.Context(), utils.DB, groupId, userId)
 
-	err = utils.Queries.AddUserToGroup(r.Context(), addUserParams)
-
-	if err != nil {
+	switch {
+	case errors.Is(err, groups.ErrFull):
+		utils.SendError(w, "Group is full", http.StatusConflict)
+		return
+	case errors.Is(err, groups.ErrAlreadyMember):
+		// Nobody joined, so there is nothing to announce
+	case err != nil:
 		utils.SendError(w, "Could not add user to group", http.StatusInternalServerError)
 		return
+	default:
+		webhooks.Enqueue(r.Context(), utils.Queries, groupId, webhooks.EventMemberJoined, map[string]string{
+			"user_id":  req.UserId,
+			"added_by": userIdStr,
+		})
 	}
 
 	utils.SendJson(w, "User added successfully", http.StatusOK)
@@ -282,17 +378,38 @@ func HandleGetGroupMembers(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	memberIDs, err := utils.Queries.GetGroupMembers(r.Context(), groupId)
+	version, err := utils.Queries.GetGroupMembersVersion(r.Context(), groupId)
+	if err != nil {
+		utils.SendError(w, "Could not retrieve members", http.StatusInternalServerError)
+		return
+	}
+
+	etag := utils.WeakETag(groupIdStr, version.MemberCount, version.LastJoined.Time.UnixNano(), version.ProfilesUpdated.Time.UnixNano())
+	if utils.CheckNotModified(w, r, etag, time.Time{}) {
+		return
+	}
+
+	members, err := utils.Queries.GetGroupMemberProfiles(r.Context(), groupId)
 
 	if err != nil {
 		utils.SendError(w, "Could not retrieve members", http.StatusInternalServerError)
 		return
 	}
 
-	var memberStrs []string
-	for _, id := range memberIDs {
-		memberStrs = append(memberStrs, id.String())
+	response := make([]models.MemberResponse, len(members))
+	for i, member := range members {
+		response[i] = models.MemberResponse{
+			ProfileResponse: models.ProfileResponse{
+				UserID:      utils.UUIDToString(member.UserID),
+				DisplayName: member.DisplayName.String,
+				AvatarURL:   member.AvatarUrl.String,
+				Bio:         member.Bio.String,
+				Timezone:    member.Timezone.String,
+			},
+			Role:     member.Role,
+			JoinedAt: member.JoinedAt.Time,
+		}
 	}
 
-	utils.SendJson(w, memberStrs, http.StatusOK)
+	utils.SendJson(w, response, http.StatusOK)
 }
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/egeuysall/cove/internal/imgproxy"
+	"github.com/egeuysall/cove/internal/utils"
+	"github.com/go-chi/chi/v5"
+)
+
+// HandleImageProxy serves a signed remote image, optionally resized with
+// ?w=. It needs no login since the signature already limits it to URLs the
+// API handed out.
+func HandleImageProxy(w http.ResponseWriter, r *http.Request) {
+	rawURL, ok := imgproxy.Verify(chi.URLParam(r, "signature"), chi.URLParam(r, "encoded"))
+	if !ok {
+		utils.SendError(w, "Invalid image signature", http.StatusForbidden)
+		return
+	}
+
+	width := 0
+	if widthStr := r.URL.Query().Get("w"); widthStr != "" {
+		var err error
+		width, err = strconv.Atoi(widthStr)
+		if err != nil || !imgproxy.IsValidWidth(width) {
+			utils.SendError(w, "Width must be one of 32, 64, 128, 320, 640 or 1280", http.StatusBadRequest)
+			return
+		}
+	}
+
+	proxy, err := imgproxy.Default()
+	if err != nil {
+		log.Printf("imgproxy: cache unavailable: %v", err)
+		utils.SendError(w, "Image proxy unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	image, err := proxy.Get(r.Context(), rawURL, width)
+	if err != nil {
+		switch {
+		case errors.Is(err, imgproxy.ErrUnsupportedType):
+			utils.SendError(w, "Unsupported image type", http.StatusUnsupportedMediaType)
+		case errors.Is(err, imgproxy.ErrTooLarge):
+			utils.SendError(w, "Image is too large", http.StatusRequestEntityTooLarge)
+		default:
+			utils.SendError(w, "Failed to fetch image", http.StatusBadGateway)
+		}
+		return
+	}
+
+	// The URL is signed and the content derived from it, so browsers and CDNs
+	// can keep it for a week
+	w.Header().Set("Cache-Control", "public, max-age=604800")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; sandbox")
+
+	if utils.CheckNotModified(w, r, image.ETag, time.Time{}) {
+		return
+	}
+
+	w.Header().Set("Content-Type", image.ContentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(image.Data)))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(image.Data)
+}
@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/egeuysall/cove/internal/groups"
+	"github.com/egeuysall/cove/internal/importer"
+	"github.com/egeuysall/cove/internal/models"
+	supabase "github.com/egeuysall/cove/internal/supabase/generated"
+	"github.com/egeuysall/cove/internal/utils"
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	maxImportBytes = 10 << 20
+	// Imports with more rows than this run as a background job
+	maxSyncImportRows = 500
+)
+
+func toImportJobResponse(job supabase.ImportJob) models.ImportJobResponse {
+	response := models.ImportJobResponse{
+		ID:      utils.UUIDToString(job.ID),
+		GroupID: utils.UUIDToString(job.GroupID),
+		Status:  job.Status,
+		Report: models.ImportReport{
+			Format:     job.Format,
+			Total:      int(job.Total),
+			Imported:   int(job.Imported),
+			Duplicates: int(job.Duplicates),
+			Failed:     int(job.Failed),
+			Errors:     []models.ImportRowError{},
+		},
+		CreatedAt: job.CreatedAt.Time,
+	}
+	_ = json.Unmarshal(job.Errors, &response.Report.Errors)
+	if job.FinishedAt.Valid {
+		response.FinishedAt = &job.FinishedAt.Time
+	}
+	return response
+}
+
+// HandleImportLinks accepts a browser bookmarks file, Pocket export or
+// Pinboard JSON as the multipart "file" field. The format is detected unless
+// given in the "format" field.
+func HandleImportLinks(w http.ResponseWriter, r *http.Request) {
+	groupId, userId, ok := memberFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	accessParams := supabase.GetGroupMemberAccessParams{
+		GroupID: groupId,
+		UserID:  userId,
+	}
+
+	access, err := utils.Queries.GetGroupMemberAccess(r.Context(), accessParams)
+	if err != nil {
+		utils.SendError(w, "Error checking group membership", http.StatusInternalServerError)
+		return
+	}
+	if !groups.CanPost(groups.ParseSettings(access.Settings), access.Role) {
+		utils.SendError(w, "Not authorized to post links in this group", http.StatusForbidden)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportBytes)
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		utils.SendError(w, "A file of at most 10MB is required", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	body, err := io.ReadAll(file)
+	if err != nil {
+		utils.SendError(w, "Failed to read file", http.StatusBadRequest)
+		return
+	}
+
+	format := r.FormValue("format")
+	if format == "" {
+		format = importer.Detect(body)
+	}
+
+	items, err := importer.Parse(format, body)
+	if err != nil {
+		if errors.Is(err, importer.ErrUnknownFormat) {
+			utils.SendError(w, "Unsupported format, expected netscape, pocket_csv or pinboard", http.StatusBadRequest)
+			return
+		}
+		utils.SendError(w, "Failed to parse file: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if len(items) <= maxSyncImportRows {
+		report, err := importer.Import(r.Context(), utils.Queries, groupId, userId, format, items)
+		if err != nil {
+			utils.SendError(w, "Failed to import links", http.StatusInternalServerError)
+			return
+		}
+
+		utils.SendJson(w, report, http.StatusOK)
+		return
+	}
+
+	createParams := supabase.CreateImportJobParams{
+		GroupID: groupId,
+		UserID:  userId,
+		Format:  format,
+		Total:   int32(len(items)),
+	}
+
+	job, err := utils.Queries.CreateImportJob(r.Context(), createParams)
+	if err != nil {
+		utils.SendError(w, "Failed to start import", http.StatusInternalServerError)
+		return
+	}
+
+	go runImportJob(job.ID, groupId, userId, format, items)
+
+	utils.SendJson(w, toImportJobResponse(job), http.StatusAccepted)
+}
+
+// runImportJob imports outside the request so it is not cut off by the
+// request timeout, then records the outcome on the job row
+func runImportJob(jobId, groupId, userId pgtype.UUID, format string, items []importer.Item) {
+	ctx := context.Background()
+
+	status := "completed"
+	report, err := importRecovering(ctx, jobId, groupId, userId, format, items)
+	if err != nil {
+		log.Printf("import: job %s failed: %v", utils.UUIDToString(jobId), err)
+		status = "failed"
+		report.Errors = append(report.Errors, models.ImportRowError{Error: "import stopped: " + err.Error()})
+	}
+
+	encoded, err := json.Marshal(report.Errors)
+	if err != nil {
+		encoded = []byte("[]")
+	}
+
+	finishParams := supabase.FinishImportJobParams{
+		Status:     status,
+		Imported:   int32(report.Imported),
+		Duplicates: int32(report.Duplicates),
+		Failed:     int32(report.Failed),
+		Errors:     encoded,
+		ID:         jobId,
+	}
+
+	err = utils.Queries.FinishImportJob(ctx, finishParams)
+	if err != nil {
+		log.Printf("import: failed to record job %s: %v", utils.UUIDToString(jobId), err)
+	}
+}
+
+// importRecovering turns a panic in the import into an error. Nothing else
+// would finish the job, so it would otherwise be left running forever.
+func importRecovering(ctx context.Context, jobId, groupId, userId pgtype.UUID, format string, items []importer.Item) (report models.ImportReport, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			log.Printf("import: job %s panicked: %v\n%s", utils.UUIDToString(jobId), recovered, debug.Stack())
+			err = errors.New("internal error")
+		}
+	}()
+
+	return importer.Import(ctx, utils.Queries, groupId, userId, format, items)
+}
+
+func HandleGetImportJob(w http.ResponseWriter, r *http.Request) {
+	groupId, _, ok := memberFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	jobId, err := utils.ParseUUID(chi.URLParam(r, "jobID"))
+	if err != nil {
+		utils.SendError(w, "Invalid import job ID", http.StatusBadRequest)
+		return
+	}
+
+	getParams := supabase.GetImportJobParams{
+		ID:      jobId,
+		GroupID: groupId,
+	}
+
+	job, err := utils.Queries.GetImportJob(r.Context(), getParams)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.SendError(w, "Import job not found", http.StatusNotFound)
+			return
+		}
+		utils.SendError(w, "Failed to get import job", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SendJson(w, toImportJobResponse(job), http.StatusOK)
+}
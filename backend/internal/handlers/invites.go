@@ -7,12 +7,16 @@ import (
 	"errors"
 	"net/http"
 
+	"github.com/egeuysall/cove/internal/groups"
 	"github.com/egeuysall/cove/internal/middleware"
 	"github.com/egeuysall/cove/internal/models"
+	"github.com/egeuysall/cove/internal/notifications"
 	supabase "github.com/egeuysall/cove/internal/supabase/generated"
 	"github.com/egeuysall/cove/internal/utils"
+	"github.com/egeuysall/cove/internal/webhooks"
 	"github.com/go-chi/chi/v5"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 func HandleCreateInvite(w http.ResponseWriter, r *http.Request) {
@@ -47,17 +51,17 @@ func HandleCreateInvite(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	inGroupParams := supabase.IsUserInGroupParams{
+	accessParams := supabase.GetGroupMemberAccessParams{
 		GroupID: groupId,
 		UserID:  userId,
 	}
 
-	isMember, err := utils.Queries.IsUserInGroup(r.Context(), inGroupParams)
-	if err != nil {
+	access, err := utils.Queries.GetGroupMemberAccess(r.Context(), accessParams)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
 		utils.SendError(w, "Error checking group membership", http.StatusInternalServerError)
 		return
 	}
-	if !isMember {
+	if err != nil || !groups.CanInvite(groups.ParseSettings(access.Settings), access.Role) {
 		utils.SendError(w, "Not authorized to create invites for this group", http.StatusForbidden)
 		return
 	}
@@ -72,8 +76,9 @@ func HandleCreateInvite(w http.ResponseWriter, r *http.Request) {
 	code := base64.RawURLEncoding.EncodeToString(b)[:10]
 
 	createParams := supabase.CreateInviteParams{
-		Code:    code,
-		GroupID: groupId,
+		Code:      code,
+		GroupID:   groupId,
+		CreatedBy: userId,
 	}
 
 	invite, err := utils.Queries.CreateInvite(r.Context(), createParams)
@@ -162,26 +167,26 @@ func HandleAcceptInviteByCode(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	addParams := supabase.AddUserToGroupParams{
-		UserID:  userId,
-		GroupID: invite.GroupID,
-	}
-
-	err = utils.Queries.AddUserToGroup(r.Context(), addParams)
+	invite, err = groups.AcceptInvite(r.Context(), utils.DB, code, userId)
 	if err != nil {
-		utils.SendError(w, "Failed to add user to group", http.StatusInternalServerError)
+		switch {
+		case errors.Is(err, groups.ErrInviteUsed):
+			utils.SendError(w, "Invalid or already used invite", http.StatusNotFound)
+		case errors.Is(err, groups.ErrAlreadyMember):
+			utils.SendError(w, "You are already a member of this group", http.StatusBadRequest)
+		case errors.Is(err, groups.ErrFull):
+			utils.SendError(w, "Group is full", http.StatusConflict)
+		default:
+			utils.SendError(w, "Failed to add user to group", http.StatusInternalServerError)
+		}
 		return
 	}
 
-	markParams := supabase.MarkInviteAsUsedParams{
-		UsedBy: userId,
-		Code:   code,
-	}
-
-	err = utils.Queries.MarkInviteAsUsed(r.Context(), markParams)
-	if err != nil {
-		utils.SendError(w, "Failed to mark invite as used", http.StatusInternalServerError)
-	}
+	webhooks.Enqueue(r.Context(), utils.Queries, invite.GroupID, webhooks.EventMemberJoined, map[string]string{
+		"user_id":     userIdStr,
+		"invite_code": code,
+	})
+	notifications.InviteAccepted(r.Context(), utils.Queries, invite, userId)
 
 	utils.SendJson(w, map[string]string{"message": "Successfully joined group"}, http.StatusOK)
 }
@@ -232,6 +237,14 @@ func HandleGetInvitesByGroup(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	var redeemerIds []pgtype.UUID
+	for _, invite := range invites {
+		if invite.UsedBy.Valid {
+			redeemerIds = append(redeemerIds, invite.UsedBy)
+		}
+	}
+	redeemers := visibleProfiles(r, userId, redeemerIds)
+
 	var response []models.InviteResponse
 	for _, invite := range invites {
 		inviteResponse := models.InviteResponse{
@@ -240,7 +253,9 @@ func HandleGetInvitesByGroup(w http.ResponseWriter, r *http.Request) {
 			CreatedAt: invite.CreatedAt.Time,
 		}
 		if invite.UsedBy.Valid {
+			redeemer := redeemers[invite.UsedBy]
 			inviteResponse.UsedBy = utils.UUIDToString(invite.UsedBy)
+			inviteResponse.Redeemer = &redeemer
 		}
 		response = append(response, inviteResponse)
 	}
@@ -1,23 +1,747 @@
 package handlers
 
-import "net/http"
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/egeuysall/cove/internal/blocklist"
+	"github.com/egeuysall/cove/internal/groups"
+	"github.com/egeuysall/cove/internal/linkcheck"
+	"github.com/egeuysall/cove/internal/links"
+	"github.com/egeuysall/cove/internal/middleware"
+	"github.com/egeuysall/cove/internal/models"
+	supabase "github.com/egeuysall/cove/internal/supabase/generated"
+	"github.com/egeuysall/cove/internal/utils"
+	"github.com/egeuysall/cove/internal/webhooks"
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func toLinkResponse(link supabase.Link) models.LinkResponse {
+	response := models.LinkResponse{
+		ID:          utils.UUIDToString(link.ID),
+		GroupID:     utils.UUIDToString(link.GroupID),
+		UserID:      utils.UUIDToString(link.UserID),
+		URL:         link.Url,
+		FinalURL:    link.FinalUrl.String,
+		Title:       link.Title.String,
+		Comment:     link.Comment.String,
+		Tags:        link.Tags,
+		Status:      link.Status,
+		RedirectURL: link.RedirectUrl.String,
+		CreatedAt:   link.CreatedAt.Time,
+	}
+	if link.StatusCode.Valid {
+		statusCode := int(link.StatusCode.Int32)
+		response.StatusCode = &statusCode
+	}
+	if link.LastCheckedAt.Valid {
+		response.LastCheckedAt = &link.LastCheckedAt.Time
+	}
+	if link.HiddenAt.Valid {
+		response.HiddenAt = &link.HiddenAt.Time
+	}
+	return response
+}
+
+func isValidLinkURL(raw string) bool {
+	parsed, err := url.ParseRequestURI(raw)
+	if err != nil {
+		return false
+	}
+	return (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
 
 func HandleCreateLink(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateLinkRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+
+	if err != nil {
+		utils.SendError(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	if req.GroupID == "" {
+		utils.SendError(w, "Group ID is required", http.StatusBadRequest)
+		return
+	}
+
+	if !isValidLinkURL(req.URL) {
+		utils.SendError(w, "A valid http or https URL is required", http.StatusBadRequest)
+		return
+	}
+
+	userIdStr, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		utils.SendError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	userId, err := utils.ParseUUID(userIdStr)
+	if err != nil {
+		utils.SendError(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	groupId, err := utils.ParseUUID(req.GroupID)
+	if err != nil {
+		utils.SendError(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+
+	accessParams := supabase.GetGroupMemberAccessParams{
+		GroupID: groupId,
+		UserID:  userId,
+	}
+
+	access, err := utils.Queries.GetGroupMemberAccess(r.Context(), accessParams)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		utils.SendError(w, "Error checking group membership", http.StatusInternalServerError)
+		return
+	}
+	if err != nil || !groups.CanPost(groups.ParseSettings(access.Settings), access.Role) {
+		utils.SendError(w, "Not authorized to post links in this group", http.StatusForbidden)
+		return
+	}
 
+	createParams := supabase.CreateLinkParams{
+		GroupID: groupId,
+		UserID:  userId,
+		Url:     req.URL,
+		Title:   utils.TextOrNull(req.Title),
+		Comment: utils.TextOrNull(req.Comment),
+	}
+
+	link, err := links.Create(r.Context(), utils.Queries, createParams)
+	if err != nil {
+		var blocked *links.BlockedError
+		switch {
+		case errors.As(err, &blocked):
+			utils.SendError(w, "Cannot post this link: "+blocked.Error(), http.StatusForbidden)
+		case errors.Is(err, links.ErrRejected):
+			utils.SendError(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, links.ErrDuplicate):
+			utils.SendError(w, "This link was already posted in the group as "+utils.UUIDToString(link.ID), http.StatusConflict)
+		default:
+			utils.SendError(w, "Error creating link", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	response := []models.LinkResponse{toLinkResponse(link)}
+	decorateLinks(r, userId, []supabase.Link{link}, response)
+
+	utils.SendJson(w, response[0], http.StatusCreated)
+}
+
+// HandleCreateLinksBatch posts several links, each to one or more groups, in
+// one request. The request fails only when it is malformed; otherwise every
+// link-group pair gets its own result, and the pairs that pass are stored
+// together.
+func HandleCreateLinksBatch(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateLinksBatchRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+
+	if err != nil {
+		utils.SendError(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	if len(req.Links) == 0 {
+		utils.SendError(w, "At least one link is required", http.StatusBadRequest)
+		return
+	}
+
+	userIdStr, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		utils.SendError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	userId, err := utils.ParseUUID(userIdStr)
+	if err != nil {
+		utils.SendError(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	sharedGroups := make([]pgtype.UUID, 0, len(req.GroupIDs))
+	for _, groupIdStr := range req.GroupIDs {
+		groupId, err := utils.ParseUUID(groupIdStr)
+		if err != nil {
+			utils.SendError(w, "Invalid group ID "+groupIdStr, http.StatusBadRequest)
+			return
+		}
+		sharedGroups = append(sharedGroups, groupId)
+	}
+
+	// Expand every link into one result per target group
+	var results []models.BatchLinkResult
+	var targets []pgtype.UUID
+	for i, item := range req.Links {
+		itemGroups := sharedGroups
+		if item.GroupID != "" {
+			groupId, err := utils.ParseUUID(item.GroupID)
+			if err != nil {
+				utils.SendError(w, fmt.Sprintf("Invalid group ID for link %d", i), http.StatusBadRequest)
+				return
+			}
+			itemGroups = append([]pgtype.UUID{groupId}, sharedGroups...)
+		}
+		if len(itemGroups) == 0 {
+			utils.SendError(w, fmt.Sprintf("Link %d has no group to post to", i), http.StatusBadRequest)
+			return
+		}
+
+		seen := map[pgtype.UUID]bool{}
+		for _, groupId := range itemGroups {
+			if seen[groupId] {
+				continue
+			}
+			seen[groupId] = true
+			results = append(results, models.BatchLinkResult{
+				Index:   i,
+				GroupID: utils.UUIDToString(groupId),
+				URL:     item.URL,
+			})
+			targets = append(targets, groupId)
+		}
+	}
+
+	if len(results) > links.MaxBatchSize {
+		utils.SendError(w, fmt.Sprintf("A batch may post at most %d links, counting each group separately", links.MaxBatchSize), http.StatusBadRequest)
+		return
+	}
+
+	// One query answers membership and posting rights for every group in
+	// the batch
+	memberOf, err := utils.Queries.GetMemberGroupAccess(r.Context(), supabase.GetMemberGroupAccessParams{
+		UserID:   userId,
+		GroupIds: targets,
+	})
+	if err != nil {
+		utils.SendError(w, "Error checking group membership", http.StatusInternalServerError)
+		return
+	}
+	canPost := make(map[pgtype.UUID]bool, len(memberOf))
+	for _, access := range memberOf {
+		canPost[access.GroupID] = groups.CanPost(groups.ParseSettings(access.Settings), access.Role)
+	}
+
+	var batch []supabase.CreateLinkParams
+	var pending []int
+	for i := range results {
+		item := req.Links[results[i].Index]
+		switch {
+		case !isValidLinkURL(item.URL):
+			results[i].Status = "rejected"
+			results[i].Error = "A valid http or https URL is required"
+		case !canPost[targets[i]]:
+			results[i].Status = "forbidden"
+			results[i].Error = "Not authorized to post links in this group"
+		default:
+			batch = append(batch, supabase.CreateLinkParams{
+				GroupID: targets[i],
+				UserID:  userId,
+				Url:     item.URL,
+				Title:   utils.TextOrNull(item.Title),
+				Comment: utils.TextOrNull(item.Comment),
+			})
+			pending = append(pending, i)
+		}
+	}
+
+	var stored []supabase.Link
+	var storedAt []int
+	if len(batch) > 0 {
+		outcomes, err := links.CreateBatch(r.Context(), utils.DB, utils.Queries, batch)
+		if err != nil {
+			log.Printf("links: batch failed: %v", err)
+			utils.SendError(w, "Error creating links", http.StatusInternalServerError)
+			return
+		}
+
+		for j, outcome := range outcomes {
+			i := pending[j]
+			var blocked *links.BlockedError
+			switch {
+			case outcome.Err == nil:
+				results[i].Status = "created"
+			case errors.Is(outcome.Err, links.ErrDuplicate):
+				results[i].Status = "duplicate"
+				results[i].Error = "This link was already posted in the group as " + utils.UUIDToString(outcome.Link.ID)
+			case errors.As(outcome.Err, &blocked):
+				results[i].Status = "blocked"
+				results[i].Error = "Cannot post this link: " + blocked.Error()
+			case errors.Is(outcome.Err, links.ErrRejected):
+				results[i].Status = "rejected"
+				results[i].Error = outcome.Err.Error()
+			default:
+				log.Printf("links: batch item %d failed: %v", i, outcome.Err)
+				results[i].Status = "failed"
+				results[i].Error = "Error creating link"
+			}
+
+			if outcome.Link.ID.Valid {
+				stored = append(stored, outcome.Link)
+				storedAt = append(storedAt, i)
+			}
+		}
+	}
+
+	responses := make([]models.LinkResponse, len(stored))
+	for j, link := range stored {
+		responses[j] = toLinkResponse(link)
+	}
+	decorateLinks(r, userId, stored, responses)
+	for j, i := range storedAt {
+		results[i].Link = &responses[j]
+	}
+
+	response := models.CreateLinksBatchResponse{Results: results}
+	for _, result := range results {
+		if result.Status == "created" {
+			response.Created++
+		} else {
+			response.Failed++
+		}
+	}
+
+	utils.SendJson(w, response, http.StatusOK)
+}
+
+// hiddenLinkVisible reports whether userId may see a link in groupId posted
+// by posterId: hidden links stay visible to their poster and the group's
+// moderators. Links that are not hidden are always visible.
+func hiddenLinkVisible(ctx context.Context, groupId, posterId pgtype.UUID, hiddenAt pgtype.Timestamptz, userId pgtype.UUID) (bool, error) {
+	if !hiddenAt.Valid {
+		return true, nil
+	}
+	if !userId.Valid {
+		return false, nil
+	}
+	if posterId == userId {
+		return true, nil
+	}
+
+	adminParams := supabase.IsGroupAdminParams{
+		GroupID: groupId,
+		UserID:  userId,
+	}
+
+	return utils.Queries.IsGroupAdmin(ctx, adminParams)
+}
+
+// requireLinkVisible checks that userId is in the link's group and, if the
+// link is hidden, may still see it. It answers 404 rather than 403 so the
+// link's existence is not revealed.
+func requireLinkVisible(w http.ResponseWriter, r *http.Request, link supabase.Link, userId pgtype.UUID) bool {
+	inGroupParams := supabase.IsUserInGroupParams{
+		GroupID: link.GroupID,
+		UserID:  userId,
+	}
+
+	isMember, err := utils.Queries.IsUserInGroup(r.Context(), inGroupParams)
+	if err != nil {
+		utils.SendError(w, "Error checking group membership", http.StatusInternalServerError)
+		return false
+	}
+	if !isMember {
+		utils.SendError(w, "Link not found", http.StatusNotFound)
+		return false
+	}
+
+	visible, err := hiddenLinkVisible(r.Context(), link.GroupID, link.UserID, link.HiddenAt, userId)
+	if err != nil {
+		utils.SendError(w, "Error checking group role", http.StatusInternalServerError)
+		return false
+	}
+	if !visible {
+		utils.SendError(w, "Link not found", http.StatusNotFound)
+		return false
+	}
+
+	return true
+}
+
+// requireLinkAllowed answers 410 for a link whose domain has been blocked
+// since it was posted, so it cannot be opened by id or short URL either
+func requireLinkAllowed(w http.ResponseWriter, r *http.Request, groupId pgtype.UUID, url string, finalUrl pgtype.Text) bool {
+	err := links.Blocked(r.Context(), utils.Queries, groupId, url, finalUrl)
+	if err == nil {
+		return true
+	}
+
+	var blocked *links.BlockedError
+	if errors.As(err, &blocked) {
+		utils.SendError(w, blocked.Error(), http.StatusGone)
+		return false
+	}
+	utils.SendError(w, "Error checking blocklists", http.StatusInternalServerError)
+	return false
 }
 
 func HandleGetLinkById(w http.ResponseWriter, r *http.Request) {
+	linkIdStr := chi.URLParam(r, "id")
+	if linkIdStr == "" {
+		utils.SendError(w, "Missing id parameter", http.StatusBadRequest)
+		return
+	}
+
+	linkId, err := utils.ParseUUID(linkIdStr)
+	if err != nil {
+		utils.SendError(w, "Invalid link ID", http.StatusBadRequest)
+		return
+	}
+
+	userIdStr, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		utils.SendError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	userId, err := utils.ParseUUID(userIdStr)
+	if err != nil {
+		utils.SendError(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
 
+	link, err := utils.Queries.GetLinkByID(r.Context(), linkId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.SendError(w, "Link not found", http.StatusNotFound)
+			return
+		}
+		utils.SendError(w, "Failed to get link", http.StatusInternalServerError)
+		return
+	}
+
+	if !requireLinkVisible(w, r, link, userId) {
+		return
+	}
+
+	if !requireLinkAllowed(w, r, link.GroupID, link.Url, link.FinalUrl) {
+		return
+	}
+
+	response := []models.LinkResponse{toLinkResponse(link)}
+	decorateLinks(r, userId, []supabase.Link{link}, response)
+
+	utils.SendJson(w, response[0], http.StatusOK)
 }
 
 func HandleGetLinksByGroup(w http.ResponseWriter, r *http.Request) {
+	groupIdStr := chi.URLParam(r, "groupID")
+	if groupIdStr == "" {
+		utils.SendError(w, "Missing groupID parameter", http.StatusBadRequest)
+		return
+	}
+
+	groupId, err := utils.ParseUUID(groupIdStr)
+	if err != nil {
+		utils.SendError(w, "Invalid group ID format", http.StatusBadRequest)
+		return
+	}
+
+	userIdStr, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		utils.SendError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	userId, err := utils.ParseUUID(userIdStr)
+	if err != nil {
+		utils.SendError(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	inGroupParams := supabase.IsUserInGroupParams{
+		GroupID: groupId,
+		UserID:  userId,
+	}
+
+	isMember, err := utils.Queries.IsUserInGroup(r.Context(), inGroupParams)
+	if err != nil {
+		utils.SendError(w, "Error checking group membership", http.StatusInternalServerError)
+		return
+	}
+	if !isMember {
+		utils.SendError(w, "You are not a member of this group", http.StatusForbidden)
+		return
+	}
+
+	limit, err := utils.ParseLimit(r, 50, 100)
+	if err != nil {
+		utils.SendError(w, "Invalid limit", http.StatusBadRequest)
+		return
+	}
+
+	offset := 0
+	if offsetStr := r.URL.Query().Get("offset"); offsetStr != "" {
+		offset, err = strconv.Atoi(offsetStr)
+		if err != nil || offset < 0 {
+			utils.SendError(w, "Invalid offset", http.StatusBadRequest)
+			return
+		}
+	}
+
+	status := r.URL.Query().Get("status")
+	if status != "" && !linkcheck.IsValidStatus(status) {
+		utils.SendError(w, "Status must be unchecked, ok, failing or dead", http.StatusBadRequest)
+		return
+	}
 
+	adminParams := supabase.IsGroupAdminParams{
+		GroupID: groupId,
+		UserID:  userId,
+	}
+
+	isAdmin, err := utils.Queries.IsGroupAdmin(r.Context(), adminParams)
+	if err != nil {
+		utils.SendError(w, "Error checking group role", http.StatusInternalServerError)
+		return
+	}
+
+	listParams := supabase.GetLinksByGroupParams{
+		GroupID:    groupId,
+		Status:     utils.TextOrNull(status),
+		Query:      utils.TextOrNull(strings.TrimSpace(r.URL.Query().Get("q"))),
+		ViewerID:   userId,
+		ShowHidden: isAdmin,
+		Limit:      limit,
+		Offset:     int32(offset),
+	}
+
+	// Open counts are left out of the validator; they catch up on the next
+	// change to the group's links
+	version, err := utils.Queries.GetGroupLinksVersion(r.Context(), groupId)
+	if err != nil {
+		utils.SendError(w, "Failed to get links", http.StatusInternalServerError)
+		return
+	}
+
+	etag := utils.WeakETag(
+		version.LinkCount, version.LastUpdated.Time.UnixNano(),
+		version.BlockedCount, version.BlockedUpdated.Time.UnixNano(),
+		version.ProfilesUpdated.Time.UnixNano(),
+		blocklist.Current().Version(), userIdStr, isAdmin, r.URL.RawQuery,
+	)
+	if utils.CheckNotModified(w, r, etag, time.Time{}) {
+		return
+	}
+
+	links, err := utils.Queries.GetLinksByGroup(r.Context(), listParams)
+	if err != nil {
+		utils.SendError(w, "Failed to get links", http.StatusInternalServerError)
+		return
+	}
+
+	// Group blocks are applied by the query; the instance list lives in
+	// memory, so a page can come back short when it hides something
+	links = blocklist.FilterLinks(links)
+
+	response := make([]models.LinkResponse, 0, len(links))
+	for _, link := range links {
+		response = append(response, toLinkResponse(link))
+	}
+	decorateLinks(r, userId, links, response)
+
+	utils.SendJson(w, response, http.StatusOK)
 }
 
 func HandleUpdateLinkComment(w http.ResponseWriter, r *http.Request) {
+	linkIdStr := chi.URLParam(r, "id")
+	if linkIdStr == "" {
+		utils.SendError(w, "Missing id parameter", http.StatusBadRequest)
+		return
+	}
+
+	linkId, err := utils.ParseUUID(linkIdStr)
+	if err != nil {
+		utils.SendError(w, "Invalid link ID", http.StatusBadRequest)
+		return
+	}
+
+	var req models.UpdateLinkCommentRequest
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		utils.SendError(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	userIdStr, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		utils.SendError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	userId, err := utils.ParseUUID(userIdStr)
+	if err != nil {
+		utils.SendError(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
 
+	link, err := utils.Queries.GetLinkByID(r.Context(), linkId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.SendError(w, "Link not found", http.StatusNotFound)
+			return
+		}
+		utils.SendError(w, "Failed to get link", http.StatusInternalServerError)
+		return
+	}
+
+	if link.UserID != userId {
+		utils.SendError(w, "Only the poster can edit this link", http.StatusForbidden)
+		return
+	}
+
+	updateParams := supabase.UpdateLinkCommentParams{
+		Comment: utils.TextOrNull(req.Comment),
+		ID:      linkId,
+		UserID:  userId,
+	}
+
+	err = utils.Queries.UpdateLinkComment(r.Context(), updateParams)
+	if err != nil {
+		utils.SendError(w, "Failed to update link", http.StatusInternalServerError)
+		return
+	}
+
+	link.Comment = updateParams.Comment
+	utils.SendJson(w, toLinkResponse(link), http.StatusOK)
 }
 
 func HandleDeleteLink(w http.ResponseWriter, r *http.Request) {
-	
+	linkIdStr := chi.URLParam(r, "id")
+	if linkIdStr == "" {
+		utils.SendError(w, "Missing id parameter", http.StatusBadRequest)
+		return
+	}
+
+	linkId, err := utils.ParseUUID(linkIdStr)
+	if err != nil {
+		utils.SendError(w, "Invalid link ID", http.StatusBadRequest)
+		return
+	}
+
+	userIdStr, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		utils.SendError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	userId, err := utils.ParseUUID(userIdStr)
+	if err != nil {
+		utils.SendError(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	link, err := utils.Queries.GetLinkByID(r.Context(), linkId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.SendError(w, "Link not found", http.StatusNotFound)
+			return
+		}
+		utils.SendError(w, "Failed to get link", http.StatusInternalServerError)
+		return
+	}
+
+	if link.UserID != userId {
+		utils.SendError(w, "Only the poster can delete this link", http.StatusForbidden)
+		return
+	}
+
+	deleteParams := supabase.DeleteLinkParams{
+		ID:     linkId,
+		UserID: userId,
+	}
+
+	err = utils.Queries.DeleteLink(r.Context(), deleteParams)
+	if err != nil {
+		utils.SendError(w, "Failed to delete link", http.StatusInternalServerError)
+		return
+	}
+
+	webhooks.Enqueue(r.Context(), utils.Queries, link.GroupID, webhooks.EventLinkDeleted, toLinkResponse(link))
+
+	utils.SendJson(w, "Link deleted", http.StatusOK)
+}
+
+func HandleGetLinkSnapshot(w http.ResponseWriter, r *http.Request) {
+	linkIdStr := chi.URLParam(r, "id")
+	if linkIdStr == "" {
+		utils.SendError(w, "Missing id parameter", http.StatusBadRequest)
+		return
+	}
+
+	linkId, err := utils.ParseUUID(linkIdStr)
+	if err != nil {
+		utils.SendError(w, "Invalid link ID", http.StatusBadRequest)
+		return
+	}
+
+	userIdStr, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		utils.SendError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	userId, err := utils.ParseUUID(userIdStr)
+	if err != nil {
+		utils.SendError(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	link, err := utils.Queries.GetLinkByID(r.Context(), linkId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.SendError(w, "Link not found", http.StatusNotFound)
+			return
+		}
+		utils.SendError(w, "Failed to get link", http.StatusInternalServerError)
+		return
+	}
+
+	if !requireLinkVisible(w, r, link, userId) {
+		return
+	}
+
+	if !requireLinkAllowed(w, r, link.GroupID, link.Url, link.FinalUrl) {
+		return
+	}
+
+	snapshot, err := utils.Queries.GetSnapshot(r.Context(), linkId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.SendError(w, "No snapshot for this link", http.StatusNotFound)
+			return
+		}
+		utils.SendError(w, "Failed to get snapshot", http.StatusInternalServerError)
+		return
+	}
+
+	response := models.SnapshotResponse{
+		LinkID:         utils.UUIDToString(snapshot.LinkID),
+		Status:         snapshot.Status,
+		Title:          snapshot.Title.String,
+		HTML:           snapshot.ContentHtml,
+		Text:           snapshot.TextContent,
+		WordCount:      int(snapshot.WordCount),
+		ReadingMinutes: int(snapshot.ReadingMinutes),
+		Error:          snapshot.LastError.String,
+	}
+	if snapshot.FetchedAt.Valid {
+		response.FetchedAt = &snapshot.FetchedAt.Time
+	}
+
+	utils.SendJson(w, response, http.StatusOK)
 }
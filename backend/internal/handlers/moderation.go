@@ -0,0 +1,274 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/egeuysall/cove/internal/middleware"
+	"github.com/egeuysall/cove/internal/models"
+	"github.com/egeuysall/cove/internal/moderation"
+	supabase "github.com/egeuysall/cove/internal/supabase/generated"
+	"github.com/egeuysall/cove/internal/utils"
+	"github.com/egeuysall/cove/internal/webhooks"
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+)
+
+func toModerationActionResponse(action supabase.ModerationAction) models.ModerationActionResponse {
+	return models.ModerationActionResponse{
+		ID:              utils.UUIDToString(action.ID),
+		LinkID:          utils.UUIDToString(action.LinkID),
+		LinkURL:         action.LinkUrl,
+		ModeratorID:     utils.UUIDToString(action.ModeratorID),
+		Action:          action.Action,
+		Note:            action.Note.String,
+		ReportsResolved: int(action.ReportsResolved),
+		CreatedAt:       action.CreatedAt.Time,
+	}
+}
+
+// parseOffset reads the "offset" query parameter, defaulting to 0
+func parseOffset(r *http.Request) (int32, error) {
+	raw := r.URL.Query().Get("offset")
+	if raw == "" {
+		return 0, nil
+	}
+
+	offset, err := strconv.ParseInt(raw, 10, 32)
+	if err != nil || offset < 0 {
+		return 0, errors.New("invalid offset")
+	}
+	return int32(offset), nil
+}
+
+func HandleReportLink(w http.ResponseWriter, r *http.Request) {
+	linkId, err := utils.ParseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.SendError(w, "Invalid link ID", http.StatusBadRequest)
+		return
+	}
+
+	userIdStr, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		utils.SendError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	userId, err := utils.ParseUUID(userIdStr)
+	if err != nil {
+		utils.SendError(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	var req models.ReportLinkRequest
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		utils.SendError(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" || utf8.RuneCountInString(reason) > moderation.MaxReasonLength {
+		utils.SendError(w, "A reason of at most 500 characters is required", http.StatusBadRequest)
+		return
+	}
+
+	link, err := utils.Queries.GetLinkByID(r.Context(), linkId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.SendError(w, "Link not found", http.StatusNotFound)
+			return
+		}
+		utils.SendError(w, "Failed to get link", http.StatusInternalServerError)
+		return
+	}
+
+	inGroupParams := supabase.IsUserInGroupParams{
+		GroupID: link.GroupID,
+		UserID:  userId,
+	}
+
+	isMember, err := utils.Queries.IsUserInGroup(r.Context(), inGroupParams)
+	if err != nil {
+		utils.SendError(w, "Error checking group membership", http.StatusInternalServerError)
+		return
+	}
+	if !isMember {
+		utils.SendError(w, "Link not found", http.StatusNotFound)
+		return
+	}
+
+	if link.UserID == userId {
+		utils.SendError(w, "You cannot report your own link", http.StatusBadRequest)
+		return
+	}
+
+	reportParams := supabase.CreateLinkReportParams{
+		GroupID:    link.GroupID,
+		LinkID:     link.ID,
+		ReporterID: userId,
+		Reason:     reason,
+	}
+
+	report, err := utils.Queries.CreateLinkReport(r.Context(), reportParams)
+	if err != nil {
+		utils.SendError(w, "Error reporting link", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SendJson(w, models.LinkReportResponse{
+		ID:        utils.UUIDToString(report.ID),
+		LinkID:    utils.UUIDToString(report.LinkID),
+		Reason:    report.Reason,
+		Status:    report.Status,
+		CreatedAt: report.CreatedAt.Time,
+	}, http.StatusCreated)
+}
+
+func HandleGetModerationQueue(w http.ResponseWriter, r *http.Request) {
+	groupId, _, ok := requireGroupAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	limit, err := utils.ParseLimit(r, 50, 100)
+	if err != nil {
+		utils.SendError(w, "Invalid limit", http.StatusBadRequest)
+		return
+	}
+
+	offset, err := parseOffset(r)
+	if err != nil {
+		utils.SendError(w, "Invalid offset", http.StatusBadRequest)
+		return
+	}
+
+	queueParams := supabase.GetModerationQueueParams{
+		GroupID: groupId,
+		Limit:   limit,
+		Offset:  offset,
+	}
+
+	rows, err := utils.Queries.GetModerationQueue(r.Context(), queueParams)
+	if err != nil {
+		utils.SendError(w, "Failed to get moderation queue", http.StatusInternalServerError)
+		return
+	}
+
+	response := make([]models.ModerationQueueItem, 0, len(rows))
+	for _, row := range rows {
+		link := toLinkResponse(supabase.Link{
+			ID:        row.ID,
+			GroupID:   groupId,
+			UserID:    row.UserID,
+			Url:       row.Url,
+			Title:     row.Title,
+			Comment:   row.Comment,
+			Status:    row.Status,
+			CreatedAt: row.CreatedAt,
+			HiddenAt:  row.HiddenAt,
+		})
+
+		response = append(response, models.ModerationQueueItem{
+			Link:            link,
+			ReportCount:     int(row.ReportCount),
+			Reasons:         row.Reasons,
+			FirstReportedAt: row.FirstReportedAt.Time,
+		})
+	}
+
+	utils.SendJson(w, response, http.StatusOK)
+}
+
+func HandleModerateLink(w http.ResponseWriter, r *http.Request) {
+	groupId, userId, ok := requireGroupAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	linkId, err := utils.ParseUUID(chi.URLParam(r, "linkID"))
+	if err != nil {
+		utils.SendError(w, "Invalid link ID", http.StatusBadRequest)
+		return
+	}
+
+	var req models.ModerateLinkRequest
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		utils.SendError(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	if !moderation.IsValidAction(req.Action) {
+		utils.SendError(w, "Action must be hide, unhide, delete or dismiss", http.StatusBadRequest)
+		return
+	}
+
+	link, err := utils.Queries.GetLinkByID(r.Context(), linkId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.SendError(w, "Link not found", http.StatusNotFound)
+			return
+		}
+		utils.SendError(w, "Failed to get link", http.StatusInternalServerError)
+		return
+	}
+	if link.GroupID != groupId {
+		utils.SendError(w, "Link not found", http.StatusNotFound)
+		return
+	}
+
+	action, err := moderation.Apply(r.Context(), utils.DB, link, userId, req.Action, utils.TextOrNull(strings.TrimSpace(req.Note)))
+	if err != nil {
+		utils.SendError(w, "Failed to moderate link", http.StatusInternalServerError)
+		return
+	}
+
+	if req.Action == moderation.ActionDelete {
+		webhooks.Enqueue(r.Context(), utils.Queries, link.GroupID, webhooks.EventLinkDeleted, toLinkResponse(link))
+	}
+
+	utils.SendJson(w, toModerationActionResponse(action), http.StatusOK)
+}
+
+func HandleGetModerationLog(w http.ResponseWriter, r *http.Request) {
+	groupId, _, ok := requireGroupAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	limit, err := utils.ParseLimit(r, 50, 200)
+	if err != nil {
+		utils.SendError(w, "Invalid limit", http.StatusBadRequest)
+		return
+	}
+
+	offset, err := parseOffset(r)
+	if err != nil {
+		utils.SendError(w, "Invalid offset", http.StatusBadRequest)
+		return
+	}
+
+	listParams := supabase.ListModerationActionsParams{
+		GroupID: groupId,
+		Limit:   limit,
+		Offset:  offset,
+	}
+
+	actions, err := utils.Queries.ListModerationActions(r.Context(), listParams)
+	if err != nil {
+		utils.SendError(w, "Failed to get moderation log", http.StatusInternalServerError)
+		return
+	}
+
+	response := make([]models.ModerationActionResponse, 0, len(actions))
+	for _, action := range actions {
+		response = append(response, toModerationActionResponse(action))
+	}
+
+	utils.SendJson(w, response, http.StatusOK)
+}
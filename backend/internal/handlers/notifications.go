@@ -0,0 +1,304 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/egeuysall/cove/internal/middleware"
+	"github.com/egeuysall/cove/internal/models"
+	"github.com/egeuysall/cove/internal/notifications"
+	supabase "github.com/egeuysall/cove/internal/supabase/generated"
+	"github.com/egeuysall/cove/internal/utils"
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// streamHeartbeat is how often an idle notification stream sends a comment,
+// so proxies and load balancers do not close it
+const streamHeartbeat = 25 * time.Second
+
+func HandleGetNotifications(w http.ResponseWriter, r *http.Request) {
+	userIdStr, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		utils.SendError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	userId, err := utils.ParseUUID(userIdStr)
+	if err != nil {
+		utils.SendError(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	query := r.URL.Query()
+
+	listParams := supabase.ListNotificationsParams{
+		UserID: userId,
+	}
+
+	switch query.Get("unread") {
+	case "", "false":
+	case "true":
+		listParams.UnreadOnly = true
+	default:
+		utils.SendError(w, "Unread must be true or false", http.StatusBadRequest)
+		return
+	}
+
+	limit, err := utils.ParseLimit(r, 20, 100)
+	if err != nil {
+		utils.SendError(w, "Invalid limit", http.StatusBadRequest)
+		return
+	}
+	listParams.RowLimit = limit
+
+	listParams.CursorCreatedAt, listParams.CursorID, err = utils.DecodeCursor(query.Get("cursor"))
+	if err != nil {
+		utils.SendError(w, "Invalid cursor", http.StatusBadRequest)
+		return
+	}
+
+	notifications, err := utils.Queries.ListNotifications(r.Context(), listParams)
+	if err != nil {
+		utils.SendError(w, "Failed to get notifications", http.StatusInternalServerError)
+		return
+	}
+
+	unread, err := utils.Queries.CountUnreadNotifications(r.Context(), userId)
+	if err != nil {
+		utils.SendError(w, "Failed to get notifications", http.StatusInternalServerError)
+		return
+	}
+
+	var actorIds []pgtype.UUID
+	seen := map[pgtype.UUID]bool{}
+	for _, notification := range notifications {
+		if notification.ActorID.Valid && !seen[notification.ActorID] {
+			seen[notification.ActorID] = true
+			actorIds = append(actorIds, notification.ActorID)
+		}
+	}
+	actors := visibleProfiles(r, userId, actorIds)
+
+	items := make([]models.NotificationResponse, 0, len(notifications))
+	for _, notification := range notifications {
+		items = append(items, toNotificationResponse(notification, actors))
+	}
+
+	response := models.NotificationsPageResponse{Items: items, UnreadCount: unread}
+	if len(notifications) == int(limit) {
+		last := notifications[len(notifications)-1]
+		response.NextCursor = utils.EncodeCursor(last.CreatedAt.Time, last.ID)
+	}
+
+	utils.SendJson(w, response, http.StatusOK)
+}
+
+func toNotificationResponse(notification supabase.ListNotificationsRow, actors map[pgtype.UUID]models.ProfileResponse) models.NotificationResponse {
+	response := models.NotificationResponse{
+		ID:        utils.UUIDToString(notification.ID),
+		Kind:      notification.Kind,
+		GroupID:   utils.UUIDToString(notification.GroupID),
+		GroupName: notification.GroupName.String,
+		LinkID:    utils.UUIDToString(notification.LinkID),
+		LinkURL:   notification.LinkUrl.String,
+		LinkTitle: notification.LinkTitle.String,
+		CreatedAt: notification.CreatedAt.Time,
+	}
+	if actor, ok := actors[notification.ActorID]; ok {
+		response.Actor = &actor
+	}
+	if notification.ReadAt.Valid {
+		response.ReadAt = &notification.ReadAt.Time
+	}
+	return response
+}
+
+// HandleNotificationStream pushes the caller's new notifications as
+// server-sent events for as long as they stay connected. Each one is a
+// "notification" event holding the same object the inbox lists. A client
+// that is cut off, including for falling behind, should reconnect and read
+// the inbox for anything it missed.
+func HandleNotificationStream(w http.ResponseWriter, r *http.Request) {
+	userIdStr, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		utils.SendError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	userId, err := utils.ParseUUID(userIdStr)
+	if err != nil {
+		utils.SendError(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	stream, closeStream := notifications.Subscribe(userId)
+	defer closeStream()
+
+	controller := http.NewResponseController(w)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	// Keeps reverse proxies such as nginx from holding events back
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	// Sends the headers now so the client knows it is connected
+	if _, err := io.WriteString(w, ": connected\n\n"); err != nil || controller.Flush() != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+
+		case <-heartbeat.C:
+			if _, err := io.WriteString(w, ": heartbeat\n\n"); err != nil || controller.Flush() != nil {
+				return
+			}
+
+		case created, ok := <-stream:
+			if !ok {
+				return
+			}
+
+			notification, err := utils.Queries.GetNotification(r.Context(), supabase.GetNotificationParams{
+				ID:     created.ID,
+				UserID: userId,
+			})
+			if err != nil {
+				// Read or pruned already, or the database is struggling;
+				// the inbox will have it either way
+				log.Printf("notifications: failed to load %s for stream: %v", utils.UUIDToString(created.ID), err)
+				continue
+			}
+
+			actors := visibleProfiles(r, userId, []pgtype.UUID{notification.ActorID})
+			data, err := json.Marshal(toNotificationResponse(supabase.ListNotificationsRow(notification), actors))
+			if err != nil {
+				return
+			}
+
+			_, err = fmt.Fprintf(w, "id: %s\nevent: notification\ndata: %s\n\n", utils.UUIDToString(notification.ID), data)
+			if err != nil || controller.Flush() != nil {
+				return
+			}
+		}
+	}
+}
+
+func HandleMarkNotificationRead(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	if idStr == "" {
+		utils.SendError(w, "Missing id parameter", http.StatusBadRequest)
+		return
+	}
+
+	id, err := utils.ParseUUID(idStr)
+	if err != nil {
+		utils.SendError(w, "Invalid notification ID format", http.StatusBadRequest)
+		return
+	}
+
+	userIdStr, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		utils.SendError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	userId, err := utils.ParseUUID(userIdStr)
+	if err != nil {
+		utils.SendError(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	markParams := supabase.MarkNotificationReadParams{
+		ID:     id,
+		UserID: userId,
+	}
+
+	_, err = utils.Queries.MarkNotificationRead(r.Context(), markParams)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.SendError(w, "Notification not found", http.StatusNotFound)
+			return
+		}
+		utils.SendError(w, "Failed to mark notification read", http.StatusInternalServerError)
+		return
+	}
+
+	unread, err := utils.Queries.CountUnreadNotifications(r.Context(), userId)
+	if err != nil {
+		utils.SendError(w, "Failed to count notifications", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SendJson(w, models.MarkNotificationsReadResponse{Marked: 1, UnreadCount: unread}, http.StatusOK)
+}
+
+func HandleMarkAllNotificationsRead(w http.ResponseWriter, r *http.Request) {
+	userIdStr, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		utils.SendError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	userId, err := utils.ParseUUID(userIdStr)
+	if err != nil {
+		utils.SendError(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	marked, err := utils.Queries.MarkAllNotificationsRead(r.Context(), userId)
+	if err != nil {
+		utils.SendError(w, "Failed to mark notifications read", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SendJson(w, models.MarkNotificationsReadResponse{Marked: marked}, http.StatusOK)
+}
+
+// HandleMuteGroup stops new_link notifications from a group for the caller.
+// Mentions still come through.
+func HandleMuteGroup(w http.ResponseWriter, r *http.Request) {
+	setGroupMuted(w, r, true)
+}
+
+func HandleUnmuteGroup(w http.ResponseWriter, r *http.Request) {
+	setGroupMuted(w, r, false)
+}
+
+func setGroupMuted(w http.ResponseWriter, r *http.Request, muted bool) {
+	groupId, userId, ok := memberFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	muteParams := supabase.SetGroupMutedParams{
+		Muted:   muted,
+		GroupID: groupId,
+		UserID:  userId,
+	}
+
+	_, err := utils.Queries.SetGroupMuted(r.Context(), muteParams)
+	if err != nil {
+		utils.SendError(w, "Failed to update group notifications", http.StatusInternalServerError)
+		return
+	}
+
+	response := models.GroupMuteResponse{
+		GroupID: utils.UUIDToString(groupId),
+		Muted:   muted,
+	}
+
+	utils.SendJson(w, response, http.StatusOK)
+}
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/egeuysall/cove/internal/middleware"
+	"github.com/egeuysall/cove/internal/models"
+	"github.com/egeuysall/cove/internal/profiles"
+	supabase "github.com/egeuysall/cove/internal/supabase/generated"
+	"github.com/egeuysall/cove/internal/utils"
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// maxProfilePatchBytes bounds a PATCH /me body
+const maxProfilePatchBytes = 16 << 10
+
+// getProfile loads a user's profile. Users who never saved one get an empty
+// profile rather than an error.
+func getProfile(r *http.Request, userId pgtype.UUID) (supabase.Profile, error) {
+	profile, err := utils.Queries.GetProfile(r.Context(), userId)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return supabase.Profile{UserID: userId}, nil
+	}
+	return profile, err
+}
+
+func HandleGetMe(w http.ResponseWriter, r *http.Request) {
+	userIdStr, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		utils.SendError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	userId, err := utils.ParseUUID(userIdStr)
+	if err != nil {
+		utils.SendError(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	profile, err := getProfile(r, userId)
+	if err != nil {
+		utils.SendError(w, "Failed to get profile", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SendJson(w, profiles.ToResponse(profile), http.StatusOK)
+}
+
+// HandleUpdateMe changes the caller's profile. The body is a JSON Merge
+// Patch, so only the fields it names change and null clears one.
+func HandleUpdateMe(w http.ResponseWriter, r *http.Request) {
+	userIdStr, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		utils.SendError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	userId, err := utils.ParseUUID(userIdStr)
+	if err != nil {
+		utils.SendError(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	patch, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxProfilePatchBytes))
+	if err != nil {
+		utils.SendError(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	profile, err := getProfile(r, userId)
+	if err != nil {
+		utils.SendError(w, "Failed to get profile", http.StatusInternalServerError)
+		return
+	}
+
+	upsertParams, err := profiles.ApplyPatch(profile, patch)
+	if err != nil {
+		utils.SendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	profile, err = utils.Queries.UpsertProfile(r.Context(), upsertParams)
+	if err != nil {
+		utils.SendError(w, "Failed to update profile", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SendJson(w, profiles.ToResponse(profile), http.StatusOK)
+}
+
+// HandleGetUser returns another user's profile. Users who share no group
+// with the caller are reported as not found, so the endpoint cannot be used
+// to tell whether an account exists.
+func HandleGetUser(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	if idStr == "" {
+		utils.SendError(w, "Missing id parameter", http.StatusBadRequest)
+		return
+	}
+
+	id, err := utils.ParseUUID(idStr)
+	if err != nil {
+		utils.SendError(w, "Invalid user ID format", http.StatusBadRequest)
+		return
+	}
+
+	userIdStr, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		utils.SendError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	userId, err := utils.ParseUUID(userIdStr)
+	if err != nil {
+		utils.SendError(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	if id != userId {
+		sharesParams := supabase.SharesGroupParams{
+			UserID:      userId,
+			OtherUserID: id,
+		}
+
+		shares, err := utils.Queries.SharesGroup(r.Context(), sharesParams)
+		if err != nil {
+			utils.SendError(w, "Failed to get profile", http.StatusInternalServerError)
+			return
+		}
+		if !shares {
+			utils.SendError(w, "User not found", http.StatusNotFound)
+			return
+		}
+	}
+
+	profile, err := getProfile(r, id)
+	if err != nil {
+		utils.SendError(w, "Failed to get profile", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SendJson(w, profiles.ToResponse(profile), http.StatusOK)
+}
+
+// visibleProfiles is profiles.Visible for a handler. Profiles are best
+// effort: on error every user is left with just their ID.
+func visibleProfiles(r *http.Request, viewer pgtype.UUID, userIds []pgtype.UUID) map[pgtype.UUID]models.ProfileResponse {
+	found, err := profiles.Visible(r.Context(), utils.Queries, viewer, userIds)
+	if err != nil {
+		log.Printf("profiles: failed to look up profiles: %v", err)
+	}
+	return found
+}
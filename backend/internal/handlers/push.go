@@ -0,0 +1,248 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/egeuysall/cove/internal/middleware"
+	"github.com/egeuysall/cove/internal/models"
+	supabase "github.com/egeuysall/cove/internal/supabase/generated"
+	"github.com/egeuysall/cove/internal/utils"
+	"github.com/egeuysall/cove/internal/webpush"
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// maxUserAgentLength bounds the user agent stored to label a subscription
+const maxUserAgentLength = 512
+
+func toPushSubscriptionResponse(subscription supabase.PushSubscription) models.PushSubscriptionResponse {
+	return models.PushSubscriptionResponse{
+		ID:        utils.UUIDToString(subscription.ID),
+		Endpoint:  subscription.Endpoint,
+		UserAgent: subscription.UserAgent.String,
+		CreatedAt: subscription.CreatedAt.Time,
+	}
+}
+
+// pushConfig loads the VAPID settings, answering 503 when push is off
+func pushConfig(w http.ResponseWriter) (webpush.Config, bool) {
+	cfg, ok, err := webpush.ConfigFromEnv()
+	if err != nil || !ok {
+		utils.SendError(w, "Push notifications are not configured", http.StatusServiceUnavailable)
+		return cfg, false
+	}
+	return cfg, true
+}
+
+// pushSubscriptionFromRequest loads the caller's subscription named by the
+// {id} URL parameter, writing the error response itself when it cannot
+func pushSubscriptionFromRequest(w http.ResponseWriter, r *http.Request) (supabase.PushSubscription, bool) {
+	var subscription supabase.PushSubscription
+
+	id, userId, ok := pushSubscriptionIDs(w, r)
+	if !ok {
+		return subscription, false
+	}
+
+	getParams := supabase.GetPushSubscriptionParams{
+		ID:     id,
+		UserID: userId,
+	}
+
+	subscription, err := utils.Queries.GetPushSubscription(r.Context(), getParams)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.SendError(w, "Subscription not found", http.StatusNotFound)
+			return subscription, false
+		}
+		utils.SendError(w, "Failed to get subscription", http.StatusInternalServerError)
+		return subscription, false
+	}
+
+	return subscription, true
+}
+
+func pushSubscriptionIDs(w http.ResponseWriter, r *http.Request) (pgtype.UUID, pgtype.UUID, bool) {
+	idStr := chi.URLParam(r, "id")
+	if idStr == "" {
+		utils.SendError(w, "Missing id parameter", http.StatusBadRequest)
+		return pgtype.UUID{}, pgtype.UUID{}, false
+	}
+
+	id, err := utils.ParseUUID(idStr)
+	if err != nil {
+		utils.SendError(w, "Invalid subscription ID format", http.StatusBadRequest)
+		return pgtype.UUID{}, pgtype.UUID{}, false
+	}
+
+	userIdStr, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		utils.SendError(w, "Unauthorized", http.StatusUnauthorized)
+		return pgtype.UUID{}, pgtype.UUID{}, false
+	}
+
+	userId, err := utils.ParseUUID(userIdStr)
+	if err != nil {
+		utils.SendError(w, "Invalid user ID", http.StatusBadRequest)
+		return pgtype.UUID{}, pgtype.UUID{}, false
+	}
+
+	return id, userId, true
+}
+
+func HandleGetPushKey(w http.ResponseWriter, r *http.Request) {
+	cfg, ok := pushConfig(w)
+	if !ok {
+		return
+	}
+
+	utils.SendJson(w, models.PushKeyResponse{PublicKey: cfg.PublicKey}, http.StatusOK)
+}
+
+// HandleCreatePushSubscription registers a browser for push. Browsers keep
+// their endpoint across sign-ins, so subscribing an endpoint that is already
+// registered hands it to the caller.
+func HandleCreatePushSubscription(w http.ResponseWriter, r *http.Request) {
+	cfg, ok := pushConfig(w)
+	if !ok {
+		return
+	}
+
+	userIdStr, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		utils.SendError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	userId, err := utils.ParseUUID(userIdStr)
+	if err != nil {
+		utils.SendError(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	var req models.CreatePushSubscriptionRequest
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		utils.SendError(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	err = webpush.ValidateSubscription(req.Endpoint, req.Keys.P256dh, req.Keys.Auth, cfg.AllowLocal)
+	if err != nil {
+		utils.SendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	userAgent := r.UserAgent()
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = strings.ToValidUTF8(userAgent[:maxUserAgentLength], "")
+	}
+
+	upsertParams := supabase.UpsertPushSubscriptionParams{
+		UserID:    userId,
+		Endpoint:  req.Endpoint,
+		P256dh:    req.Keys.P256dh,
+		Auth:      req.Keys.Auth,
+		UserAgent: pgtype.Text{String: userAgent, Valid: userAgent != ""},
+	}
+
+	subscription, err := utils.Queries.UpsertPushSubscription(r.Context(), upsertParams)
+	if err != nil {
+		utils.SendError(w, "Failed to save subscription", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SendJson(w, toPushSubscriptionResponse(subscription), http.StatusCreated)
+}
+
+func HandleGetPushSubscriptions(w http.ResponseWriter, r *http.Request) {
+	userIdStr, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		utils.SendError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	userId, err := utils.ParseUUID(userIdStr)
+	if err != nil {
+		utils.SendError(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	subscriptions, err := utils.Queries.ListPushSubscriptions(r.Context(), userId)
+	if err != nil {
+		utils.SendError(w, "Failed to get subscriptions", http.StatusInternalServerError)
+		return
+	}
+
+	response := make([]models.PushSubscriptionResponse, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		response = append(response, toPushSubscriptionResponse(subscription))
+	}
+
+	utils.SendJson(w, response, http.StatusOK)
+}
+
+func HandleDeletePushSubscription(w http.ResponseWriter, r *http.Request) {
+	id, userId, ok := pushSubscriptionIDs(w, r)
+	if !ok {
+		return
+	}
+
+	deleteParams := supabase.DeletePushSubscriptionParams{
+		ID:     id,
+		UserID: userId,
+	}
+
+	deleted, err := utils.Queries.DeletePushSubscription(r.Context(), deleteParams)
+	if err != nil {
+		utils.SendError(w, "Failed to delete subscription", http.StatusInternalServerError)
+		return
+	}
+	if deleted == 0 {
+		utils.SendError(w, "Subscription not found", http.StatusNotFound)
+		return
+	}
+
+	utils.SendJson(w, "Subscription deleted", http.StatusOK)
+}
+
+// HandleTestPushSubscription queues a test message to one subscription, so a
+// user can check that notifications reach their device
+func HandleTestPushSubscription(w http.ResponseWriter, r *http.Request) {
+	if _, ok := pushConfig(w); !ok {
+		return
+	}
+
+	subscription, ok := pushSubscriptionFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	payload, err := json.Marshal(webpush.Message{
+		Title: "Cove",
+		Body:  "Notifications are working on this device",
+		Tag:   "test",
+	})
+	if err != nil {
+		utils.SendError(w, "Failed to queue test notification", http.StatusInternalServerError)
+		return
+	}
+
+	createParams := supabase.CreatePushDeliveryParams{
+		SubscriptionID: subscription.ID,
+		Payload:        payload,
+		ExpiresAt:      webpush.ExpiresAt(),
+	}
+
+	err = utils.Queries.CreatePushDelivery(r.Context(), createParams)
+	if err != nil {
+		utils.SendError(w, "Failed to queue test notification", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SendJson(w, toPushSubscriptionResponse(subscription), http.StatusAccepted)
+}
@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/egeuysall/cove/internal/clicks"
+	"github.com/egeuysall/cove/internal/middleware"
+	"github.com/egeuysall/cove/internal/models"
+	supabase "github.com/egeuysall/cove/internal/supabase/generated"
+	"github.com/egeuysall/cove/internal/utils"
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const clickStatsDays = 30
+
+// HandleShortLinkRedirect sends the browser on to the link's URL. The click
+// is only buffered here; it reaches the database on the next flush.
+func HandleShortLinkRedirect(w http.ResponseWriter, r *http.Request) {
+	shortID := chi.URLParam(r, "shortID")
+
+	link, err := utils.Queries.GetLinkByShortID(r.Context(), shortID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.SendError(w, "Link not found", http.StatusNotFound)
+			return
+		}
+		utils.SendError(w, "Failed to get link", http.StatusInternalServerError)
+		return
+	}
+
+	var userId pgtype.UUID
+	userIdStr := r.URL.Query().Get("u")
+	if clicks.VerifyUser(shortID, userIdStr, r.URL.Query().Get("s")) {
+		userId, _ = utils.ParseUUID(userIdStr)
+	}
+
+	// Only a signed short URL says who is following it, so a hidden link
+	// redirects just for its poster and moderators
+	visible, err := hiddenLinkVisible(r.Context(), link.GroupID, link.UserID, link.HiddenAt, userId)
+	if err != nil {
+		utils.SendError(w, "Failed to get link", http.StatusInternalServerError)
+		return
+	}
+	if !visible {
+		utils.SendError(w, "Link not found", http.StatusNotFound)
+		return
+	}
+
+	if !requireLinkAllowed(w, r, link.GroupID, link.Url, link.FinalUrl) {
+		return
+	}
+
+	clicks.Record(link.ID, userId, link.GroupID, time.Now())
+
+	w.Header().Del("Content-Type")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	http.Redirect(w, r, link.Url, http.StatusFound)
+}
+
+// decorateLinks fills in the viewer's short URLs, the posters' profiles and
+// how many members opened each link. Profiles and open counts are best
+// effort and left empty on error.
+func decorateLinks(r *http.Request, userId pgtype.UUID, links []supabase.Link, responses []models.LinkResponse) {
+	base := publicBaseURL(r)
+	viewer := utils.UUIDToString(userId)
+
+	linkIds := make([]pgtype.UUID, 0, len(links))
+	var posterIds []pgtype.UUID
+	seen := map[pgtype.UUID]bool{}
+	for i, link := range links {
+		responses[i].ShortURL = clicks.ShortURL(base, link.ShortID, viewer)
+		linkIds = append(linkIds, link.ID)
+		if link.UserID.Valid && !seen[link.UserID] {
+			seen[link.UserID] = true
+			posterIds = append(posterIds, link.UserID)
+		}
+	}
+
+	if len(linkIds) == 0 {
+		return
+	}
+
+	posters := visibleProfiles(r, userId, posterIds)
+	for i, link := range links {
+		if poster, ok := posters[link.UserID]; ok {
+			responses[i].Poster = &poster
+		}
+	}
+
+	counts, err := utils.Queries.GetLinkOpenCounts(r.Context(), linkIds)
+	if err != nil {
+		log.Printf("clicks: failed to count opens: %v", err)
+		return
+	}
+
+	openedBy := make(map[pgtype.UUID]int, len(counts))
+	for _, count := range counts {
+		openedBy[count.LinkID] = int(count.OpenedBy)
+	}
+	for i, link := range links {
+		responses[i].OpenedBy = openedBy[link.ID]
+	}
+}
+
+func HandleGetLinkClicks(w http.ResponseWriter, r *http.Request) {
+	linkIdStr := chi.URLParam(r, "id")
+	if linkIdStr == "" {
+		utils.SendError(w, "Missing id parameter", http.StatusBadRequest)
+		return
+	}
+
+	linkId, err := utils.ParseUUID(linkIdStr)
+	if err != nil {
+		utils.SendError(w, "Invalid link ID", http.StatusBadRequest)
+		return
+	}
+
+	userIdStr, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		utils.SendError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	userId, err := utils.ParseUUID(userIdStr)
+	if err != nil {
+		utils.SendError(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	link, err := utils.Queries.GetLinkByID(r.Context(), linkId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.SendError(w, "Link not found", http.StatusNotFound)
+			return
+		}
+		utils.SendError(w, "Failed to get link", http.StatusInternalServerError)
+		return
+	}
+
+	if !requireLinkVisible(w, r, link, userId) {
+		return
+	}
+
+	if link.UserID != userId {
+		utils.SendError(w, "Only the poster can see click stats", http.StatusForbidden)
+		return
+	}
+
+	totals, err := utils.Queries.GetLinkClickTotals(r.Context(), linkId)
+	if err != nil {
+		utils.SendError(w, "Failed to get click stats", http.StatusInternalServerError)
+		return
+	}
+
+	dayParams := supabase.GetLinkClicksByDayParams{
+		LinkID: linkId,
+		Limit:  clickStatsDays,
+	}
+
+	days, err := utils.Queries.GetLinkClicksByDay(r.Context(), dayParams)
+	if err != nil {
+		utils.SendError(w, "Failed to get click stats", http.StatusInternalServerError)
+		return
+	}
+
+	response := models.LinkClickStatsResponse{
+		LinkID:          utils.UUIDToString(linkId),
+		TotalClicks:     int(totals.TotalClicks),
+		UniqueMembers:   int(totals.UniqueMembers),
+		AnonymousClicks: int(totals.AnonymousClicks),
+		Days:            make([]models.LinkClickDay, 0, len(days)),
+	}
+	if totals.LastClickedAt.Valid {
+		response.LastClickedAt = &totals.LastClickedAt.Time
+	}
+	for _, day := range days {
+		response.Days = append(response.Days, models.LinkClickDay{
+			Date:   day.ClickedOn.Time.Format("2006-01-02"),
+			Clicks: int(day.Clicks),
+		})
+	}
+
+	utils.SendJson(w, response, http.StatusOK)
+}
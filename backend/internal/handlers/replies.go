@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/egeuysall/cove/internal/middleware"
+	"github.com/egeuysall/cove/internal/models"
+	"github.com/egeuysall/cove/internal/notifications"
+	supabase "github.com/egeuysall/cove/internal/supabase/generated"
+	"github.com/egeuysall/cove/internal/utils"
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const maxReplyLength = 2000
+
+// visibleLinkFromRequest loads the link named by the id URL parameter for the
+// caller, answering 404 for links they cannot see
+func visibleLinkFromRequest(w http.ResponseWriter, r *http.Request) (supabase.Link, pgtype.UUID, bool) {
+	var link supabase.Link
+
+	linkIdStr := chi.URLParam(r, "id")
+	if linkIdStr == "" {
+		utils.SendError(w, "Missing id parameter", http.StatusBadRequest)
+		return link, pgtype.UUID{}, false
+	}
+
+	linkId, err := utils.ParseUUID(linkIdStr)
+	if err != nil {
+		utils.SendError(w, "Invalid link ID", http.StatusBadRequest)
+		return link, pgtype.UUID{}, false
+	}
+
+	userIdStr, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		utils.SendError(w, "Unauthorized", http.StatusUnauthorized)
+		return link, pgtype.UUID{}, false
+	}
+
+	userId, err := utils.ParseUUID(userIdStr)
+	if err != nil {
+		utils.SendError(w, "Invalid user ID", http.StatusBadRequest)
+		return link, pgtype.UUID{}, false
+	}
+
+	link, err = utils.Queries.GetLinkByID(r.Context(), linkId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.SendError(w, "Link not found", http.StatusNotFound)
+			return link, userId, false
+		}
+		utils.SendError(w, "Failed to get link", http.StatusInternalServerError)
+		return link, userId, false
+	}
+
+	if !requireLinkVisible(w, r, link, userId) {
+		return link, userId, false
+	}
+
+	return link, userId, true
+}
+
+// HandleCreateLinkReply posts a reply under a link. The link's poster is
+// notified, as is anyone the reply mentions.
+func HandleCreateLinkReply(w http.ResponseWriter, r *http.Request) {
+	link, userId, ok := visibleLinkFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.CreateLinkReplyRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		utils.SendError(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	body := strings.TrimSpace(req.Body)
+	if body == "" {
+		utils.SendError(w, "Reply body is required", http.StatusBadRequest)
+		return
+	}
+	if utf8.RuneCountInString(body) > maxReplyLength {
+		utils.SendError(w, "Reply is too long", http.StatusBadRequest)
+		return
+	}
+
+	reply, err := utils.Queries.CreateLinkReply(r.Context(), supabase.CreateLinkReplyParams{
+		LinkID: link.ID,
+		UserID: userId,
+		Body:   body,
+	})
+	if err != nil {
+		utils.SendError(w, "Failed to create reply", http.StatusInternalServerError)
+		return
+	}
+
+	notifications.Replied(r.Context(), utils.Queries, link, reply)
+
+	authors := visibleProfiles(r, userId, []pgtype.UUID{userId})
+	utils.SendJson(w, toLinkReplyResponse(reply, authors), http.StatusCreated)
+}
+
+// HandleGetLinkReplies lists a link's replies, oldest first
+func HandleGetLinkReplies(w http.ResponseWriter, r *http.Request) {
+	link, userId, ok := visibleLinkFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	limit, err := utils.ParseLimit(r, 50, 100)
+	if err != nil {
+		utils.SendError(w, "Invalid limit", http.StatusBadRequest)
+		return
+	}
+
+	listParams := supabase.ListLinkRepliesParams{
+		LinkID:   link.ID,
+		RowLimit: limit,
+	}
+
+	listParams.CursorCreatedAt, listParams.CursorID, err = utils.DecodeCursor(r.URL.Query().Get("cursor"))
+	if err != nil {
+		utils.SendError(w, "Invalid cursor", http.StatusBadRequest)
+		return
+	}
+
+	replies, err := utils.Queries.ListLinkReplies(r.Context(), listParams)
+	if err != nil {
+		utils.SendError(w, "Failed to get replies", http.StatusInternalServerError)
+		return
+	}
+
+	var authorIds []pgtype.UUID
+	seen := map[pgtype.UUID]bool{}
+	for _, reply := range replies {
+		if !seen[reply.UserID] {
+			seen[reply.UserID] = true
+			authorIds = append(authorIds, reply.UserID)
+		}
+	}
+	authors := visibleProfiles(r, userId, authorIds)
+
+	items := make([]models.LinkReplyResponse, 0, len(replies))
+	for _, reply := range replies {
+		items = append(items, toLinkReplyResponse(reply, authors))
+	}
+
+	response := models.PageResponse{Items: items}
+	if len(replies) == int(limit) {
+		last := replies[len(replies)-1]
+		response.NextCursor = utils.EncodeCursor(last.CreatedAt.Time, last.ID)
+	}
+
+	utils.SendJson(w, response, http.StatusOK)
+}
+
+// HandleDeleteLinkReply deletes one of the caller's own replies
+func HandleDeleteLinkReply(w http.ResponseWriter, r *http.Request) {
+	link, userId, ok := visibleLinkFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	replyId, err := utils.ParseUUID(chi.URLParam(r, "rid"))
+	if err != nil {
+		utils.SendError(w, "Invalid reply ID", http.StatusBadRequest)
+		return
+	}
+
+	deleted, err := utils.Queries.DeleteLinkReply(r.Context(), supabase.DeleteLinkReplyParams{
+		ID:     replyId,
+		LinkID: link.ID,
+		UserID: userId,
+	})
+	if err != nil {
+		utils.SendError(w, "Failed to delete reply", http.StatusInternalServerError)
+		return
+	}
+	if deleted == 0 {
+		utils.SendError(w, "Reply not found", http.StatusNotFound)
+		return
+	}
+
+	utils.SendJson(w, "Reply deleted", http.StatusOK)
+}
+
+func toLinkReplyResponse(reply supabase.LinkReply, authors map[pgtype.UUID]models.ProfileResponse) models.LinkReplyResponse {
+	response := models.LinkReplyResponse{
+		ID:        utils.UUIDToString(reply.ID),
+		LinkID:    utils.UUIDToString(reply.LinkID),
+		UserID:    utils.UUIDToString(reply.UserID),
+		Body:      reply.Body,
+		CreatedAt: reply.CreatedAt.Time,
+	}
+	if author, ok := authors[reply.UserID]; ok {
+		response.Author = &author
+	}
+	return response
+}
@@ -0,0 +1,343 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/egeuysall/cove/internal/middleware"
+	"github.com/egeuysall/cove/internal/models"
+	supabase "github.com/egeuysall/cove/internal/supabase/generated"
+	"github.com/egeuysall/cove/internal/utils"
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	savedStatusToRead  = "to-read"
+	savedStatusReading = "reading"
+	savedStatusDone    = "done"
+)
+
+func isValidSavedStatus(status string) bool {
+	switch status {
+	case savedStatusToRead, savedStatusReading, savedStatusDone:
+		return true
+	}
+	return false
+}
+
+func toSavedLinkResponse(saved supabase.SavedLink) models.SavedLinkResponse {
+	return models.SavedLinkResponse{
+		ID:        utils.UUIDToString(saved.ID),
+		LinkID:    utils.UUIDToString(saved.LinkID),
+		GroupID:   utils.UUIDToString(saved.GroupID),
+		URL:       saved.Url,
+		Title:     saved.Title.String,
+		Status:    saved.Status,
+		Note:      saved.Note.String,
+		CreatedAt: saved.CreatedAt.Time,
+		UpdatedAt: saved.UpdatedAt.Time,
+	}
+}
+
+func HandleSaveLink(w http.ResponseWriter, r *http.Request) {
+	var req models.SaveLinkRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+
+	if err != nil {
+		utils.SendError(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	if req.LinkID == "" {
+		utils.SendError(w, "Link ID is required", http.StatusBadRequest)
+		return
+	}
+
+	if req.Status == "" {
+		req.Status = savedStatusToRead
+	}
+
+	if !isValidSavedStatus(req.Status) {
+		utils.SendError(w, "Status must be one of to-read, reading or done", http.StatusBadRequest)
+		return
+	}
+
+	userIdStr, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		utils.SendError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	userId, err := utils.ParseUUID(userIdStr)
+	if err != nil {
+		utils.SendError(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	linkId, err := utils.ParseUUID(req.LinkID)
+	if err != nil {
+		utils.SendError(w, "Invalid link ID", http.StatusBadRequest)
+		return
+	}
+
+	link, err := utils.Queries.GetLinkByID(r.Context(), linkId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.SendError(w, "Link not found", http.StatusNotFound)
+			return
+		}
+		utils.SendError(w, "Failed to get link", http.StatusInternalServerError)
+		return
+	}
+
+	if !requireLinkVisible(w, r, link, userId) {
+		return
+	}
+
+	saveParams := supabase.SaveLinkParams{
+		UserID:  userId,
+		LinkID:  link.ID,
+		GroupID: link.GroupID,
+		Url:     link.Url,
+		Title:   link.Title,
+		Status:  req.Status,
+	}
+
+	if req.Note != nil {
+		saveParams.Note = utils.TextOrNull(*req.Note)
+	}
+
+	saved, err := utils.Queries.SaveLink(r.Context(), saveParams)
+	if err != nil {
+		if utils.IsUniqueViolation(err) {
+			utils.SendError(w, "Link is already saved", http.StatusConflict)
+			return
+		}
+		utils.SendError(w, "Error saving link", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SendJson(w, toSavedLinkResponse(saved), http.StatusCreated)
+}
+
+func HandleGetSavedLinks(w http.ResponseWriter, r *http.Request) {
+	userIdStr, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		utils.SendError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	userId, err := utils.ParseUUID(userIdStr)
+	if err != nil {
+		utils.SendError(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	query := r.URL.Query()
+
+	listParams := supabase.ListSavedLinksParams{
+		UserID: userId,
+	}
+
+	if status := query.Get("status"); status != "" {
+		if !isValidSavedStatus(status) {
+			utils.SendError(w, "Status must be one of to-read, reading or done", http.StatusBadRequest)
+			return
+		}
+		listParams.Status = pgtype.Text{String: status, Valid: true}
+	}
+
+	if groupIdStr := query.Get("group_id"); groupIdStr != "" {
+		groupId, err := utils.ParseUUID(groupIdStr)
+		if err != nil {
+			utils.SendError(w, "Invalid group ID", http.StatusBadRequest)
+			return
+		}
+		listParams.GroupID = groupId
+	}
+
+	limit, err := utils.ParseLimit(r, 20, 100)
+	if err != nil {
+		utils.SendError(w, "Invalid limit", http.StatusBadRequest)
+		return
+	}
+	listParams.RowLimit = limit
+
+	listParams.CursorCreatedAt, listParams.CursorID, err = utils.DecodeCursor(query.Get("cursor"))
+	if err != nil {
+		utils.SendError(w, "Invalid cursor", http.StatusBadRequest)
+		return
+	}
+
+	savedLinks, err := utils.Queries.ListSavedLinks(r.Context(), listParams)
+	if err != nil {
+		utils.SendError(w, "Failed to get saved links", http.StatusInternalServerError)
+		return
+	}
+
+	items := make([]models.SavedLinkResponse, 0, len(savedLinks))
+	for _, saved := range savedLinks {
+		items = append(items, toSavedLinkResponse(saved))
+	}
+
+	response := models.PageResponse{Items: items}
+	if len(savedLinks) == int(limit) {
+		last := savedLinks[len(savedLinks)-1]
+		response.NextCursor = utils.EncodeCursor(last.CreatedAt.Time, last.ID)
+	}
+
+	utils.SendJson(w, response, http.StatusOK)
+}
+
+func HandleGetSavedLink(w http.ResponseWriter, r *http.Request) {
+	savedIdStr := chi.URLParam(r, "id")
+	if savedIdStr == "" {
+		utils.SendError(w, "Missing id parameter", http.StatusBadRequest)
+		return
+	}
+
+	savedId, err := utils.ParseUUID(savedIdStr)
+	if err != nil {
+		utils.SendError(w, "Invalid saved link ID", http.StatusBadRequest)
+		return
+	}
+
+	userIdStr, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		utils.SendError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	userId, err := utils.ParseUUID(userIdStr)
+	if err != nil {
+		utils.SendError(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	getParams := supabase.GetSavedLinkParams{
+		ID:     savedId,
+		UserID: userId,
+	}
+
+	saved, err := utils.Queries.GetSavedLink(r.Context(), getParams)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.SendError(w, "Saved link not found", http.StatusNotFound)
+			return
+		}
+		utils.SendError(w, "Failed to get saved link", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SendJson(w, toSavedLinkResponse(saved), http.StatusOK)
+}
+
+func HandleUpdateSavedLink(w http.ResponseWriter, r *http.Request) {
+	savedIdStr := chi.URLParam(r, "id")
+	if savedIdStr == "" {
+		utils.SendError(w, "Missing id parameter", http.StatusBadRequest)
+		return
+	}
+
+	savedId, err := utils.ParseUUID(savedIdStr)
+	if err != nil {
+		utils.SendError(w, "Invalid saved link ID", http.StatusBadRequest)
+		return
+	}
+
+	var req models.UpdateSavedLinkRequest
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		utils.SendError(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	if req.Status == nil && req.Note == nil {
+		utils.SendError(w, "Nothing to update", http.StatusBadRequest)
+		return
+	}
+
+	userIdStr, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		utils.SendError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	userId, err := utils.ParseUUID(userIdStr)
+	if err != nil {
+		utils.SendError(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	updateParams := supabase.UpdateSavedLinkParams{
+		ID:     savedId,
+		UserID: userId,
+	}
+
+	if req.Status != nil {
+		if !isValidSavedStatus(*req.Status) {
+			utils.SendError(w, "Status must be one of to-read, reading or done", http.StatusBadRequest)
+			return
+		}
+		updateParams.Status = pgtype.Text{String: *req.Status, Valid: true}
+	}
+
+	if req.Note != nil {
+		// An empty note is stored as-is so it can clear a previous one
+		updateParams.Note = pgtype.Text{String: *req.Note, Valid: true}
+	}
+
+	saved, err := utils.Queries.UpdateSavedLink(r.Context(), updateParams)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.SendError(w, "Saved link not found", http.StatusNotFound)
+			return
+		}
+		utils.SendError(w, "Failed to update saved link", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SendJson(w, toSavedLinkResponse(saved), http.StatusOK)
+}
+
+func HandleDeleteSavedLink(w http.ResponseWriter, r *http.Request) {
+	savedIdStr := chi.URLParam(r, "id")
+	if savedIdStr == "" {
+		utils.SendError(w, "Missing id parameter", http.StatusBadRequest)
+		return
+	}
+
+	savedId, err := utils.ParseUUID(savedIdStr)
+	if err != nil {
+		utils.SendError(w, "Invalid saved link ID", http.StatusBadRequest)
+		return
+	}
+
+	userIdStr, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		utils.SendError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	userId, err := utils.ParseUUID(userIdStr)
+	if err != nil {
+		utils.SendError(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	deleteParams := supabase.DeleteSavedLinkParams{
+		ID:     savedId,
+		UserID: userId,
+	}
+
+	err = utils.Queries.DeleteSavedLink(r.Context(), deleteParams)
+	if err != nil {
+		utils.SendError(w, "Failed to delete saved link", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SendJson(w, "Saved link deleted", http.StatusOK)
+}
@@ -0,0 +1,384 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/egeuysall/cove/internal/middleware"
+	"github.com/egeuysall/cove/internal/models"
+	"github.com/egeuysall/cove/internal/netguard"
+	supabase "github.com/egeuysall/cove/internal/supabase/generated"
+	"github.com/egeuysall/cove/internal/utils"
+	"github.com/egeuysall/cove/internal/webhooks"
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func toWebhookResponse(hook supabase.GroupWebhook) models.WebhookResponse {
+	response := models.WebhookResponse{
+		ID:           utils.UUIDToString(hook.ID),
+		GroupID:      utils.UUIDToString(hook.GroupID),
+		URL:          hook.Url,
+		Events:       hook.Events,
+		Enabled:      hook.Enabled,
+		FailureCount: int(hook.FailureCount),
+		CreatedAt:    hook.CreatedAt.Time,
+	}
+	if hook.DisabledAt.Valid {
+		response.DisabledAt = &hook.DisabledAt.Time
+	}
+	return response
+}
+
+func toWebhookDeliveryResponse(delivery supabase.WebhookDelivery) models.WebhookDeliveryResponse {
+	response := models.WebhookDeliveryResponse{
+		ID:        utils.UUIDToString(delivery.ID),
+		Event:     delivery.Event,
+		Status:    delivery.Status,
+		Attempts:  int(delivery.Attempts),
+		LastError: delivery.LastError.String,
+		Payload:   json.RawMessage(delivery.Payload),
+		CreatedAt: delivery.CreatedAt.Time,
+	}
+	if delivery.ResponseStatus.Valid {
+		status := int(delivery.ResponseStatus.Int32)
+		response.ResponseStatus = &status
+	}
+	if delivery.Status == "pending" {
+		response.NextAttemptAt = &delivery.NextAttemptAt.Time
+	}
+	if delivery.DeliveredAt.Valid {
+		response.DeliveredAt = &delivery.DeliveredAt.Time
+	}
+	return response
+}
+
+func validateWebhookEvents(events []string) bool {
+	if len(events) == 0 {
+		return false
+	}
+	for _, event := range events {
+		if !webhooks.IsValidEvent(event) {
+			return false
+		}
+	}
+	return true
+}
+
+// requireGroupAdmin resolves the {id} group and checks that the caller is its
+// owner or an admin, writing the error response itself when not.
+func requireGroupAdmin(w http.ResponseWriter, r *http.Request) (pgtype.UUID, pgtype.UUID, bool) {
+	var groupId, userId pgtype.UUID
+
+	groupIdStr := chi.URLParam(r, "id")
+	if groupIdStr == "" {
+		utils.SendError(w, "Missing group ID parameter", http.StatusBadRequest)
+		return groupId, userId, false
+	}
+
+	groupId, err := utils.ParseUUID(groupIdStr)
+	if err != nil {
+		utils.SendError(w, "Invalid group ID format", http.StatusBadRequest)
+		return groupId, userId, false
+	}
+
+	userIdStr, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		utils.SendError(w, "Unauthorized", http.StatusUnauthorized)
+		return groupId, userId, false
+	}
+
+	userId, err = utils.ParseUUID(userIdStr)
+	if err != nil {
+		utils.SendError(w, "Invalid user ID", http.StatusBadRequest)
+		return groupId, userId, false
+	}
+
+	adminParams := supabase.IsGroupAdminParams{
+		GroupID: groupId,
+		UserID:  userId,
+	}
+
+	isAdmin, err := utils.Queries.IsGroupAdmin(r.Context(), adminParams)
+	if err != nil {
+		utils.SendError(w, "Error checking group role", http.StatusInternalServerError)
+		return groupId, userId, false
+	}
+	if !isAdmin {
+		utils.SendError(w, "Only group owners and admins can do this", http.StatusForbidden)
+		return groupId, userId, false
+	}
+
+	return groupId, userId, true
+}
+
+// webhookFromRequest loads the {hid} webhook, scoped to the {id} group
+func webhookFromRequest(w http.ResponseWriter, r *http.Request, groupId pgtype.UUID) (supabase.GroupWebhook, bool) {
+	hookId, err := utils.ParseUUID(chi.URLParam(r, "hid"))
+	if err != nil {
+		utils.SendError(w, "Invalid webhook ID", http.StatusBadRequest)
+		return supabase.GroupWebhook{}, false
+	}
+
+	getParams := supabase.GetWebhookParams{
+		ID:      hookId,
+		GroupID: groupId,
+	}
+
+	hook, err := utils.Queries.GetWebhook(r.Context(), getParams)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.SendError(w, "Webhook not found", http.StatusNotFound)
+			return hook, false
+		}
+		utils.SendError(w, "Failed to get webhook", http.StatusInternalServerError)
+		return hook, false
+	}
+
+	return hook, true
+}
+
+// isValidWebhookURL also rejects loopback and private hosts, so a webhook
+// cannot be used to make the server post to its own network. Hostnames are
+// checked again after DNS when the dispatcher connects.
+func isValidWebhookURL(raw string) bool {
+	if !isValidLinkURL(raw) {
+		return false
+	}
+	parsed, err := url.Parse(raw)
+	return err == nil && netguard.CheckHost(parsed.Hostname()) == nil
+}
+
+func HandleCreateWebhook(w http.ResponseWriter, r *http.Request) {
+	groupId, userId, ok := requireGroupAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.CreateWebhookRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		utils.SendError(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	if !isValidWebhookURL(req.URL) {
+		utils.SendError(w, "A public http or https URL is required", http.StatusBadRequest)
+		return
+	}
+
+	if !validateWebhookEvents(req.Events) {
+		utils.SendError(w, "Events must be a non-empty list of link.created, link.deleted or member.joined", http.StatusBadRequest)
+		return
+	}
+
+	secret, err := webhooks.GenerateSecret()
+	if err != nil {
+		utils.SendError(w, "Failed to generate webhook secret", http.StatusInternalServerError)
+		return
+	}
+
+	createParams := supabase.CreateWebhookParams{
+		GroupID:   groupId,
+		Url:       req.URL,
+		Secret:    secret,
+		Events:    req.Events,
+		CreatedBy: userId,
+	}
+
+	hook, err := utils.Queries.CreateWebhook(r.Context(), createParams)
+	if err != nil {
+		utils.SendError(w, "Error creating webhook", http.StatusInternalServerError)
+		return
+	}
+
+	response := toWebhookResponse(hook)
+	response.Secret = hook.Secret
+
+	utils.SendJson(w, response, http.StatusCreated)
+}
+
+func HandleGetWebhooks(w http.ResponseWriter, r *http.Request) {
+	groupId, _, ok := requireGroupAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	hooks, err := utils.Queries.ListWebhooksByGroup(r.Context(), groupId)
+	if err != nil {
+		utils.SendError(w, "Failed to get webhooks", http.StatusInternalServerError)
+		return
+	}
+
+	response := make([]models.WebhookResponse, 0, len(hooks))
+	for _, hook := range hooks {
+		response = append(response, toWebhookResponse(hook))
+	}
+
+	utils.SendJson(w, response, http.StatusOK)
+}
+
+func HandleUpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	groupId, _, ok := requireGroupAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	hook, ok := webhookFromRequest(w, r, groupId)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateWebhookRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		utils.SendError(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	updateParams := supabase.UpdateWebhookParams{
+		ID:      hook.ID,
+		GroupID: groupId,
+	}
+
+	if req.URL != nil {
+		if !isValidWebhookURL(*req.URL) {
+			utils.SendError(w, "A public http or https URL is required", http.StatusBadRequest)
+			return
+		}
+		updateParams.Url = pgtype.Text{String: *req.URL, Valid: true}
+	}
+
+	if req.Events != nil {
+		if !validateWebhookEvents(req.Events) {
+			utils.SendError(w, "Events must be a non-empty list of link.created, link.deleted or member.joined", http.StatusBadRequest)
+			return
+		}
+		updateParams.Events = req.Events
+	}
+
+	if req.Enabled != nil {
+		updateParams.Enabled = pgtype.Bool{Bool: *req.Enabled, Valid: true}
+	}
+
+	hook, err = utils.Queries.UpdateWebhook(r.Context(), updateParams)
+	if err != nil {
+		utils.SendError(w, "Failed to update webhook", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SendJson(w, toWebhookResponse(hook), http.StatusOK)
+}
+
+func HandleDeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	groupId, _, ok := requireGroupAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	hook, ok := webhookFromRequest(w, r, groupId)
+	if !ok {
+		return
+	}
+
+	deleteParams := supabase.DeleteWebhookParams{
+		ID:      hook.ID,
+		GroupID: groupId,
+	}
+
+	err := utils.Queries.DeleteWebhook(r.Context(), deleteParams)
+	if err != nil {
+		utils.SendError(w, "Failed to delete webhook", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SendJson(w, "Webhook deleted", http.StatusOK)
+}
+
+func HandleGetWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	groupId, _, ok := requireGroupAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	hook, ok := webhookFromRequest(w, r, groupId)
+	if !ok {
+		return
+	}
+
+	limit, err := utils.ParseLimit(r, 50, 200)
+	if err != nil {
+		utils.SendError(w, "Invalid limit", http.StatusBadRequest)
+		return
+	}
+
+	listParams := supabase.ListWebhookDeliveriesParams{
+		WebhookID: hook.ID,
+		Limit:     limit,
+	}
+
+	deliveries, err := utils.Queries.ListWebhookDeliveries(r.Context(), listParams)
+	if err != nil {
+		utils.SendError(w, "Failed to get deliveries", http.StatusInternalServerError)
+		return
+	}
+
+	response := make([]models.WebhookDeliveryResponse, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		response = append(response, toWebhookDeliveryResponse(delivery))
+	}
+
+	utils.SendJson(w, response, http.StatusOK)
+}
+
+// HandleRedeliverWebhookDelivery queues a fresh copy of a past delivery with
+// the original payload, leaving the old record untouched.
+func HandleRedeliverWebhookDelivery(w http.ResponseWriter, r *http.Request) {
+	groupId, _, ok := requireGroupAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	hook, ok := webhookFromRequest(w, r, groupId)
+	if !ok {
+		return
+	}
+
+	deliveryId, err := utils.ParseUUID(chi.URLParam(r, "did"))
+	if err != nil {
+		utils.SendError(w, "Invalid delivery ID", http.StatusBadRequest)
+		return
+	}
+
+	getParams := supabase.GetWebhookDeliveryParams{
+		ID:        deliveryId,
+		WebhookID: hook.ID,
+	}
+
+	original, err := utils.Queries.GetWebhookDelivery(r.Context(), getParams)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.SendError(w, "Delivery not found", http.StatusNotFound)
+			return
+		}
+		utils.SendError(w, "Failed to get delivery", http.StatusInternalServerError)
+		return
+	}
+
+	createParams := supabase.CreateWebhookDeliveryParams{
+		WebhookID: hook.ID,
+		Event:     original.Event,
+		Payload:   original.Payload,
+	}
+
+	delivery, err := utils.Queries.CreateWebhookDelivery(r.Context(), createParams)
+	if err != nil {
+		utils.SendError(w, "Failed to queue redelivery", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SendJson(w, toWebhookDeliveryResponse(delivery), http.StatusAccepted)
+}
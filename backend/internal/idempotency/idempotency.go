@@ -0,0 +1,70 @@
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"time"
+
+	supabase "github.com/egeuysall/cove/internal/supabase/generated"
+)
+
+const (
+	// Header is the request header clients put their key in
+	Header = "Idempotency-Key"
+	// ReplayedHeader marks a response that was served from storage
+	ReplayedHeader = "Idempotent-Replayed"
+	// InProgressHeader marks the 409 sent while an earlier request with the
+	// same key is still running, so clients can tell it from a handler's 409
+	InProgressHeader = "Idempotent-In-Progress"
+	// TTL is how long a completed response is kept for replay
+	TTL = 24 * time.Hour
+	// StaleAfter is when an unfinished request's claim is considered
+	// abandoned, e.g. because its instance went away mid-request
+	StaleAfter = time.Minute
+	// MaxKeyLength bounds the header value
+	MaxKeyLength = 255
+	// MaxBodyBytes bounds the request bodies that are fingerprinted
+	MaxBodyBytes = 16 << 20
+)
+
+// Fingerprint identifies a request by its method, path and body, so a key
+// reused for a different request can be told apart from a retry
+func Fingerprint(method, path string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method + " " + path + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// Pruner deletes keys whose responses are past their TTL
+type Pruner struct {
+	queries  *supabase.Queries
+	interval time.Duration
+}
+
+func NewPruner(queries *supabase.Queries) *Pruner {
+	return &Pruner{
+		queries:  queries,
+		interval: time.Hour,
+	}
+}
+
+// Start prunes until ctx is cancelled
+func (p *Pruner) Start(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_, err := p.queries.DeleteExpiredIdempotencyKeys(ctx)
+			if err != nil {
+				log.Printf("idempotency: failed to prune keys: %v", err)
+			}
+		}
+	}
+}
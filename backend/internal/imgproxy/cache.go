@@ -0,0 +1,196 @@
+package imgproxy
+
+import (
+	"container/list"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// diskCache stores proxied images as files and evicts the least recently
+// used ones once the total size passes maxBytes. The index lives in memory
+// and is rebuilt from file modification times on start.
+type diskCache struct {
+	dir      string
+	maxBytes int64
+
+	mu      sync.Mutex
+	size    int64
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type cacheEntry struct {
+	key         string
+	contentType string
+	size        int64
+}
+
+// Content types are stored in the file name so no sidecar is needed
+var extensions = map[string]string{
+	"image/jpeg":               ".jpg",
+	"image/png":                ".png",
+	"image/gif":                ".gif",
+	"image/webp":               ".webp",
+	"image/x-icon":             ".ico",
+	"image/vnd.microsoft.icon": ".ico",
+}
+
+func contentTypeFor(ext string) string {
+	for contentType, e := range extensions {
+		if e == ext && contentType != "image/vnd.microsoft.icon" {
+			return contentType
+		}
+	}
+	return ""
+}
+
+func newDiskCache(dir string, maxBytes int64) (*diskCache, error) {
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &diskCache{
+		dir:      dir,
+		maxBytes: maxBytes,
+		order:    list.New(),
+		entries:  map[string]*list.Element{},
+	}
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	type found struct {
+		entry   cacheEntry
+		modTime int64
+	}
+	var existing []found
+
+	for _, file := range files {
+		ext := filepath.Ext(file.Name())
+		contentType := contentTypeFor(ext)
+		info, err := file.Info()
+		if err != nil || file.IsDir() || contentType == "" {
+			continue
+		}
+		existing = append(existing, found{
+			entry: cacheEntry{
+				key:         strings.TrimSuffix(file.Name(), ext),
+				contentType: contentType,
+				size:        info.Size(),
+			},
+			modTime: info.ModTime().UnixNano(),
+		})
+	}
+
+	sort.Slice(existing, func(i, j int) bool {
+		return existing[i].modTime > existing[j].modTime
+	})
+
+	for _, f := range existing {
+		c.entries[f.entry.key] = c.order.PushBack(f.entry)
+		c.size += f.entry.size
+	}
+
+	c.mu.Lock()
+	c.evict()
+	c.mu.Unlock()
+
+	return c, nil
+}
+
+func (c *diskCache) path(entry cacheEntry) string {
+	return filepath.Join(c.dir, entry.key+extensions[entry.contentType])
+}
+
+func (c *diskCache) Get(key string) ([]byte, string, bool) {
+	c.mu.Lock()
+	element, ok := c.entries[key]
+	if !ok {
+		c.mu.Unlock()
+		return nil, "", false
+	}
+	c.order.MoveToFront(element)
+	entry := element.Value.(cacheEntry)
+	c.mu.Unlock()
+
+	data, err := os.ReadFile(c.path(entry))
+	if err != nil {
+		c.remove(key)
+		return nil, "", false
+	}
+	return data, entry.contentType, true
+}
+
+// Put writes through a temporary file and renames it so readers never see a
+// partial image
+func (c *diskCache) Put(key, contentType string, data []byte) error {
+	entry := cacheEntry{key: key, contentType: contentType, size: int64(len(data))}
+	if extensions[contentType] == "" || entry.size > c.maxBytes {
+		return nil
+	}
+
+	tmp, err := os.CreateTemp(c.dir, "tmp-*")
+	if err != nil {
+		return err
+	}
+
+	_, err = tmp.Write(data)
+	closeErr := tmp.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), c.path(entry))
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		c.size -= element.Value.(cacheEntry).size
+		c.order.Remove(element)
+	}
+	c.entries[key] = c.order.PushFront(entry)
+	c.size += entry.size
+	c.evict()
+
+	return nil
+}
+
+func (c *diskCache) remove(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return
+	}
+	c.size -= element.Value.(cacheEntry).size
+	c.order.Remove(element)
+	delete(c.entries, key)
+}
+
+// evict must be called with mu held
+func (c *diskCache) evict() {
+	for c.size > c.maxBytes {
+		oldest := c.order.Back()
+		if oldest == nil {
+			return
+		}
+		entry := oldest.Value.(cacheEntry)
+		c.order.Remove(oldest)
+		delete(c.entries, entry.key)
+		c.size -= entry.size
+		_ = os.Remove(c.path(entry))
+	}
+}
@@ -0,0 +1,91 @@
+package imgproxy
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// Widths are the sizes images can be resized to. Keeping the set small keeps
+// the cache small and stops callers from requesting arbitrary sizes.
+var Widths = []int{32, 64, 128, 320, 640, 1280}
+
+const (
+	jpegQuality = 82
+	// maxPixels rejects decompression bombs before the full image is decoded
+	maxPixels = 40_000_000
+)
+
+var (
+	ErrUnsupportedType = errors.New("unsupported image type")
+	ErrTooLarge        = errors.New("image is too large")
+)
+
+// allowedTypes maps accepted upstream content types to whether they can be
+// decoded and resized. Icons are passed through as is; SVG is refused since
+// it can carry script.
+var allowedTypes = map[string]bool{
+	"image/jpeg":               true,
+	"image/png":                true,
+	"image/gif":                true,
+	"image/webp":               true,
+	"image/x-icon":             false,
+	"image/vnd.microsoft.icon": false,
+}
+
+func IsValidWidth(width int) bool {
+	if width == 0 {
+		return true
+	}
+	for _, w := range Widths {
+		if w == width {
+			return true
+		}
+	}
+	return false
+}
+
+// resize scales data down to width, keeping the aspect ratio. Images already
+// narrower are re-encoded at their own size, which also strips metadata.
+// Formats with transparency become PNG, everything else JPEG. Animated GIFs
+// keep only their first frame.
+func resize(data []byte, width int) ([]byte, string, error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrUnsupportedType, err)
+	}
+	if config.Width*config.Height > maxPixels {
+		return nil, "", ErrTooLarge
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrUnsupportedType, err)
+	}
+
+	bounds := src.Bounds()
+	dst := src
+	if bounds.Dx() > width {
+		height := max(1, bounds.Dy()*width/bounds.Dx())
+		scaled := image.NewRGBA(image.Rect(0, 0, width, height))
+		draw.CatmullRom.Scale(scaled, scaled.Bounds(), src, bounds, draw.Over, nil)
+		dst = scaled
+	}
+
+	var out bytes.Buffer
+	switch format {
+	case "png", "gif", "webp":
+		err = png.Encode(&out, dst)
+		return out.Bytes(), "image/png", err
+	}
+
+	err = jpeg.Encode(&out, dst, &jpeg.Options{Quality: jpegQuality})
+	return out.Bytes(), "image/jpeg", err
+}
@@ -0,0 +1,186 @@
+// Package imgproxy serves remote preview images and favicons through Cove so
+// clients never contact the origin directly.
+package imgproxy
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/egeuysall/cove/internal/netguard"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	maxImageBytes   = 8 << 20
+	defaultCacheMB  = 256
+	fetchTimeout    = 10 * time.Second
+	defaultCacheDir = "cove-img"
+)
+
+var ErrUpstream = errors.New("failed to fetch image")
+
+// Image is a proxied image ready to be written to a response
+type Image struct {
+	Data        []byte
+	ContentType string
+	ETag        string
+}
+
+// Proxy fetches, resizes and caches images. Concurrent requests for the
+// same image and width share one fetch.
+type Proxy struct {
+	client *http.Client
+	cache  *diskCache
+	group  singleflight.Group
+}
+
+// New builds a proxy caching up to maxCacheBytes in cacheDir. Image URLs
+// come from link previews, so a nil client means netguard.Client with
+// fetchTimeout.
+func New(client *http.Client, cacheDir string, maxCacheBytes int64) (*Proxy, error) {
+	if client == nil {
+		client = netguard.Client(fetchTimeout)
+	}
+
+	cache, err := newDiskCache(cacheDir, maxCacheBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Proxy{client: client, cache: cache}, nil
+}
+
+var (
+	defaultOnce  sync.Once
+	defaultProxy *Proxy
+	defaultErr   error
+)
+
+// Default returns the proxy configured by IMAGE_CACHE_DIR and
+// IMAGE_CACHE_MB, creating it on first use
+func Default() (*Proxy, error) {
+	defaultOnce.Do(func() {
+		dir := os.Getenv("IMAGE_CACHE_DIR")
+		if dir == "" {
+			dir = filepath.Join(os.TempDir(), defaultCacheDir)
+		}
+
+		cacheMB := defaultCacheMB
+		if raw := os.Getenv("IMAGE_CACHE_MB"); raw != "" {
+			parsed, err := strconv.Atoi(raw)
+			if err != nil || parsed < 1 {
+				log.Printf("imgproxy: ignoring invalid IMAGE_CACHE_MB %q", raw)
+			} else {
+				cacheMB = parsed
+			}
+		}
+
+		defaultProxy, defaultErr = New(nil, dir, int64(cacheMB)<<20)
+	})
+	return defaultProxy, defaultErr
+}
+
+func cacheKey(rawURL string, width int) string {
+	sum := sha256.Sum256([]byte(strconv.Itoa(width) + "|" + rawURL))
+	return hex.EncodeToString(sum[:])
+}
+
+// Get returns the image at rawURL scaled to width, or unscaled when width is
+// zero, serving from the disk cache when it can
+func (p *Proxy) Get(ctx context.Context, rawURL string, width int) (Image, error) {
+	key := cacheKey(rawURL, width)
+
+	if data, contentType, ok := p.cache.Get(key); ok {
+		return Image{Data: data, ContentType: contentType, ETag: `"` + key[:32] + `"`}, nil
+	}
+
+	result, err, _ := p.group.Do(key, func() (interface{}, error) {
+		data, contentType, err := p.fetch(ctx, rawURL)
+		if err != nil {
+			return nil, err
+		}
+
+		if width > 0 && allowedTypes[contentType] {
+			data, contentType, err = resize(data, width)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		err = p.cache.Put(key, contentType, data)
+		if err != nil {
+			log.Printf("imgproxy: failed to cache %s: %v", key, err)
+		}
+
+		return Image{Data: data, ContentType: contentType, ETag: `"` + key[:32] + `"`}, nil
+	})
+	if err != nil {
+		return Image{}, err
+	}
+
+	return result.(Image), nil
+}
+
+func (p *Proxy) fetch(ctx context.Context, rawURL string) ([]byte, string, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, "", fmt.Errorf("%w: not an http or https URL", ErrUpstream)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrUpstream, err)
+	}
+
+	req.Header.Set("User-Agent", "Cove-ImageProxy/1.0")
+	req.Header.Set("Accept", "image/webp, image/png, image/jpeg, image/gif, image/x-icon;q=0.8")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrUpstream, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("%w: origin responded with %d", ErrUpstream, resp.StatusCode)
+	}
+
+	contentType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if _, ok := allowedTypes[contentType]; !ok {
+		return nil, "", ErrUnsupportedType
+	}
+
+	if resp.ContentLength > maxImageBytes {
+		return nil, "", ErrTooLarge
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxImageBytes+1))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrUpstream, err)
+	}
+	if len(data) > maxImageBytes {
+		return nil, "", ErrTooLarge
+	}
+
+	// Trust the bytes rather than the header, so a mislabelled HTML page
+	// cannot be served as an image
+	sniffed, _, _ := mime.ParseMediaType(http.DetectContentType(data))
+	if _, ok := allowedTypes[sniffed]; !ok {
+		return nil, "", ErrUnsupportedType
+	}
+
+	return data, sniffed, nil
+}
@@ -0,0 +1,48 @@
+package imgproxy
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"os"
+	"strconv"
+	"strings"
+)
+
+func signature(rawURL string) string {
+	secret := strings.TrimSpace(os.Getenv("IMAGE_PROXY_SECRET"))
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("img:" + rawURL))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:20])
+}
+
+// URL builds the proxied address of an image. A width of zero serves the
+// image at its original size; other widths must be one of Widths.
+func URL(publicURL, rawURL string, width int) string {
+	proxied := strings.TrimRight(publicURL, "/") + "/img/" + signature(rawURL) + "/" + base64.RawURLEncoding.EncodeToString([]byte(rawURL))
+	if width > 0 {
+		proxied += "?w=" + strconv.Itoa(width)
+	}
+	return proxied
+}
+
+// Verify decodes the URL from a proxy path and checks its signature. It
+// always fails when IMAGE_PROXY_SECRET is unset so the proxy cannot be used
+// as an open relay.
+func Verify(sig, encoded string) (string, bool) {
+	if strings.TrimSpace(os.Getenv("IMAGE_PROXY_SECRET")) == "" {
+		return "", false
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", false
+	}
+
+	rawURL := string(raw)
+	if !hmac.Equal([]byte(signature(rawURL)), []byte(sig)) {
+		return "", false
+	}
+	return rawURL, true
+}
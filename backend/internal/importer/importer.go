@@ -0,0 +1,121 @@
+package importer
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"github.com/egeuysall/cove/internal/blocklist"
+	"github.com/egeuysall/cove/internal/models"
+	supabase "github.com/egeuysall/cove/internal/supabase/generated"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	batchSize = 500
+	// maxReportedErrors caps the per-row errors kept in a report so a badly
+	// broken file does not produce an enormous response
+	maxReportedErrors = 200
+)
+
+// Import inserts items into the group with COPY, skipping invalid rows and
+// URLs the group already has. Rows are attributed to userID.
+func Import(ctx context.Context, queries *supabase.Queries, groupID, userID pgtype.UUID, format string, items []Item) (models.ImportReport, error) {
+	report := models.ImportReport{
+		Format: format,
+		Total:  len(items),
+		Errors: []models.ImportRowError{},
+	}
+
+	existing, err := queries.GetGroupLinkURLs(ctx, groupID)
+	if err != nil {
+		return report, err
+	}
+
+	seen := make(map[string]bool, len(existing)+len(items))
+	for _, u := range existing {
+		seen[u] = true
+	}
+
+	now := time.Now()
+	batch := make([]supabase.ImportLinksParams, 0, batchSize)
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		inserted, err := queries.ImportLinks(ctx, batch)
+		if err != nil {
+			return err
+		}
+		report.Imported += int(inserted)
+		batch = batch[:0]
+		return nil
+	}
+
+	for _, item := range items {
+		if !isValidURL(item.URL) {
+			report.Failed++
+			addError(&report, item, "not a valid http or https URL")
+			continue
+		}
+
+		if seen[item.URL] {
+			report.Duplicates++
+			continue
+		}
+		seen[item.URL] = true
+
+		createdAt := item.CreatedAt
+		if createdAt.IsZero() || createdAt.After(now) {
+			createdAt = now
+		}
+
+		tags := item.Tags
+		if tags == nil {
+			tags = []string{}
+		}
+
+		batch = append(batch, supabase.ImportLinksParams{
+			ID:        pgtype.UUID{Bytes: uuid.New(), Valid: true},
+			GroupID:   groupID,
+			UserID:    userID,
+			Url:       item.URL,
+			Title:     pgtype.Text{String: item.Title, Valid: item.Title != ""},
+			Comment:   pgtype.Text{String: item.Comment, Valid: item.Comment != ""},
+			Tags:      tags,
+			CreatedAt: pgtype.Timestamptz{Time: createdAt, Valid: true},
+			UrlHost:   pgtype.Text{String: blocklist.Host(item.URL), Valid: true},
+		})
+
+		if len(batch) == batchSize {
+			err := flush()
+			if err != nil {
+				return report, err
+			}
+		}
+	}
+
+	err = flush()
+	return report, err
+}
+
+func addError(report *models.ImportReport, item Item, reason string) {
+	if len(report.Errors) >= maxReportedErrors {
+		return
+	}
+	report.Errors = append(report.Errors, models.ImportRowError{
+		Row:   item.Row,
+		URL:   item.URL,
+		Error: reason,
+	})
+}
+
+func isValidURL(raw string) bool {
+	parsed, err := url.ParseRequestURI(raw)
+	if err != nil {
+		return false
+	}
+	return (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
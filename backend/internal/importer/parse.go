@@ -0,0 +1,269 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+const (
+	FormatNetscape  = "netscape"
+	FormatPocketCSV = "pocket_csv"
+	FormatPinboard  = "pinboard"
+)
+
+var ErrUnknownFormat = errors.New("could not detect export format")
+
+// Item is one bookmark read from an export file. Row is its 1-based position
+// in the file, used to report per-row errors.
+type Item struct {
+	Row       int
+	URL       string
+	Title     string
+	Comment   string
+	Tags      []string
+	CreatedAt time.Time
+}
+
+// Detect guesses the export format from the file contents. Pocket's HTML
+// export is a Netscape-style bookmarks file and is parsed as one.
+func Detect(body []byte) string {
+	trimmed := bytes.TrimSpace(body)
+	lower := bytes.ToLower(trimmed[:min(len(trimmed), 512)])
+
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("[")):
+		return FormatPinboard
+	case bytes.Contains(lower, []byte("<!doctype netscape-bookmark-file")),
+		bytes.Contains(lower, []byte("<html")),
+		bytes.Contains(lower, []byte("<a ")):
+		return FormatNetscape
+	case bytes.Contains(lower, []byte("url")) && bytes.Contains(lower, []byte(",")):
+		return FormatPocketCSV
+	}
+	return ""
+}
+
+// Parse reads every bookmark from body in the given format
+func Parse(format string, body []byte) ([]Item, error) {
+	switch format {
+	case FormatNetscape:
+		return parseNetscape(body)
+	case FormatPocketCSV:
+		return parsePocketCSV(body)
+	case FormatPinboard:
+		return parsePinboard(body)
+	}
+	return nil, ErrUnknownFormat
+}
+
+// parseNetscape walks a bookmarks HTML file. Folder names (H3 headings)
+// enclosing a bookmark become tags, alongside any TAGS attribute.
+func parseNetscape(body []byte) ([]Item, error) {
+	tokenizer := html.NewTokenizer(bytes.NewReader(body))
+
+	var items []Item
+	var folders []string
+	var pendingFolder string
+	var current *Item
+	var inTitle, inFolder, inDescription bool
+
+	flush := func() {
+		if current != nil {
+			items = append(items, *current)
+			current = nil
+		}
+	}
+
+	for {
+		tokenType := tokenizer.Next()
+
+		switch tokenType {
+		case html.ErrorToken:
+			if errors.Is(tokenizer.Err(), io.EOF) {
+				flush()
+				return items, nil
+			}
+			return nil, tokenizer.Err()
+
+		case html.StartTagToken:
+			name, hasAttr := tokenizer.TagName()
+			switch string(name) {
+			case "a":
+				flush()
+				item := Item{Row: len(items) + 1}
+				for hasAttr {
+					var key, value []byte
+					key, value, hasAttr = tokenizer.TagAttr()
+					switch string(key) {
+					case "href":
+						item.URL = strings.TrimSpace(string(value))
+					case "add_date", "time_added":
+						item.CreatedAt = parseUnix(string(value))
+					case "tags":
+						item.Tags = append(item.Tags, splitTags(string(value), ",")...)
+					}
+				}
+				for _, folder := range folders {
+					if folder != "" {
+						item.Tags = append(item.Tags, folder)
+					}
+				}
+				current = &item
+				inTitle = true
+			case "h3":
+				inFolder = true
+				pendingFolder = ""
+			case "dl":
+				folders = append(folders, pendingFolder)
+				pendingFolder = ""
+			case "dd":
+				inDescription = current != nil
+			case "dt":
+				inDescription = false
+				flush()
+			}
+
+		case html.EndTagToken:
+			name, _ := tokenizer.TagName()
+			switch string(name) {
+			case "a":
+				inTitle = false
+			case "h3":
+				inFolder = false
+			case "dl":
+				inDescription = false
+				flush()
+				if len(folders) > 0 {
+					folders = folders[:len(folders)-1]
+				}
+			}
+
+		case html.TextToken:
+			text := string(tokenizer.Text())
+			switch {
+			case inTitle && current != nil:
+				current.Title += strings.TrimSpace(text)
+			case inFolder:
+				pendingFolder += strings.TrimSpace(text)
+			case inDescription && current != nil:
+				current.Comment += strings.TrimSpace(text)
+			}
+		}
+	}
+}
+
+// parsePocketCSV reads Pocket's CSV export: title,url,time_added,tags,status
+// with tags separated by "|".
+func parsePocketCSV(body []byte) ([]Item, error) {
+	reader := csv.NewReader(bytes.NewReader(body))
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	urlColumn, ok := columns["url"]
+	if !ok {
+		return nil, errors.New("CSV has no url column")
+	}
+
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var items []Item
+	for row := 1; ; row++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return items, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		item := Item{Row: row}
+		if urlColumn < len(record) {
+			item.URL = strings.TrimSpace(record[urlColumn])
+		}
+		item.Title = field(record, "title")
+		item.Tags = splitTags(field(record, "tags"), "|")
+		item.CreatedAt = parseUnix(field(record, "time_added"))
+
+		items = append(items, item)
+	}
+}
+
+type pinboardPost struct {
+	Href        string `json:"href"`
+	Description string `json:"description"`
+	Extended    string `json:"extended"`
+	Tags        string `json:"tags"`
+	Time        string `json:"time"`
+}
+
+// parsePinboard reads Pinboard's JSON export. Pinboard calls the title
+// "description" and the note "extended".
+func parsePinboard(body []byte) ([]Item, error) {
+	var posts []pinboardPost
+	err := json.Unmarshal(body, &posts)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]Item, 0, len(posts))
+	for i, post := range posts {
+		item := Item{
+			Row:     i + 1,
+			URL:     strings.TrimSpace(post.Href),
+			Title:   strings.TrimSpace(post.Description),
+			Comment: strings.TrimSpace(post.Extended),
+			Tags:    splitTags(post.Tags, " "),
+		}
+
+		t, err := time.Parse(time.RFC3339, post.Time)
+		if err == nil {
+			item.CreatedAt = t
+		}
+
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+func splitTags(raw, sep string) []string {
+	var tags []string
+	for _, tag := range strings.Split(raw, sep) {
+		tag = strings.TrimSpace(tag)
+		if tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+func parseUnix(raw string) time.Time {
+	seconds, err := strconv.ParseInt(strings.TrimSpace(raw), 10, 64)
+	if err != nil || seconds <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(seconds, 0)
+}
@@ -0,0 +1,283 @@
+// Package linkcheck periodically probes posted links and flags the ones that
+// have stopped resolving.
+package linkcheck
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/egeuysall/cove/internal/netguard"
+	supabase "github.com/egeuysall/cove/internal/supabase/generated"
+	"github.com/egeuysall/cove/internal/utils"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	StatusUnchecked = "unchecked"
+	StatusOK        = "ok"
+	StatusFailing   = "failing"
+	StatusDead      = "dead"
+
+	// DeadAfter is how many consecutive failed checks mark a link dead
+	DeadAfter = 3
+
+	okInterval      = 7 * 24 * time.Hour
+	failingInterval = 24 * time.Hour
+	deadInterval    = 30 * 24 * time.Hour
+
+	batchSize = 50
+	// maxHosts caps how many hosts are probed at once; each host is only
+	// ever sent one request at a time
+	maxHosts = 8
+	// hostDelay is the minimum gap between two requests to the same host
+	hostDelay = 2 * time.Second
+)
+
+// IsValidStatus reports whether status can be used to filter links
+func IsValidStatus(status string) bool {
+	switch status {
+	case StatusUnchecked, StatusOK, StatusFailing, StatusDead:
+		return true
+	}
+	return false
+}
+
+// Result is the outcome of probing one URL. StatusCode is zero when no
+// response was received at all.
+type Result struct {
+	StatusCode int
+	FinalURL   string
+	Err        error
+}
+
+// Failed reports whether the probe counts towards marking the link dead
+func (r Result) Failed() bool {
+	return r.Err != nil || r.StatusCode >= 400
+}
+
+// Throttled reports a 429, which says nothing about whether the link works
+func (r Result) Throttled() bool {
+	return r.StatusCode == http.StatusTooManyRequests
+}
+
+// Checker claims links that are due for a check and probes them
+type Checker struct {
+	queries  *supabase.Queries
+	client   *http.Client
+	interval time.Duration
+
+	mu       sync.Mutex
+	lastSeen map[string]time.Time
+}
+
+// NewChecker builds a checker. When client is nil, probes go through
+// netguard.Client and each request, HEAD or GET, gets 15 seconds.
+func NewChecker(queries *supabase.Queries, client *http.Client) *Checker {
+	if client == nil {
+		client = netguard.Client(15 * time.Second)
+	}
+
+	return &Checker{
+		queries:  queries,
+		client:   client,
+		interval: time.Minute,
+		lastSeen: map[string]time.Time{},
+	}
+}
+
+// Start runs the checker until ctx is cancelled
+func (c *Checker) Start(ctx context.Context) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		c.RunOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce checks one batch of due links. Links are grouped by host so each
+// host is probed sequentially with hostDelay between requests.
+func (c *Checker) RunOnce(ctx context.Context) {
+	due, err := c.queries.ClaimLinksForCheck(ctx, batchSize)
+	if err != nil {
+		log.Printf("linkcheck: failed to claim links: %v", err)
+		return
+	}
+
+	c.forgetIdleHosts()
+
+	byHost := map[string][]supabase.ClaimLinksForCheckRow{}
+	for _, link := range due {
+		host := hostOf(link.Url)
+		byHost[host] = append(byHost[host], link)
+	}
+
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, maxHosts)
+
+	for host, hostLinks := range byHost {
+		wg.Add(1)
+		slots <- struct{}{}
+
+		go func(host string, hostLinks []supabase.ClaimLinksForCheckRow) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			for _, link := range hostLinks {
+				if !c.waitForHost(ctx, host) {
+					return
+				}
+				c.check(ctx, link)
+			}
+		}(host, hostLinks)
+	}
+
+	wg.Wait()
+}
+
+func hostOf(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return parsed.Hostname()
+}
+
+// forgetIdleHosts drops hosts that have not been sent a request for longer
+// than hostDelay, since waitForHost would not make them wait anyway
+func (c *Checker) forgetIdleHosts() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for host, seen := range c.lastSeen {
+		if time.Since(seen) > hostDelay {
+			delete(c.lastSeen, host)
+		}
+	}
+}
+
+// waitForHost sleeps until hostDelay has passed since the last request to
+// host, including requests from earlier batches
+func (c *Checker) waitForHost(ctx context.Context, host string) bool {
+	c.mu.Lock()
+	wait := time.Until(c.lastSeen[host].Add(hostDelay))
+	c.lastSeen[host] = time.Now().Add(max(wait, 0))
+	c.mu.Unlock()
+
+	if wait <= 0 {
+		return true
+	}
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(wait):
+		return true
+	}
+}
+
+func (c *Checker) check(ctx context.Context, link supabase.ClaimLinksForCheckRow) {
+	result := c.Probe(ctx, link.Url)
+
+	// A probe cut short by shutdown says nothing about the link
+	if ctx.Err() != nil {
+		return
+	}
+
+	// A throttled probe keeps the current status and tries again later
+	if result.Throttled() {
+		return
+	}
+
+	params := supabase.RecordLinkCheckParams{
+		Status:        StatusOK,
+		StatusCode:    pgtype.Int4{Int32: int32(result.StatusCode), Valid: result.StatusCode != 0},
+		CheckFailures: 0,
+		NextCheckAt:   pgtype.Timestamptz{Time: time.Now().Add(okInterval), Valid: true},
+		ID:            link.ID,
+	}
+
+	if result.FinalURL != "" && result.FinalURL != link.Url {
+		params.RedirectUrl = pgtype.Text{String: result.FinalURL, Valid: true}
+	}
+
+	if result.Failed() {
+		params.CheckFailures = link.CheckFailures + 1
+		params.Status = StatusFailing
+		params.NextCheckAt.Time = time.Now().Add(failingInterval)
+
+		if params.CheckFailures >= DeadAfter {
+			params.Status = StatusDead
+			params.NextCheckAt.Time = time.Now().Add(deadInterval)
+		}
+	}
+
+	err := c.queries.RecordLinkCheck(ctx, params)
+	if err != nil {
+		log.Printf("linkcheck: failed to record check for %s: %v", utils.UUIDToString(link.ID), err)
+	}
+}
+
+// Probe sends a HEAD request and, if that fails or is rejected, a GET for
+// the first byte only. Many servers answer HEAD with 403, 404 or 405 while
+// serving GET fine.
+func (c *Checker) Probe(ctx context.Context, rawURL string) Result {
+	result := c.do(ctx, http.MethodHead, rawURL)
+	if !result.Failed() {
+		return result
+	}
+
+	// The GET is a second request to the same host, so it is spaced out too
+	if !c.waitForHost(ctx, hostOf(rawURL)) {
+		return result
+	}
+
+	// Prefer the HEAD response if the GET got no response at all
+	fallback := c.do(ctx, http.MethodGet, rawURL)
+	if fallback.StatusCode == 0 && result.StatusCode != 0 {
+		return result
+	}
+	return fallback
+}
+
+func (c *Checker) do(ctx context.Context, method, rawURL string) Result {
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, nil)
+	if err != nil {
+		return Result{Err: err}
+	}
+
+	req.Header.Set("User-Agent", "Cove-LinkChecker/1.0")
+	if method == http.MethodGet {
+		req.Header.Set("Range", "bytes=0-0")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return Result{Err: err}
+	}
+	defer resp.Body.Close()
+
+	// Servers that ignore Range may send a whole page; read only a little
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	result := Result{
+		StatusCode: resp.StatusCode,
+		FinalURL:   resp.Request.URL.String(),
+	}
+	if resp.StatusCode >= 400 {
+		result.Err = fmt.Errorf("%s responded with %d", method, resp.StatusCode)
+	}
+	return result
+}
@@ -0,0 +1,164 @@
+package links
+
+import (
+	"context"
+	"sync"
+
+	supabase "github.com/egeuysall/cove/internal/supabase/generated"
+	"github.com/egeuysall/cove/internal/urlexpand"
+	"github.com/egeuysall/cove/internal/utils"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// MaxBatchSize caps the links a batch may create, counting each group a link
+// is cross-posted to. It matches the per-minute allowance for posting links
+// one at a time, so batching is not a way around the rate limit.
+const MaxBatchSize = 20
+
+// batchWorkers bounds how many URLs a batch expands at once
+const batchWorkers = 4
+
+// BatchResult is the outcome of one link in a batch. Link is set when it was
+// created, and alongside ErrDuplicate to the link already posted.
+type BatchResult struct {
+	Link supabase.Link
+	Err  error
+}
+
+// CreateBatch runs each link through the same checks as Create and stores
+// the ones that pass in a single transaction. A link that fails, even while
+// being stored, does not stop the others; results are in the order given.
+// A URL that appears more than once is expanded only once, and the second
+// post of the same destination to a group counts as a duplicate of the first.
+func CreateBatch(ctx context.Context, db *pgxpool.Pool, queries *supabase.Queries, batch []supabase.CreateLinkParams) ([]BatchResult, error) {
+	results := make([]BatchResult, len(batch))
+	prepared := make([]supabase.CreateLinkParams, len(batch))
+
+	// Expanding URLs means fetching them, so it happens before the
+	// transaction is opened and a few at a time
+	expander := &sharedExpander{calls: map[string]*expansion{}}
+	sem := make(chan struct{}, batchWorkers)
+	var wg sync.WaitGroup
+	for i := range batch {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			prepared[i], results[i].Link, results[i].Err = prepare(ctx, queries, batch[i], expander.expand)
+		}(i)
+	}
+	wg.Wait()
+
+	// Posting the same destination twice to one group within the batch
+	duplicateOf := map[int]int{}
+	firstPost := map[string]int{}
+	for i := range batch {
+		if results[i].Err != nil {
+			continue
+		}
+		key := utils.UUIDToString(prepared[i].GroupID) + " " + finalURLOf(prepared[i])
+		if first, ok := firstPost[key]; ok {
+			results[i].Err = ErrDuplicate
+			duplicateOf[i] = first
+			continue
+		}
+		firstPost[key] = i
+	}
+
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	txQueries := queries.WithTx(tx)
+	stored := 0
+	for i := range batch {
+		if results[i].Err != nil {
+			continue
+		}
+
+		// A savepoint per link keeps one failed insert from aborting the
+		// rest of the transaction
+		if _, err := tx.Exec(ctx, "SAVEPOINT batch_link"); err != nil {
+			return nil, err
+		}
+		link, err := txQueries.CreateLink(ctx, prepared[i])
+		if err == nil {
+			_, err = tx.Exec(ctx, "RELEASE SAVEPOINT batch_link")
+		}
+		if err != nil {
+			if _, rbErr := tx.Exec(ctx, "ROLLBACK TO SAVEPOINT batch_link"); rbErr != nil {
+				return nil, rbErr
+			}
+			if _, relErr := tx.Exec(ctx, "RELEASE SAVEPOINT batch_link"); relErr != nil {
+				return nil, relErr
+			}
+			results[i].Err = err
+			continue
+		}
+		results[i].Link = link
+		stored++
+	}
+
+	if stored > 0 {
+		if err := tx.Commit(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	// Only links that made it into the database are announced
+	for i := range batch {
+		if results[i].Err == nil {
+			announce(ctx, queries, results[i].Link)
+		}
+	}
+
+	// Point in-batch duplicates at the link that was stored first, unless
+	// that one failed, in which case the duplicate gets its error
+	for i, first := range duplicateOf {
+		if results[first].Err != nil {
+			results[i].Err = results[first].Err
+			continue
+		}
+		results[i].Link = results[first].Link
+	}
+
+	return results, nil
+}
+
+func finalURLOf(params supabase.CreateLinkParams) string {
+	if params.FinalUrl.Valid {
+		return params.FinalUrl.String
+	}
+	return params.Url
+}
+
+// sharedExpander expands each distinct URL once, however many links in a
+// batch share it
+type sharedExpander struct {
+	mu    sync.Mutex
+	calls map[string]*expansion
+}
+
+type expansion struct {
+	once     sync.Once
+	finalURL string
+	err      error
+}
+
+func (e *sharedExpander) expand(ctx context.Context, rawURL string) (string, error) {
+	e.mu.Lock()
+	call, ok := e.calls[rawURL]
+	if !ok {
+		call = &expansion{}
+		e.calls[rawURL] = call
+	}
+	e.mu.Unlock()
+
+	call.once.Do(func() {
+		call.finalURL, call.err = urlexpand.Expand(ctx, rawURL)
+	})
+	return call.finalURL, call.err
+}
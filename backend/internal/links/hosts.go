@@ -0,0 +1,60 @@
+package links
+
+import (
+	"context"
+	"log"
+
+	"github.com/egeuysall/cove/internal/blocklist"
+	supabase "github.com/egeuysall/cove/internal/supabase/generated"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// backfillBatchSize is how many links BackfillHosts updates per query
+const backfillBatchSize = 500
+
+// hosts returns the normalized hosts group blocklists are matched against.
+// The URL's host is always set, even when empty, so BackfillHosts can tell
+// which links it has seen.
+func hosts(url string, finalURL pgtype.Text) (urlHost, finalHost pgtype.Text) {
+	urlHost = pgtype.Text{String: blocklist.Host(url), Valid: true}
+	if finalURL.Valid {
+		finalHost = pgtype.Text{String: blocklist.Host(finalURL.String), Valid: true}
+	}
+	return urlHost, finalHost
+}
+
+// BackfillHosts fills in the hosts of links stored before the server wrote
+// them, so group blocks apply to those links too. It stops at the first
+// error; the remaining links are picked up on the next start.
+func BackfillHosts(ctx context.Context, queries *supabase.Queries) {
+	total := 0
+	for {
+		batch, err := queries.ListLinksWithoutHosts(ctx, backfillBatchSize)
+		if err != nil {
+			log.Printf("links: failed to list links without hosts: %v", err)
+			return
+		}
+
+		for _, link := range batch {
+			urlHost, finalHost := hosts(link.Url, link.FinalUrl)
+			err := queries.SetLinkHosts(ctx, supabase.SetLinkHostsParams{
+				ID:        link.ID,
+				UrlHost:   urlHost,
+				FinalHost: finalHost,
+			})
+			if err != nil {
+				log.Printf("links: failed to set hosts: %v", err)
+				return
+			}
+		}
+		total += len(batch)
+
+		if len(batch) < backfillBatchSize {
+			break
+		}
+	}
+
+	if total > 0 {
+		log.Printf("links: filled in hosts for %d links", total)
+	}
+}
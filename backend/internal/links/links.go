@@ -0,0 +1,176 @@
+package links
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/egeuysall/cove/internal/blocklist"
+	"github.com/egeuysall/cove/internal/models"
+	"github.com/egeuysall/cove/internal/notifications"
+	supabase "github.com/egeuysall/cove/internal/supabase/generated"
+	"github.com/egeuysall/cove/internal/urlexpand"
+	"github.com/egeuysall/cove/internal/utils"
+	"github.com/egeuysall/cove/internal/webhooks"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	// ErrRejected wraps the reason a URL may not be posted at all
+	ErrRejected = errors.New("link rejected")
+	// ErrDuplicate is returned, along with the existing link, when the group
+	// already has a link with the same destination
+	ErrDuplicate = errors.New("link already posted in this group")
+)
+
+// Scopes a BlockedError can come from
+const (
+	ScopeInstance = "instance"
+	ScopeGroup    = "group"
+)
+
+// BlockedError is returned when the link's host, or the host it redirects
+// to, is on the instance or the group blocklist. It unwraps to ErrRejected.
+type BlockedError struct {
+	Domain string
+	Scope  string
+}
+
+func (e *BlockedError) Error() string {
+	if e.Scope == ScopeInstance {
+		return "links to " + e.Domain + " are not allowed on this server"
+	}
+	return "links to " + e.Domain + " are blocked in this group"
+}
+
+func (e *BlockedError) Unwrap() error {
+	return ErrRejected
+}
+
+// checkBlocked looks up each URL's host on the instance list and then on the
+// group's own list
+func checkBlocked(ctx context.Context, queries *supabase.Queries, groupID pgtype.UUID, urls ...string) error {
+	for _, rawURL := range urls {
+		if domain := blocklist.BlockedByInstance(rawURL); domain != "" {
+			return &BlockedError{Domain: domain, Scope: ScopeInstance}
+		}
+	}
+
+	for _, rawURL := range urls {
+		domain, err := queries.GetBlockingDomain(ctx, supabase.GetBlockingDomainParams{
+			GroupID: groupID,
+			Host:    blocklist.Host(rawURL),
+		})
+		if err == nil {
+			return &BlockedError{Domain: domain, Scope: ScopeGroup}
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Blocked checks a stored link against the blocklists as they are now, since
+// domains may have been blocked after it was posted. It returns a
+// *BlockedError when the link's URL or final URL is blocked.
+func Blocked(ctx context.Context, queries *supabase.Queries, groupID pgtype.UUID, url string, finalURL pgtype.Text) error {
+	urls := []string{url}
+	if finalURL.Valid {
+		urls = append(urls, finalURL.String)
+	}
+	return checkBlocked(ctx, queries, groupID, urls...)
+}
+
+// Create is the single path through which links are stored, whether posted by
+// a member or by a bot such as the feed poller. Bot links have no UserID.
+// Bulk imports skip it, so imported links get no webhook or snapshot.
+//
+// The URL's redirect chain is expanded first and the final URL is what
+// duplicates are detected on, so a bit.ly link and its target count as one.
+// Both are checked against the blocklists, so a shortener cannot hide a
+// blocked site.
+func Create(ctx context.Context, queries *supabase.Queries, params supabase.CreateLinkParams) (supabase.Link, error) {
+	params, existing, err := prepare(ctx, queries, params, urlexpand.Expand)
+	if err != nil {
+		return existing, err
+	}
+
+	link, err := queries.CreateLink(ctx, params)
+	if err != nil {
+		return link, err
+	}
+
+	announce(ctx, queries, link)
+	return link, nil
+}
+
+// prepare runs every check Create makes before storing a link and fills in
+// FinalUrl. On ErrDuplicate it also returns the link already posted.
+func prepare(ctx context.Context, queries *supabase.Queries, params supabase.CreateLinkParams, expand func(context.Context, string) (string, error)) (supabase.CreateLinkParams, supabase.Link, error) {
+	// Checking before expanding avoids fetching anything from blocked hosts
+	err := checkBlocked(ctx, queries, params.GroupID, params.Url)
+	if err != nil {
+		return params, supabase.Link{}, err
+	}
+
+	finalURL, err := expand(ctx, params.Url)
+	if err != nil {
+		return params, supabase.Link{}, fmt.Errorf("%w: %w", ErrRejected, err)
+	}
+
+	if finalURL != params.Url {
+		err = checkBlocked(ctx, queries, params.GroupID, finalURL)
+		if err != nil {
+			return params, supabase.Link{}, err
+		}
+	}
+
+	params.FinalUrl = utils.TextOrNull("")
+	if finalURL != params.Url {
+		params.FinalUrl = utils.TextOrNull(finalURL)
+	}
+	params.UrlHost, params.FinalHost = hosts(params.Url, params.FinalUrl)
+
+	existing, err := queries.FindGroupLinkByURL(ctx, supabase.FindGroupLinkByURLParams{
+		GroupID: params.GroupID,
+		Url:     finalURL,
+	})
+	if err == nil {
+		return params, existing, ErrDuplicate
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return params, supabase.Link{}, err
+	}
+
+	return params, supabase.Link{}, nil
+}
+
+// announce queues a stored link's snapshot and tells the group's webhooks and
+// members about it. It must only run once the link is committed, and logs
+// rather than returns failures, which never undo the link.
+func announce(ctx context.Context, queries *supabase.Queries, link supabase.Link) {
+	// The archiver picks this up and stores a readable copy of the page
+	err := queries.CreatePendingSnapshot(ctx, link.ID)
+	if err != nil {
+		log.Printf("links: failed to queue snapshot for %s: %v", utils.UUIDToString(link.ID), err)
+	}
+
+	webhooks.Enqueue(ctx, queries, link.GroupID, webhooks.EventLinkCreated, models.LinkResponse{
+		ID:        utils.UUIDToString(link.ID),
+		GroupID:   utils.UUIDToString(link.GroupID),
+		UserID:    utils.UUIDToString(link.UserID),
+		URL:       link.Url,
+		FinalURL:  link.FinalUrl.String,
+		Title:     link.Title.String,
+		Comment:   link.Comment.String,
+		Tags:      link.Tags,
+		Status:    link.Status,
+		CreatedAt: link.CreatedAt.Time,
+	})
+
+	notifications.LinkPosted(ctx, queries, link)
+}
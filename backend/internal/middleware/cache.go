@@ -0,0 +1,24 @@
+package middleware
+
+import "net/http"
+
+// Cache-Control values used by the router
+const (
+	// CacheNoStore keeps responses out of every cache, for anything holding
+	// secrets or one-off results
+	CacheNoStore = "no-store"
+	// CacheRevalidate lets the browser keep a private copy but check it on
+	// every use, which is what makes ETags pay off
+	CacheRevalidate = "private, no-cache"
+)
+
+// CacheControl sets the Cache-Control header for every response on the
+// route. Unlike chi's NoCache it leaves conditional request headers alone.
+func CacheControl(value string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Cache-Control", value)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
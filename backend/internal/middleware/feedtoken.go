@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/egeuysall/cove/internal/feeds"
+	supabase "github.com/egeuysall/cove/internal/supabase/generated"
+	"github.com/egeuysall/cove/internal/utils"
+	"github.com/jackc/pgx/v5"
+)
+
+const feedTokenKey = contextKey("feedToken")
+
+// FeedToken looks up the token query parameter feed readers authenticate
+// with and, if it exists, adds it to the context for RateLimit and the feed
+// handler. Unknown tokens are passed on without one, so RateLimit counts
+// them against the client IP and the handler rejects them.
+func FeedToken() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token := r.URL.Query().Get("token")
+			if token == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			feedToken, err := utils.Queries.GetFeedTokenByHash(r.Context(), feeds.HashToken(token))
+			if err != nil {
+				if errors.Is(err, pgx.ErrNoRows) {
+					next.ServeHTTP(w, r)
+					return
+				}
+				utils.SendError(w, "Failed to check feed token", http.StatusInternalServerError)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), feedTokenKey, feedToken)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func FeedTokenFromContext(ctx context.Context) (supabase.FeedToken, bool) {
+	feedToken, ok := ctx.Value(feedTokenKey).(supabase.FeedToken)
+	return feedToken, ok
+}
@@ -0,0 +1,216 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/egeuysall/cove/internal/idempotency"
+	supabase "github.com/egeuysall/cove/internal/supabase/generated"
+	"github.com/egeuysall/cove/internal/utils"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	// idempotencyWait bounds how long a retry waits for the request that
+	// holds its key to finish
+	idempotencyWait = 10 * time.Second
+	idempotencyPoll = 200 * time.Millisecond
+)
+
+// recordingWriter passes a response through while keeping a copy of it
+type recordingWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *recordingWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func replay(w http.ResponseWriter, stored supabase.IdempotencyKey) {
+	if stored.ContentType.Valid {
+		w.Header().Set("Content-Type", stored.ContentType.String)
+	}
+	w.Header().Set(idempotency.ReplayedHeader, "true")
+	w.WriteHeader(int(stored.ResponseStatus.Int32))
+
+	_, err := w.Write(stored.ResponseBody)
+	if err != nil {
+		log.Printf("idempotency: failed to replay response: %v", err)
+	}
+}
+
+// Idempotency makes POST requests that carry an Idempotency-Key safe to
+// retry. The first request with a key claims it and its response is kept
+// for 24 hours; retries with the same body get that response back, while
+// reusing the key for a different request is a 422. A retry that arrives
+// while the first request is still running waits for it to finish.
+// Server errors are not kept, so the request can be retried for real.
+//
+// It must run after RequireAuth, as keys are scoped to the user.
+func Idempotency() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(idempotency.Header)
+			if r.Method != http.MethodPost || key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if len(key) > idempotency.MaxKeyLength {
+				utils.SendError(w, "Idempotency-Key must be at most 255 characters", http.StatusBadRequest)
+				return
+			}
+
+			userIdStr, ok := UserIDFromContext(r.Context())
+			if !ok {
+				utils.SendError(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+
+			userId, err := utils.ParseUUID(userIdStr)
+			if err != nil {
+				utils.SendError(w, "Invalid user ID", http.StatusBadRequest)
+				return
+			}
+
+			body, err := io.ReadAll(io.LimitReader(r.Body, idempotency.MaxBodyBytes+1))
+			if err != nil {
+				utils.SendError(w, "Failed to read request body", http.StatusBadRequest)
+				return
+			}
+			if len(body) > idempotency.MaxBodyBytes {
+				utils.SendError(w, "Request body is too large", http.StatusRequestEntityTooLarge)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			fingerprint := idempotency.Fingerprint(r.Method, r.URL.Path, body)
+
+			claimed, stored, err := claimIdempotencyKey(r.Context(), userId, key, fingerprint)
+			if err != nil {
+				if errors.Is(err, context.DeadlineExceeded) {
+					w.Header().Set("Retry-After", "1")
+					w.Header().Set(idempotency.InProgressHeader, "true")
+					utils.SendError(w, "A request with this Idempotency-Key is still in progress", http.StatusConflict)
+					return
+				}
+				log.Printf("idempotency: failed to claim key: %v", err)
+				utils.SendError(w, "Failed to check Idempotency-Key", http.StatusInternalServerError)
+				return
+			}
+
+			if !claimed {
+				if stored.Fingerprint != fingerprint {
+					utils.SendError(w, "Idempotency-Key was already used for a different request", http.StatusUnprocessableEntity)
+					return
+				}
+				replay(w, stored)
+				return
+			}
+
+			recorder := &recordingWriter{ResponseWriter: w}
+			completed := false
+
+			// Release the claim when the response is not kept, including
+			// when the handler panics, so the key can be retried
+			defer func() {
+				if completed {
+					return
+				}
+				err := utils.Queries.ReleaseIdempotencyKey(context.WithoutCancel(r.Context()), supabase.ReleaseIdempotencyKeyParams{
+					UserID: userId,
+					Key:    key,
+				})
+				if err != nil {
+					log.Printf("idempotency: failed to release key: %v", err)
+				}
+			}()
+
+			next.ServeHTTP(recorder, r)
+
+			if recorder.status == 0 || recorder.status >= http.StatusInternalServerError {
+				return
+			}
+
+			err = utils.Queries.CompleteIdempotencyKey(context.WithoutCancel(r.Context()), supabase.CompleteIdempotencyKeyParams{
+				UserID:         userId,
+				Key:            key,
+				ResponseStatus: pgtype.Int4{Int32: int32(recorder.status), Valid: true},
+				ContentType:    utils.TextOrNull(recorder.Header().Get("Content-Type")),
+				ResponseBody:   recorder.body.Bytes(),
+			})
+			if err != nil {
+				log.Printf("idempotency: failed to store response: %v", err)
+				return
+			}
+			completed = true
+		})
+	}
+}
+
+// claimIdempotencyKey either claims key for this request, or returns the
+// completed request that holds it. While another request holds the key
+// unfinished, it polls until that one completes or gives up, failing with
+// context.DeadlineExceeded after idempotencyWait.
+func claimIdempotencyKey(ctx context.Context, userId pgtype.UUID, key, fingerprint string) (bool, supabase.IdempotencyKey, error) {
+	deadline := time.Now().Add(idempotencyWait)
+
+	for {
+		now := time.Now()
+		_, err := utils.Queries.ClaimIdempotencyKey(ctx, supabase.ClaimIdempotencyKeyParams{
+			UserID:      userId,
+			Key:         key,
+			Fingerprint: fingerprint,
+			ExpiresAt:   pgtype.Timestamptz{Time: now.Add(idempotency.TTL), Valid: true},
+			StaleBefore: pgtype.Timestamptz{Time: now.Add(-idempotency.StaleAfter), Valid: true},
+		})
+		if err == nil {
+			return true, supabase.IdempotencyKey{}, nil
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return false, supabase.IdempotencyKey{}, err
+		}
+
+		stored, err := utils.Queries.GetIdempotencyKey(ctx, supabase.GetIdempotencyKeyParams{
+			UserID: userId,
+			Key:    key,
+		})
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return false, supabase.IdempotencyKey{}, err
+		}
+		// A different body is rejected without waiting for the holder
+		if err == nil && (stored.Status == "completed" || stored.Fingerprint != fingerprint) {
+			return false, stored, nil
+		}
+
+		// Either still running, or released between the two queries
+		if time.Now().After(deadline) {
+			return false, supabase.IdempotencyKey{}, context.DeadlineExceeded
+		}
+
+		select {
+		case <-ctx.Done():
+			return false, supabase.IdempotencyKey{}, ctx.Err()
+		case <-time.After(idempotencyPoll):
+		}
+	}
+}
@@ -17,6 +17,7 @@ import (
 type contextKey string
 
 const userIDKey = contextKey("userID")
+const emailKey = contextKey("email")
 
 func RequireAuth() func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
@@ -104,6 +105,12 @@ func RequireAuth() func(http.Handler) http.Handler {
 
 			// Add user ID to the context and continue the request
 			ctx := context.WithValue(r.Context(), userIDKey, sub)
+
+			// Supabase includes the account email, which digests are sent to
+			if email, ok := claims["email"].(string); ok && email != "" {
+				ctx = context.WithValue(ctx, emailKey, email)
+			}
+
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
@@ -114,11 +121,17 @@ func UserIDFromContext(ctx context.Context) (string, bool) {
 	return userID, ok
 }
 
+func EmailFromContext(ctx context.Context) (string, bool) {
+	email, ok := ctx.Value(emailKey).(string)
+	return email, ok
+}
+
 func Cors() func(next http.Handler) http.Handler {
 	return cors.Handler(cors.Options{
 		AllowedOrigins:   []string{"https://www.cove.egeuysal.com", "http://localhost:3000"},
-		AllowedMethods:   []string{"GET", "POST", "PATCH", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "Idempotency-Key"},
+		ExposedHeaders:   []string{"ETag", "RateLimit-Policy", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "Retry-After", "Idempotent-Replayed", "Idempotent-In-Progress"},
 		AllowCredentials: true,
 		MaxAge:           3600,
 	})
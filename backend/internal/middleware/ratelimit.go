@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/egeuysall/cove/internal/ratelimit"
+	"github.com/egeuysall/cove/internal/utils"
+)
+
+// rateLimitSubject identifies who a request counts against: the signed-in
+// user, else the feed token FeedToken verified, else the client IP. An
+// unverified token is never used, since a client could send a new one with
+// every request to escape its limit.
+func rateLimitSubject(r *http.Request) string {
+	if userID, ok := UserIDFromContext(r.Context()); ok {
+		return "user:" + userID
+	}
+
+	if feedToken, ok := FeedTokenFromContext(r.Context()); ok {
+		return "token:" + utils.UUIDToString(feedToken.ID)
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+func limit(w http.ResponseWriter, r *http.Request, next http.Handler, policy ratelimit.Policy) {
+	result, err := ratelimit.Take(r.Context(), utils.Queries, policy, rateLimitSubject(r))
+	if err != nil {
+		// A database hiccup should not take the whole API down with it
+		log.Printf("ratelimit: failed to take token for %s: %v", policy.Name, err)
+		next.ServeHTTP(w, r)
+		return
+	}
+
+	// When several policies apply, the innermost one's headers win
+	w.Header().Set("RateLimit-Policy", policy.Header())
+	w.Header().Set("RateLimit-Limit", strconv.Itoa(policy.Limit))
+	w.Header().Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	w.Header().Set("RateLimit-Reset", strconv.Itoa(int(result.Reset.Seconds())))
+
+	if !result.Allowed {
+		w.Header().Set("Retry-After", strconv.Itoa(int(result.RetryAfter.Seconds())))
+		utils.SendError(w, "Too many requests", http.StatusTooManyRequests)
+		return
+	}
+
+	next.ServeHTTP(w, r)
+}
+
+// RateLimit counts every request against policy. Place it after RequireAuth
+// so requests are keyed by user rather than by IP.
+func RateLimit(policy ratelimit.Policy) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			limit(w, r, next, policy)
+		})
+	}
+}
+
+// RateLimitByMethod counts safe methods against read and the rest against
+// write
+func RateLimitByMethod(read, write ratelimit.Policy) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				limit(w, r, next, read)
+			default:
+				limit(w, r, next, write)
+			}
+		})
+	}
+}
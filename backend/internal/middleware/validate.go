@@ -0,0 +1,143 @@
+package middleware
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/egeuysall/cove/internal/models"
+	"github.com/egeuysall/cove/internal/utils"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+)
+
+// ValidateRequest rejects requests that do not match doc with a 400 listing
+// every problem found. Requests for paths the document does not describe
+// are passed through so the router can answer them with a 404 or 405.
+func ValidateRequest(doc *openapi3.T) func(http.Handler) http.Handler {
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		log.Fatalf("Failed to build OpenAPI router: %v", err)
+	}
+
+	// A merge patch is plain JSON as far as the schema is concerned
+	openapi3filter.RegisterBodyDecoder("application/merge-patch+json", openapi3filter.JSONBodyDecoder)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route, pathParams, err := router.FindRoute(r)
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// Handlers decode JSON whatever the declared type, and clients
+			// have always been able to leave it off
+			if r.Header.Get("Content-Type") == "" && r.ContentLength != 0 && route.Operation.RequestBody != nil {
+				r.Header.Set("Content-Type", "application/json")
+			}
+
+			input := &openapi3filter.RequestValidationInput{
+				Request:    r,
+				PathParams: pathParams,
+				Route:      route,
+				Options: &openapi3filter.Options{
+					// Uploads are parsed in a streaming fashion by the
+					// importer, which enforces its own limits
+					ExcludeRequestBody:  strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/"),
+					MultiError:          true,
+					SkipSettingDefaults: true,
+					// Authentication is RequireAuth's job
+					AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+				},
+			}
+
+			if err := openapi3filter.ValidateRequest(r.Context(), input); err != nil {
+				utils.SendValidationError(w, "Request does not match the API specification", validationErrors(err))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// validationErrors flattens the errors kin-openapi reports into one entry
+// per problem
+func validationErrors(err error) []models.ValidationError {
+	// Only a top-level MultiError is split here; RequestError unwraps to the
+	// MultiError of its own schema errors, which would lose the parameter
+	if multi, ok := err.(openapi3.MultiError); ok {
+		var out []models.ValidationError
+		for _, e := range multi {
+			out = append(out, validationErrors(e)...)
+		}
+		return out
+	}
+
+	var reqErr *openapi3filter.RequestError
+	if !errors.As(err, &reqErr) {
+		return []models.ValidationError{{In: "request", Message: err.Error()}}
+	}
+
+	if p := reqErr.Parameter; p != nil {
+		return []models.ValidationError{{In: p.In, Name: p.Name, Message: reasonOf(reqErr)}}
+	}
+
+	if reqErr.RequestBody != nil {
+		var bodyMulti openapi3.MultiError
+		if errors.As(reqErr.Err, &bodyMulti) {
+			var out []models.ValidationError
+			for _, e := range bodyMulti {
+				out = append(out, bodyError(e))
+			}
+			return out
+		}
+		if reqErr.Err != nil {
+			return []models.ValidationError{bodyError(reqErr.Err)}
+		}
+		return []models.ValidationError{{In: "body", Message: reqErr.Reason}}
+	}
+
+	return []models.ValidationError{{In: "request", Message: reasonOf(reqErr)}}
+}
+
+func bodyError(err error) models.ValidationError {
+	var schemaErr *openapi3.SchemaError
+	if errors.As(err, &schemaErr) {
+		name := ""
+		if pointer := schemaErr.JSONPointer(); len(pointer) > 0 {
+			name = "/" + strings.Join(pointer, "/")
+		}
+		return models.ValidationError{In: "body", Name: name, Message: schemaErr.Reason}
+	}
+	return models.ValidationError{In: "body", Message: err.Error()}
+}
+
+func reasonOf(err *openapi3filter.RequestError) string {
+	var schemaErr *openapi3.SchemaError
+	if errors.As(err.Err, &schemaErr) {
+		return schemaErr.Reason
+	}
+	var multi openapi3.MultiError
+	if errors.As(err.Err, &multi) && len(multi) > 0 {
+		var reasons []string
+		for _, e := range multi {
+			if errors.As(e, &schemaErr) {
+				reasons = append(reasons, schemaErr.Reason)
+			} else {
+				reasons = append(reasons, e.Error())
+			}
+		}
+		return strings.Join(reasons, "; ")
+	}
+	if err.Err != nil {
+		if err.Reason != "" {
+			return err.Reason + ": " + err.Err.Error()
+		}
+		return err.Err.Error()
+	}
+	return err.Reason
+}
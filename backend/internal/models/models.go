@@ -1,6 +1,9 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 type Group struct {
 	ID        string    `json:"id"`
@@ -9,6 +12,47 @@ type Group struct {
 	CreatedBy string    `json:"created_by,omitempty"`
 }
 
+// GroupSettings controls who may do what in a group. WhoCanInvite and
+// WhoCanPost are "members" or "admins". MaxMembers and RetentionDays are
+// unlimited when zero.
+type GroupSettings struct {
+	WhoCanInvite  string `json:"who_can_invite"`
+	WhoCanPost    string `json:"who_can_post"`
+	MaxMembers    int    `json:"max_members"`
+	RetentionDays int    `json:"retention_days"`
+}
+
+// GroupResponse keeps the original capitalised keys of a group, which
+// clients already depend on, and adds its profile and settings.
+type GroupResponse struct {
+	ID          string        `json:"ID"`
+	Name        string        `json:"Name"`
+	CreatedBy   string        `json:"CreatedBy"`
+	CreatedAt   time.Time     `json:"CreatedAt"`
+	Description string        `json:"Description,omitempty"`
+	Emoji       string        `json:"Emoji,omitempty"`
+	AvatarURL   string        `json:"AvatarURL,omitempty"`
+	Settings    GroupSettings `json:"Settings"`
+	UpdatedAt   time.Time     `json:"UpdatedAt"`
+}
+
+// ProfileResponse is how a user presents themselves. Users who never set up
+// a profile, and users who share no group with the viewer, have only UserID.
+type ProfileResponse struct {
+	UserID      string `json:"user_id"`
+	DisplayName string `json:"display_name,omitempty"`
+	AvatarURL   string `json:"avatar_url,omitempty"`
+	Bio         string `json:"bio,omitempty"`
+	Timezone    string `json:"timezone,omitempty"`
+}
+
+// MemberResponse is a group member's profile with their place in the group
+type MemberResponse struct {
+	ProfileResponse
+	Role     string    `json:"role"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
 type User struct {
 	UserId string `json:"user_id"`
 }
@@ -19,8 +63,385 @@ type CreateInviteRequest struct {
 
 // InviteResponse is the response structure for invite data
 type InviteResponse struct {
-	Code      string    `json:"code"`
-	GroupID   string    `json:"group_id"`
-	UsedBy    string    `json:"used_by,omitempty"`
+	Code      string           `json:"code"`
+	GroupID   string           `json:"group_id"`
+	UsedBy    string           `json:"used_by,omitempty"`
+	Redeemer  *ProfileResponse `json:"redeemer,omitempty"`
+	CreatedAt time.Time        `json:"created_at"`
+}
+
+type CreateLinkRequest struct {
+	GroupID string `json:"group_id"`
+	URL     string `json:"url"`
+	Title   string `json:"title,omitempty"`
+	Comment string `json:"comment,omitempty"`
+}
+
+type UpdateLinkCommentRequest struct {
+	Comment string `json:"comment"`
+}
+
+type CreateLinkReplyRequest struct {
+	Body string `json:"body"`
+}
+
+// LinkReplyResponse is one reply under a link, with its author's profile
+type LinkReplyResponse struct {
+	ID        string           `json:"id"`
+	LinkID    string           `json:"link_id"`
+	UserID    string           `json:"user_id"`
+	Author    *ProfileResponse `json:"author,omitempty"`
+	Body      string           `json:"body"`
+	CreatedAt time.Time        `json:"created_at"`
+}
+
+// LinkResponse is a posted link. UserID is empty for links posted by a bot,
+// such as an external feed source.
+// LinkResponse describes a posted link. FinalURL is set when URL redirects
+// somewhere else. Status is the link checker's verdict:
+// unchecked, ok, failing or dead. OpenedBy counts members who opened it
+// through ShortURL. Poster is the profile of UserID.
+type LinkResponse struct {
+	ID            string           `json:"id"`
+	GroupID       string           `json:"group_id"`
+	UserID        string           `json:"user_id,omitempty"`
+	URL           string           `json:"url"`
+	FinalURL      string           `json:"final_url,omitempty"`
+	Title         string           `json:"title,omitempty"`
+	Comment       string           `json:"comment,omitempty"`
+	Tags          []string         `json:"tags"`
+	Status        string           `json:"status"`
+	StatusCode    *int             `json:"status_code,omitempty"`
+	RedirectURL   string           `json:"redirect_url,omitempty"`
+	LastCheckedAt *time.Time       `json:"last_checked_at,omitempty"`
+	HiddenAt      *time.Time       `json:"hidden_at,omitempty"`
+	ShortURL      string           `json:"short_url,omitempty"`
+	OpenedBy      int              `json:"opened_by"`
+	Poster        *ProfileResponse `json:"poster,omitempty"`
+	CreatedAt     time.Time        `json:"created_at"`
+}
+
+// BatchLinkItem is one link in a batch. It is posted to GroupID, if set, and
+// to every group in the batch's GroupIDs.
+type BatchLinkItem struct {
+	GroupID string `json:"group_id,omitempty"`
+	URL     string `json:"url"`
+	Title   string `json:"title,omitempty"`
+	Comment string `json:"comment,omitempty"`
+}
+
+type CreateLinksBatchRequest struct {
+	Links    []BatchLinkItem `json:"links"`
+	GroupIDs []string        `json:"group_ids,omitempty"`
+}
+
+// BatchLinkResult is the outcome of posting one link to one group. Index is
+// the link's position in the request. Status is created, duplicate,
+// forbidden, blocked, rejected or failed; Link is set when created, and for
+// a duplicate is the link already posted.
+type BatchLinkResult struct {
+	Index   int           `json:"index"`
+	GroupID string        `json:"group_id"`
+	URL     string        `json:"url"`
+	Status  string        `json:"status"`
+	Link    *LinkResponse `json:"link,omitempty"`
+	Error   string        `json:"error,omitempty"`
+}
+
+type CreateLinksBatchResponse struct {
+	Created int               `json:"created"`
+	Failed  int               `json:"failed"`
+	Results []BatchLinkResult `json:"results"`
+}
+
+type SaveLinkRequest struct {
+	LinkID string  `json:"link_id"`
+	Status string  `json:"status,omitempty"`
+	Note   *string `json:"note,omitempty"`
+}
+
+type UpdateSavedLinkRequest struct {
+	Status *string `json:"status,omitempty"`
+	Note   *string `json:"note,omitempty"`
+}
+
+// SavedLinkResponse is a private reading list entry. URL and Title are a
+// snapshot taken when the link was saved, so they outlive the original link.
+type SavedLinkResponse struct {
+	ID        string    `json:"id"`
+	LinkID    string    `json:"link_id,omitempty"`
+	GroupID   string    `json:"group_id,omitempty"`
+	URL       string    `json:"url"`
+	Title     string    `json:"title,omitempty"`
+	Status    string    `json:"status"`
+	Note      string    `json:"note,omitempty"`
 	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// PageResponse wraps one page of a keyset-paginated listing
+type PageResponse struct {
+	Items      interface{} `json:"items"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+}
+
+// NotificationResponse is one entry in a user's inbox. Kind is new_link,
+// mention, invite_accepted or reply. Actor is whoever caused it, and is
+// absent for links posted by a bot.
+type NotificationResponse struct {
+	ID        string           `json:"id"`
+	Kind      string           `json:"kind"`
+	GroupID   string           `json:"group_id,omitempty"`
+	GroupName string           `json:"group_name,omitempty"`
+	LinkID    string           `json:"link_id,omitempty"`
+	LinkURL   string           `json:"link_url,omitempty"`
+	LinkTitle string           `json:"link_title,omitempty"`
+	Actor     *ProfileResponse `json:"actor,omitempty"`
+	ReadAt    *time.Time       `json:"read_at,omitempty"`
+	CreatedAt time.Time        `json:"created_at"`
+}
+
+// NotificationsPageResponse is a page of the inbox. UnreadCount covers the
+// whole inbox, not just the page.
+type NotificationsPageResponse struct {
+	Items       []NotificationResponse `json:"items"`
+	UnreadCount int64                  `json:"unread_count"`
+	NextCursor  string                 `json:"next_cursor,omitempty"`
+}
+
+// MarkNotificationsReadResponse reports how many notifications a request
+// marked read and how many remain unread
+type MarkNotificationsReadResponse struct {
+	Marked      int64 `json:"marked"`
+	UnreadCount int64 `json:"unread_count"`
+}
+
+type GroupMuteResponse struct {
+	GroupID string `json:"group_id"`
+	Muted   bool   `json:"muted"`
+}
+
+// PushKeyResponse is the VAPID public key browsers subscribe with, as
+// PushManager.subscribe's applicationServerKey
+type PushKeyResponse struct {
+	PublicKey string `json:"public_key"`
+}
+
+type PushSubscriptionKeys struct {
+	P256dh string `json:"p256dh"`
+	Auth   string `json:"auth"`
+}
+
+// CreatePushSubscriptionRequest matches PushSubscription.toJSON() in the
+// browser, so the app can post a subscription as it gets it
+type CreatePushSubscriptionRequest struct {
+	Endpoint       string               `json:"endpoint"`
+	ExpirationTime *int64               `json:"expirationTime,omitempty"`
+	Keys           PushSubscriptionKeys `json:"keys"`
+}
+
+// PushSubscriptionResponse leaves out the subscription's keys, which only
+// the server needs
+type PushSubscriptionResponse struct {
+	ID        string    `json:"id"`
+	Endpoint  string    `json:"endpoint"`
+	UserAgent string    `json:"user_agent,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type DigestSettingsRequest struct {
+	Email     string `json:"email,omitempty"`
+	Frequency string `json:"frequency"`
+	Timezone  string `json:"timezone,omitempty"`
+	SendHour  *int   `json:"send_hour,omitempty"`
+}
+
+type DigestSettingsResponse struct {
+	Email      string     `json:"email"`
+	Frequency  string     `json:"frequency"`
+	Timezone   string     `json:"timezone"`
+	SendHour   int        `json:"send_hour"`
+	LastSentAt *time.Time `json:"last_sent_at,omitempty"`
+}
+
+type CreateWebhookRequest struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+}
+
+type UpdateWebhookRequest struct {
+	URL     *string  `json:"url,omitempty"`
+	Events  []string `json:"events,omitempty"`
+	Enabled *bool    `json:"enabled,omitempty"`
+}
+
+// WebhookResponse describes a group webhook. Secret is only set in the
+// response to creation.
+type WebhookResponse struct {
+	ID           string     `json:"id"`
+	GroupID      string     `json:"group_id"`
+	URL          string     `json:"url"`
+	Events       []string   `json:"events"`
+	Enabled      bool       `json:"enabled"`
+	FailureCount int        `json:"failure_count"`
+	Secret       string     `json:"secret,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	DisabledAt   *time.Time `json:"disabled_at,omitempty"`
+}
+
+type WebhookDeliveryResponse struct {
+	ID             string          `json:"id"`
+	Event          string          `json:"event"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	ResponseStatus *int            `json:"response_status,omitempty"`
+	LastError      string          `json:"last_error,omitempty"`
+	Payload        json.RawMessage `json:"payload"`
+	CreatedAt      time.Time       `json:"created_at"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at,omitempty"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+}
+
+// FeedTokenResponse describes a feed token. Token and URLs are only returned
+// when the token is created, since only its hash is stored.
+type FeedTokenResponse struct {
+	ID         string            `json:"id"`
+	GroupID    string            `json:"group_id"`
+	Token      string            `json:"token,omitempty"`
+	URLs       map[string]string `json:"urls,omitempty"`
+	CreatedAt  time.Time         `json:"created_at"`
+	LastUsedAt *time.Time        `json:"last_used_at,omitempty"`
+}
+
+type CreateFeedSourceRequest struct {
+	URL             string `json:"url"`
+	IntervalMinutes int    `json:"interval_minutes,omitempty"`
+}
+
+// FeedSourceResponse is an external RSS or Atom feed that posts into a group
+type FeedSourceResponse struct {
+	ID              string     `json:"id"`
+	GroupID         string     `json:"group_id"`
+	URL             string     `json:"url"`
+	Title           string     `json:"title,omitempty"`
+	IntervalMinutes int        `json:"interval_minutes"`
+	LastFetchedAt   *time.Time `json:"last_fetched_at,omitempty"`
+	LastError       string     `json:"last_error,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+type ImportRowError struct {
+	Row   int    `json:"row"`
+	URL   string `json:"url,omitempty"`
+	Error string `json:"error"`
+}
+
+// ImportReport summarises an import. Duplicates are URLs already in the group
+// or repeated within the file; they are skipped rather than counted as failed.
+type ImportReport struct {
+	Format     string           `json:"format"`
+	Total      int              `json:"total"`
+	Imported   int              `json:"imported"`
+	Duplicates int              `json:"duplicates"`
+	Failed     int              `json:"failed"`
+	Errors     []ImportRowError `json:"errors"`
+}
+
+// ImportJobResponse tracks an import too large to run within one request
+type ImportJobResponse struct {
+	ID         string       `json:"id"`
+	GroupID    string       `json:"group_id"`
+	Status     string       `json:"status"`
+	Report     ImportReport `json:"report"`
+	CreatedAt  time.Time    `json:"created_at"`
+	FinishedAt *time.Time   `json:"finished_at,omitempty"`
+}
+
+// SnapshotResponse is the archived readable copy of a linked page. Status is
+// pending until the page has been fetched, and failed if it never could be.
+type SnapshotResponse struct {
+	LinkID         string     `json:"link_id"`
+	Status         string     `json:"status"`
+	Title          string     `json:"title,omitempty"`
+	HTML           string     `json:"html,omitempty"`
+	Text           string     `json:"text,omitempty"`
+	WordCount      int        `json:"word_count"`
+	ReadingMinutes int        `json:"reading_minutes"`
+	Error          string     `json:"error,omitempty"`
+	FetchedAt      *time.Time `json:"fetched_at,omitempty"`
+}
+
+type LinkClickDay struct {
+	Date   string `json:"date"`
+	Clicks int    `json:"clicks"`
+}
+
+// LinkClickStatsResponse is shown to a link's poster. Anonymous clicks came
+// through a short URL without a member signature, e.g. one shared elsewhere.
+type LinkClickStatsResponse struct {
+	LinkID          string         `json:"link_id"`
+	TotalClicks     int            `json:"total_clicks"`
+	UniqueMembers   int            `json:"unique_members"`
+	AnonymousClicks int            `json:"anonymous_clicks"`
+	LastClickedAt   *time.Time     `json:"last_clicked_at,omitempty"`
+	Days            []LinkClickDay `json:"days"`
+}
+
+// BlockDomainRequest adds a domain to a group's blocklist. A "*." prefix
+// blocks its subdomains as well.
+type BlockDomainRequest struct {
+	Domain string `json:"domain"`
+}
+
+type BlockedDomainResponse struct {
+	Domain            string    `json:"domain"`
+	IncludeSubdomains bool      `json:"include_subdomains"`
+	CreatedBy         string    `json:"created_by,omitempty"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+type ReportLinkRequest struct {
+	Reason string `json:"reason"`
+}
+
+type LinkReportResponse struct {
+	ID        string    `json:"id"`
+	LinkID    string    `json:"link_id"`
+	Reason    string    `json:"reason"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ModerationQueueItem is a link with open reports, along with every reason
+// it was reported for
+type ModerationQueueItem struct {
+	Link            LinkResponse `json:"link"`
+	ReportCount     int          `json:"report_count"`
+	Reasons         []string     `json:"reasons"`
+	FirstReportedAt time.Time    `json:"first_reported_at"`
+}
+
+type ModerateLinkRequest struct {
+	Action string `json:"action"`
+	Note   string `json:"note,omitempty"`
+}
+
+type ModerationActionResponse struct {
+	ID              string    `json:"id"`
+	LinkID          string    `json:"link_id"`
+	LinkURL         string    `json:"link_url"`
+	ModeratorID     string    `json:"moderator_id,omitempty"`
+	Action          string    `json:"action"`
+	Note            string    `json:"note,omitempty"`
+	ReportsResolved int       `json:"reports_resolved"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// ValidationError describes one way a request failed to match the OpenAPI
+// document. In is path, query, header or body; for the body, Name is a JSON
+// pointer to the offending value.
+type ValidationError struct {
+	In      string `json:"in"`
+	Name    string `json:"name,omitempty"`
+	Message string `json:"message"`
 }
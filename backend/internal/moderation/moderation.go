@@ -0,0 +1,84 @@
+package moderation
+
+import (
+	"context"
+	"errors"
+
+	supabase "github.com/egeuysall/cove/internal/supabase/generated"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Actions a moderator can take on a reported link
+const (
+	ActionHide    = "hide"
+	ActionUnhide  = "unhide"
+	ActionDelete  = "delete"
+	ActionDismiss = "dismiss"
+)
+
+// MaxReasonLength caps the free-text reason on a report
+const MaxReasonLength = 500
+
+// ErrUnknownAction is returned by Apply for anything but the actions above
+var ErrUnknownAction = errors.New("unknown moderation action")
+
+func IsValidAction(action string) bool {
+	switch action {
+	case ActionHide, ActionUnhide, ActionDelete, ActionDismiss:
+		return true
+	}
+	return false
+}
+
+// Apply carries out action on link and records it in the group's moderation
+// log, in one transaction. Every action except unhide closes the link's open
+// reports, and deleting removes them along with the link.
+func Apply(ctx context.Context, db *pgxpool.Pool, link supabase.Link, moderatorID pgtype.UUID, action string, note pgtype.Text) (supabase.ModerationAction, error) {
+	if !IsValidAction(action) {
+		return supabase.ModerationAction{}, ErrUnknownAction
+	}
+
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return supabase.ModerationAction{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	queries := supabase.New(tx)
+
+	var resolved int64
+	if action != ActionUnhide {
+		resolved, err = queries.ResolveLinkReports(ctx, link.ID)
+		if err != nil {
+			return supabase.ModerationAction{}, err
+		}
+	}
+
+	switch action {
+	case ActionHide:
+		err = queries.HideLink(ctx, supabase.HideLinkParams{ID: link.ID, HiddenBy: moderatorID})
+	case ActionUnhide:
+		err = queries.UnhideLink(ctx, link.ID)
+	case ActionDelete:
+		err = queries.DeleteGroupLink(ctx, supabase.DeleteGroupLinkParams{ID: link.ID, GroupID: link.GroupID})
+	}
+	if err != nil {
+		return supabase.ModerationAction{}, err
+	}
+
+	logged, err := queries.CreateModerationAction(ctx, supabase.CreateModerationActionParams{
+		GroupID:         link.GroupID,
+		LinkID:          link.ID,
+		LinkUrl:         link.Url,
+		ModeratorID:     moderatorID,
+		Action:          action,
+		Note:            note,
+		ReportsResolved: int32(resolved),
+	})
+	if err != nil {
+		return supabase.ModerationAction{}, err
+	}
+
+	return logged, tx.Commit(ctx)
+}
@@ -0,0 +1,105 @@
+// Package netguard builds HTTP clients that refuse to connect to private,
+// loopback and other internal addresses, so user-supplied URLs cannot be
+// used to reach the server's own network.
+package netguard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"syscall"
+	"time"
+)
+
+var ErrPrivateAddress = errors.New("refusing to connect to a private or loopback address")
+
+// Ranges that are not globally routable but are not covered by the netip
+// helpers used in IsPublic
+var reserved = []netip.Prefix{
+	netip.MustParsePrefix("100.64.0.0/10"), // carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),  // IETF protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"), // benchmarking
+	netip.MustParsePrefix("240.0.0.0/4"),   // reserved
+	netip.MustParsePrefix("64:ff9b::/96"),  // NAT64, can map to private IPv4
+}
+
+// IsPublic reports whether addr is a globally routable unicast address
+func IsPublic(addr netip.Addr) bool {
+	addr = addr.Unmap()
+
+	if !addr.IsValid() || addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsMulticast() {
+		return false
+	}
+
+	for _, prefix := range reserved {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckHost rejects hosts that are literal non-public IPs or well-known
+// local names. Hostnames are checked again at connect time, after DNS.
+func CheckHost(host string) error {
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return ErrPrivateAddress
+	}
+
+	addr, err := netip.ParseAddr(host)
+	if err == nil && !IsPublic(addr) {
+		return ErrPrivateAddress
+	}
+	return nil
+}
+
+// control runs after DNS resolution, on the exact address being dialled, so
+// a hostname that resolves (or later rebinds) to an internal IP is caught
+func control(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("netguard: %w", err)
+	}
+	if !IsPublic(addrPort.Addr()) {
+		return ErrPrivateAddress
+	}
+	return nil
+}
+
+// Transport returns a transport whose every connection, including those made
+// while following redirects, is checked by control. Proxies from the
+// environment are ignored since they would bypass the check.
+func Transport() *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   10 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   control,
+	}
+
+	return &http.Transport{
+		Proxy: nil,
+		DialContext: func(ctx context.Context, network, address string) (net.Conn, error) {
+			return dialer.DialContext(ctx, network, address)
+		},
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          50,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: time.Second,
+	}
+}
+
+// Client returns a guarded client with the given overall timeout. Workers
+// that fetch user-supplied URLs use it when no client is passed in.
+func Client(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Transport: Transport(),
+		Timeout:   timeout,
+	}
+}
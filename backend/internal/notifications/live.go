@@ -0,0 +1,67 @@
+package notifications
+
+import (
+	"sync"
+
+	supabase "github.com/egeuysall/cove/internal/supabase/generated"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// streamBuffer is how far a stream can fall behind before it is closed
+const streamBuffer = 16
+
+// live holds the open streams of each user connected to this process
+var live = struct {
+	mu      sync.Mutex
+	streams map[pgtype.UUID]map[chan supabase.Notification]struct{}
+}{streams: map[pgtype.UUID]map[chan supabase.Notification]struct{}{}}
+
+// Subscribe opens a stream of userId's notifications as they are created.
+// Only notifications created by this process reach it. A stream that falls
+// too far behind is closed rather than skipping any, so its reader can catch
+// up from the inbox. Calling the returned function closes the stream.
+func Subscribe(userId pgtype.UUID) (<-chan supabase.Notification, func()) {
+	stream := make(chan supabase.Notification, streamBuffer)
+
+	live.mu.Lock()
+	if live.streams[userId] == nil {
+		live.streams[userId] = map[chan supabase.Notification]struct{}{}
+	}
+	live.streams[userId][stream] = struct{}{}
+	live.mu.Unlock()
+
+	return stream, func() {
+		live.mu.Lock()
+		defer live.mu.Unlock()
+		unsubscribe(userId, stream)
+	}
+}
+
+// unsubscribe removes and closes a stream unless that already happened.
+// live.mu must be held.
+func unsubscribe(userId pgtype.UUID, stream chan supabase.Notification) {
+	streams := live.streams[userId]
+	if _, ok := streams[stream]; !ok {
+		return
+	}
+
+	delete(streams, stream)
+	if len(streams) == 0 {
+		delete(live.streams, userId)
+	}
+	close(stream)
+}
+
+// publish passes a new notification to its recipient's open streams
+func publish(notification supabase.Notification) {
+	live.mu.Lock()
+	defer live.mu.Unlock()
+
+	for stream := range live.streams[notification.UserID] {
+		select {
+		case stream <- notification:
+		default:
+			unsubscribe(notification.UserID, stream)
+		}
+	}
+}
@@ -0,0 +1,304 @@
+// Package notifications fills each user's in-app inbox, hands each new
+// notification to the recipient's open live streams and, for users who
+// subscribed a browser or phone, queues a Web Push message to match.
+// Notifications are written alongside the change that causes them and, like
+// webhooks, never fail the request that caused them.
+package notifications
+
+import (
+	"context"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	supabase "github.com/egeuysall/cove/internal/supabase/generated"
+	"github.com/egeuysall/cove/internal/utils"
+	"github.com/egeuysall/cove/internal/webpush"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Kinds of notification
+const (
+	KindNewLink        = "new_link"
+	KindMention        = "mention"
+	KindInviteAccepted = "invite_accepted"
+	KindReply          = "reply"
+)
+
+// mentionPattern finds @name where the @ does not follow a word character,
+// so email addresses in a comment are not mentions
+var mentionPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_@])@([\p{L}\p{N}_.\-]+)`)
+
+// Mentions returns the names mentioned in text, without their @ and in the
+// order they appear. A mention is @ followed by someone's display name with
+// its spaces left out, so "Ada Lovelace" is @AdaLovelace.
+func Mentions(text string) []string {
+	var names []string
+	for _, match := range mentionPattern.FindAllStringSubmatch(text, -1) {
+		name := strings.TrimRight(match[1], ".-")
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// mentionHandle is how a display name is written in a mention
+func mentionHandle(displayName string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, displayName)
+}
+
+// LinkPosted tells a link's group about it. Members mentioned in the comment
+// get a mention, even in a group they muted; every other member who has not
+// muted the group gets a new_link. The poster hears about neither.
+func LinkPosted(ctx context.Context, queries *supabase.Queries, link supabase.Link) {
+	mentioned := mentionedMembers(ctx, queries, link.GroupID, link.Comment.String, link.UserID)
+
+	for _, userId := range mentioned {
+		err := create(ctx, queries, supabase.CreateNotificationParams{
+			UserID:  userId,
+			Kind:    KindMention,
+			GroupID: link.GroupID,
+			LinkID:  link.ID,
+			ActorID: link.UserID,
+		})
+		if err != nil {
+			log.Printf("notifications: failed to notify mention on %s: %v", utils.UUIDToString(link.ID), err)
+		}
+	}
+
+	created, err := queries.CreateLinkNotifications(ctx, supabase.CreateLinkNotificationsParams{
+		LinkID:      link.ID,
+		ActorID:     link.UserID,
+		GroupID:     link.GroupID,
+		SkipUserIds: mentioned,
+	})
+	if err != nil {
+		log.Printf("notifications: failed to notify group of %s: %v", utils.UUIDToString(link.ID), err)
+	}
+
+	recipients := make([]pgtype.UUID, 0, len(created))
+	for _, notification := range created {
+		publish(notification)
+		recipients = append(recipients, notification.UserID)
+	}
+
+	if len(mentioned) == 0 && len(recipients) == 0 || !webpush.Enabled() {
+		return
+	}
+
+	groupName, actorName := names(ctx, queries, link.GroupID, link.UserID)
+	message := webpush.Message{
+		URL: "/groups/" + utils.UUIDToString(link.GroupID),
+		Tag: "link-" + utils.UUIDToString(link.ID),
+	}
+
+	mention := message
+	mention.Title = actorName + " mentioned you in " + groupName
+	mention.Body = link.Comment.String
+	webpush.Enqueue(ctx, queries, mentioned, mention)
+
+	posted := message
+	posted.Title = groupName
+	posted.Body = actorName + " shared " + linkLabel(link)
+	webpush.Enqueue(ctx, queries, recipients, posted)
+}
+
+// Replied tells a link's poster about a reply to it. Members mentioned in the
+// reply get a mention instead, unless the link is hidden from them. Nobody
+// hears about their own reply.
+func Replied(ctx context.Context, queries *supabase.Queries, link supabase.Link, reply supabase.LinkReply) {
+	var mentioned []pgtype.UUID
+	if !link.HiddenAt.Valid {
+		for _, userId := range mentionedMembers(ctx, queries, link.GroupID, reply.Body, reply.UserID) {
+			if userId != link.UserID {
+				mentioned = append(mentioned, userId)
+			}
+		}
+	}
+
+	for _, userId := range mentioned {
+		err := create(ctx, queries, supabase.CreateNotificationParams{
+			UserID:  userId,
+			Kind:    KindMention,
+			GroupID: link.GroupID,
+			LinkID:  link.ID,
+			ActorID: reply.UserID,
+		})
+		if err != nil {
+			log.Printf("notifications: failed to notify mention in reply %s: %v", utils.UUIDToString(reply.ID), err)
+		}
+	}
+
+	notifyPoster := link.UserID.Valid && link.UserID != reply.UserID
+	if notifyPoster {
+		err := create(ctx, queries, supabase.CreateNotificationParams{
+			UserID:  link.UserID,
+			Kind:    KindReply,
+			GroupID: link.GroupID,
+			LinkID:  link.ID,
+			ActorID: reply.UserID,
+		})
+		if err != nil {
+			log.Printf("notifications: failed to notify reply %s: %v", utils.UUIDToString(reply.ID), err)
+		}
+	}
+
+	if !notifyPoster && len(mentioned) == 0 || !webpush.Enabled() {
+		return
+	}
+
+	_, actorName := names(ctx, queries, link.GroupID, reply.UserID)
+	message := webpush.Message{
+		Body: reply.Body,
+		URL:  "/groups/" + utils.UUIDToString(link.GroupID),
+		Tag:  "reply-" + utils.UUIDToString(reply.ID),
+	}
+
+	if notifyPoster {
+		replied := message
+		replied.Title = actorName + " replied to " + linkLabel(link)
+		webpush.Enqueue(ctx, queries, []pgtype.UUID{link.UserID}, replied)
+	}
+
+	mention := message
+	mention.Title = actorName + " mentioned you in a reply"
+	webpush.Enqueue(ctx, queries, mentioned, mention)
+}
+
+// create stores a notification and passes it on to the recipient's streams
+func create(ctx context.Context, queries *supabase.Queries, params supabase.CreateNotificationParams) error {
+	notification, err := queries.CreateNotification(ctx, params)
+	if err != nil {
+		return err
+	}
+	publish(notification)
+	return nil
+}
+
+// linkLabel is how a push message refers to a link
+func linkLabel(link supabase.Link) string {
+	if link.Title.Valid && link.Title.String != "" {
+		return link.Title.String
+	}
+	return link.Url
+}
+
+// names looks up the group and actor named in a push message. Lookups are
+// best effort and fall back to generic names.
+func names(ctx context.Context, queries *supabase.Queries, groupId, actorId pgtype.UUID) (string, string) {
+	groupName, actorName := "Cove", "Someone"
+
+	group, err := queries.GetGroupByID(ctx, groupId)
+	if err == nil {
+		groupName = group.Name
+	}
+
+	profile, err := queries.GetProfile(ctx, actorId)
+	if err == nil && profile.DisplayName.Valid {
+		actorName = profile.DisplayName.String
+	}
+
+	return groupName, actorName
+}
+
+// mentionedMembers resolves the mentions in text written by authorId to the
+// members of groupId they name. The result is never nil: it is passed to a
+// NOT ANY filter, which a NULL array would turn against every row.
+func mentionedMembers(ctx context.Context, queries *supabase.Queries, groupId pgtype.UUID, text string, authorId pgtype.UUID) []pgtype.UUID {
+	names := Mentions(text)
+	if len(names) == 0 {
+		return []pgtype.UUID{}
+	}
+
+	members, err := queries.GetGroupMemberProfiles(ctx, groupId)
+	if err != nil {
+		log.Printf("notifications: failed to resolve mentions in %s: %v", utils.UUIDToString(groupId), err)
+		return []pgtype.UUID{}
+	}
+
+	mentioned := []pgtype.UUID{}
+	for _, member := range members {
+		if member.UserID == authorId || !member.DisplayName.Valid {
+			continue
+		}
+		handle := mentionHandle(member.DisplayName.String)
+		for _, name := range names {
+			if strings.EqualFold(name, handle) {
+				mentioned = append(mentioned, member.UserID)
+				break
+			}
+		}
+	}
+	return mentioned
+}
+
+// InviteAccepted tells whoever made an invite that userId joined with it
+func InviteAccepted(ctx context.Context, queries *supabase.Queries, invite supabase.Invite, userId pgtype.UUID) {
+	if !invite.CreatedBy.Valid || invite.CreatedBy == userId {
+		return
+	}
+
+	err := create(ctx, queries, supabase.CreateNotificationParams{
+		UserID:  invite.CreatedBy,
+		Kind:    KindInviteAccepted,
+		GroupID: invite.GroupID,
+		ActorID: userId,
+	})
+	if err != nil {
+		log.Printf("notifications: failed to notify accepted invite %s: %v", invite.Code, err)
+		return
+	}
+
+	if !webpush.Enabled() {
+		return
+	}
+
+	groupName, actorName := names(ctx, queries, invite.GroupID, userId)
+	webpush.Enqueue(ctx, queries, []pgtype.UUID{invite.CreatedBy}, webpush.Message{
+		Title: groupName,
+		Body:  actorName + " joined with your invite",
+		URL:   "/groups/" + utils.UUIDToString(invite.GroupID),
+	})
+}
+
+// Pruner deletes notifications once they are too old to be worth showing
+type Pruner struct {
+	queries  *supabase.Queries
+	interval time.Duration
+	maxAge   time.Duration
+}
+
+func NewPruner(queries *supabase.Queries) *Pruner {
+	return &Pruner{
+		queries:  queries,
+		interval: time.Hour,
+		maxAge:   90 * 24 * time.Hour,
+	}
+}
+
+// Start prunes until ctx is cancelled
+func (p *Pruner) Start(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			before := pgtype.Timestamptz{Time: time.Now().Add(-p.maxAge), Valid: true}
+			_, err := p.queries.DeleteNotificationsBefore(ctx, before)
+			if err != nil {
+				log.Printf("notifications: failed to prune: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,104 @@
+// Package openapi holds the API's OpenAPI 3 document. It is served at
+// /openapi.json, enforced on incoming requests by middleware, and checked
+// against the router at startup so the two cannot drift apart.
+package openapi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+//go:embed openapi.yaml
+var source []byte
+
+// Doc is the parsed and validated document
+var Doc = mustLoad()
+
+var docJSON []byte
+
+func mustLoad() *openapi3.T {
+	// Every ID in the API is a UUID; kin-openapi leaves the format unchecked
+	// unless told otherwise
+	openapi3.DefineStringFormatCallback("uuid", func(s string) error {
+		if _, err := uuid.Parse(s); err != nil {
+			return errors.New("must be a UUID")
+		}
+		return nil
+	})
+
+	doc, err := openapi3.NewLoader().LoadFromData(source)
+	if err != nil {
+		panic(fmt.Sprintf("openapi: parsing openapi.yaml: %v", err))
+	}
+	if err := doc.Validate(openapi3.NewLoader().Context); err != nil {
+		panic(fmt.Sprintf("openapi: invalid openapi.yaml: %v", err))
+	}
+
+	docJSON, err = json.Marshal(doc)
+	if err != nil {
+		panic(fmt.Sprintf("openapi: encoding document: %v", err))
+	}
+
+	return doc
+}
+
+// HandleSpec serves the document as JSON
+func HandleSpec(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	w.WriteHeader(http.StatusOK)
+	w.Write(docJSON)
+}
+
+// CheckRoutes compares the routes registered on the router with the
+// operations in the document and returns an error listing every route that
+// is missing from either side.
+func CheckRoutes(routes chi.Routes) error {
+	registered := map[string]bool{}
+	err := chi.Walk(routes, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		// Sub-routers report their root with a trailing slash
+		if len(route) > 1 {
+			route = strings.TrimSuffix(route, "/")
+		}
+		registered[method+" "+route] = true
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("walking routes: %w", err)
+	}
+
+	documented := map[string]bool{}
+	for path, item := range Doc.Paths.Map() {
+		for method := range item.Operations() {
+			documented[method+" "+path] = true
+		}
+	}
+
+	var problems []string
+	for route := range registered {
+		if !documented[route] {
+			problems = append(problems, route+" is routed but not documented")
+		}
+	}
+	for route := range documented {
+		if !registered[route] {
+			problems = append(problems, route+" is documented but not routed")
+		}
+	}
+
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("router and OpenAPI document disagree:\n  %s", strings.Join(problems, "\n  "))
+	}
+
+	return nil
+}
@@ -0,0 +1,119 @@
+package profiles
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/egeuysall/cove/internal/models"
+	supabase "github.com/egeuysall/cove/internal/supabase/generated"
+	"github.com/egeuysall/cove/internal/utils"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Limits on a profile's fields
+const (
+	MaxDisplayNameLength = 50
+	MaxBioLength         = 500
+)
+
+func ToResponse(profile supabase.Profile) models.ProfileResponse {
+	return models.ProfileResponse{
+		UserID:      utils.UUIDToString(profile.UserID),
+		DisplayName: profile.DisplayName.String,
+		AvatarURL:   profile.AvatarUrl.String,
+		Bio:         profile.Bio.String,
+		Timezone:    profile.Timezone.String,
+	}
+}
+
+// Visible looks up the profiles of userIds that viewer may see, in one
+// query. Every ID gets an entry; the ones without a visible profile carry
+// only their user ID.
+func Visible(ctx context.Context, queries *supabase.Queries, viewer pgtype.UUID, userIds []pgtype.UUID) (map[pgtype.UUID]models.ProfileResponse, error) {
+	found := make(map[pgtype.UUID]models.ProfileResponse, len(userIds))
+	for _, id := range userIds {
+		found[id] = models.ProfileResponse{UserID: utils.UUIDToString(id)}
+	}
+
+	if len(userIds) == 0 {
+		return found, nil
+	}
+
+	visible, err := queries.GetVisibleProfiles(ctx, supabase.GetVisibleProfilesParams{
+		UserIds:  userIds,
+		ViewerID: viewer,
+	})
+	if err != nil {
+		return found, err
+	}
+
+	for _, profile := range visible {
+		found[profile.UserID] = ToResponse(profile)
+	}
+	return found, nil
+}
+
+// ApplyPatch applies a JSON Merge Patch (RFC 7396) to profile. A null member
+// clears the field. The returned error is safe to show to the client.
+func ApplyPatch(profile supabase.Profile, patch []byte) (supabase.UpsertProfileParams, error) {
+	params := supabase.UpsertProfileParams{
+		UserID:      profile.UserID,
+		DisplayName: profile.DisplayName,
+		AvatarUrl:   profile.AvatarUrl,
+		Bio:         profile.Bio,
+		Timezone:    profile.Timezone,
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(patch, &fields); err != nil || fields == nil {
+		return params, errors.New("The patch must be a JSON object")
+	}
+
+	for key, value := range fields {
+		var err error
+		switch key {
+		case "display_name":
+			params.DisplayName, err = utils.PatchText(value)
+		case "avatar_url":
+			params.AvatarUrl, err = utils.PatchText(value)
+		case "bio":
+			params.Bio, err = utils.PatchText(value)
+		case "timezone":
+			params.Timezone, err = utils.PatchText(value)
+		default:
+			return params, fmt.Errorf("Unknown field %q", key)
+		}
+		if err != nil {
+			return params, fmt.Errorf("Invalid %s: %w", key, err)
+		}
+	}
+
+	if utf8.RuneCountInString(params.DisplayName.String) > MaxDisplayNameLength {
+		return params, fmt.Errorf("Display name must be at most %d characters", MaxDisplayNameLength)
+	}
+
+	if strings.ContainsAny(params.DisplayName.String, "\r\n") {
+		return params, errors.New("Display name must be a single line")
+	}
+
+	if params.AvatarUrl.Valid && !utils.IsAvatarURL(params.AvatarUrl.String) {
+		return params, errors.New("Avatar URL must be an https URL")
+	}
+
+	if utf8.RuneCountInString(params.Bio.String) > MaxBioLength {
+		return params, fmt.Errorf("Bio must be at most %d characters", MaxBioLength)
+	}
+
+	if params.Timezone.Valid {
+		if _, err := time.LoadLocation(params.Timezone.String); err != nil {
+			return params, errors.New("Invalid timezone")
+		}
+	}
+
+	return params, nil
+}
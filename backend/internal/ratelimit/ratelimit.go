@@ -0,0 +1,123 @@
+package ratelimit
+
+import (
+	"context"
+	"log"
+	"math"
+	"strconv"
+	"time"
+
+	supabase "github.com/egeuysall/cove/internal/supabase/generated"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Policy is a token bucket: Limit requests may burst at once, and the bucket
+// refills at Limit per Window. Buckets are keyed by policy name, so each
+// policy is a separate budget.
+type Policy struct {
+	Name   string
+	Limit  int
+	Window time.Duration
+}
+
+// Budgets used by the router
+var (
+	// Public covers unauthenticated routes, keyed by client IP
+	Public = Policy{Name: "public", Limit: 60, Window: time.Minute}
+	// Read covers GET requests to the API
+	Read = Policy{Name: "read", Limit: 300, Window: time.Minute}
+	// Write covers every other API method
+	Write = Policy{Name: "write", Limit: 60, Window: time.Minute}
+	// CreateLink applies on top of Write, since each new link also costs
+	// an expansion, a snapshot and webhook deliveries
+	CreateLink = Policy{Name: "create-link", Limit: 20, Window: time.Minute}
+	// Import applies on top of Write to bulk imports
+	Import = Policy{Name: "import", Limit: 10, Window: time.Hour}
+)
+
+func (p Policy) refillPerSecond() float64 {
+	return float64(p.Limit) / p.Window.Seconds()
+}
+
+// Header is the RateLimit-Policy value for p, e.g. "60;w=60"
+func (p Policy) Header() string {
+	return strconv.Itoa(p.Limit) + ";w=" + strconv.Itoa(int(p.Window.Seconds()))
+}
+
+// Result describes a bucket after a request was counted against it
+type Result struct {
+	Allowed   bool
+	Remaining int
+	// Reset is how long until the bucket is full again
+	Reset time.Duration
+	// RetryAfter is how long until the next token, when not Allowed
+	RetryAfter time.Duration
+}
+
+// Take counts one request against subject's bucket for policy. The bucket
+// lives in Postgres so every instance shares it; the upsert locks the row,
+// which makes refill-and-take atomic.
+func Take(ctx context.Context, queries *supabase.Queries, policy Policy, subject string) (Result, error) {
+	row, err := queries.TakeRateLimitToken(ctx, supabase.TakeRateLimitTokenParams{
+		Key:             policy.Name + ":" + subject,
+		Capacity:        float64(policy.Limit),
+		RefillPerSecond: policy.refillPerSecond(),
+	})
+	if err != nil {
+		return Result{}, err
+	}
+
+	rate := policy.refillPerSecond()
+	tokens := math.Max(row.Tokens, 0)
+
+	result := Result{
+		Allowed:   row.Allowed,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     seconds((float64(policy.Limit) - tokens) / rate),
+	}
+	if !row.Allowed {
+		result.RetryAfter = seconds((1 - tokens) / rate)
+	}
+	return result, nil
+}
+
+// seconds rounds up to whole seconds, which is what the headers carry
+func seconds(s float64) time.Duration {
+	return time.Duration(math.Ceil(s)) * time.Second
+}
+
+// Pruner deletes buckets that have been idle long enough to be full again
+type Pruner struct {
+	queries  *supabase.Queries
+	interval time.Duration
+	idle     time.Duration
+}
+
+func NewPruner(queries *supabase.Queries) *Pruner {
+	return &Pruner{
+		queries:  queries,
+		interval: time.Hour,
+		// Longer than the longest policy window, so a pruned bucket would
+		// have refilled anyway
+		idle: 2 * time.Hour,
+	}
+}
+
+// Start prunes until ctx is cancelled
+func (p *Pruner) Start(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			before := pgtype.Timestamptz{Time: time.Now().Add(-p.idle), Valid: true}
+			_, err := p.queries.DeleteIdleRateLimitBuckets(ctx, before)
+			if err != nil {
+				log.Printf("ratelimit: failed to prune buckets: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,420 @@
+// Package snapshot archives a readable copy of each linked page so its text
+// survives link rot and paywall changes.
+package snapshot
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// wordsPerMinute is the average adult reading speed used for estimates
+const wordsPerMinute = 238
+
+var ErrNoContent = errors.New("no readable content found")
+
+// Article is the extracted body of a page
+type Article struct {
+	Title          string
+	HTML           string
+	Text           string
+	WordCount      int
+	ReadingMinutes int
+}
+
+var (
+	positiveHint = regexp.MustCompile(`(?i)article|body|content|entry|main|page|post|story|text`)
+	negativeHint = regexp.MustCompile(`(?i)ad-|banner|comment|combx|contact|footer|footnote|masthead|menu|meta|nav|promo|related|share|sidebar|social|sponsor|subscribe|widget`)
+)
+
+// Elements removed before scoring, along with everything inside them
+var droppedElements = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Noscript: true, atom.Iframe: true,
+	atom.Form: true, atom.Button: true, atom.Input: true, atom.Select: true,
+	atom.Textarea: true, atom.Svg: true, atom.Canvas: true, atom.Nav: true,
+	atom.Header: true, atom.Footer: true, atom.Aside: true, atom.Object: true,
+	atom.Embed: true, atom.Template: true,
+}
+
+// Elements kept in the sanitized output. Anything else is unwrapped, keeping
+// its children.
+var allowedElements = map[atom.Atom]bool{
+	atom.P: true, atom.Br: true, atom.Hr: true, atom.H1: true, atom.H2: true,
+	atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true, atom.Ul: true,
+	atom.Ol: true, atom.Li: true, atom.Blockquote: true, atom.Pre: true,
+	atom.Code: true, atom.Em: true, atom.Strong: true, atom.B: true, atom.I: true,
+	atom.A: true, atom.Img: true, atom.Figure: true, atom.Figcaption: true,
+	atom.Table: true, atom.Thead: true, atom.Tbody: true, atom.Tr: true,
+	atom.Th: true, atom.Td: true, atom.Sup: true, atom.Sub: true,
+}
+
+// Elements that end a line in the plain-text rendering
+var blockElements = map[atom.Atom]bool{
+	atom.P: true, atom.Br: true, atom.Hr: true, atom.H1: true, atom.H2: true,
+	atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true, atom.Li: true,
+	atom.Blockquote: true, atom.Pre: true, atom.Figure: true, atom.Tr: true,
+	atom.Div: true, atom.Section: true, atom.Article: true,
+}
+
+// Extract finds the main content of an HTML page the way Readability does:
+// paragraphs score their ancestors by length and punctuation, class and id
+// names nudge the score, and the best-scoring container wins. Relative links
+// and images are resolved against pageURL.
+func Extract(r io.Reader, pageURL string) (Article, error) {
+	doc, err := html.Parse(r)
+	if err != nil {
+		return Article{}, err
+	}
+
+	base, _ := url.Parse(pageURL)
+
+	title := documentTitle(doc)
+	removeDropped(doc)
+
+	root := bestCandidate(doc)
+	if root == nil {
+		return Article{}, ErrNoContent
+	}
+
+	var content bytes.Buffer
+	for child := root.FirstChild; child != nil; child = child.NextSibling {
+		sanitize(&content, child, base)
+	}
+
+	var text strings.Builder
+	plainText(&text, root)
+	cleaned := collapseBlankLines(text.String())
+
+	words := len(strings.Fields(cleaned))
+	if words == 0 {
+		return Article{}, ErrNoContent
+	}
+
+	return Article{
+		Title:          title,
+		HTML:           strings.TrimSpace(content.String()),
+		Text:           cleaned,
+		WordCount:      words,
+		ReadingMinutes: ReadingMinutes(words),
+	}, nil
+}
+
+// ReadingMinutes estimates reading time, rounding up to at least a minute
+func ReadingMinutes(words int) int {
+	if words <= 0 {
+		return 0
+	}
+	return int(math.Ceil(float64(words) / wordsPerMinute))
+}
+
+func documentTitle(doc *html.Node) string {
+	var ogTitle, title string
+
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			switch n.DataAtom {
+			case atom.Title:
+				if title == "" {
+					title = strings.TrimSpace(textOf(n))
+				}
+			case atom.Meta:
+				if attr(n, "property") == "og:title" {
+					ogTitle = strings.TrimSpace(attr(n, "content"))
+				}
+			}
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(doc)
+
+	if ogTitle != "" {
+		return ogTitle
+	}
+	return title
+}
+
+func removeDropped(n *html.Node) {
+	for child := n.FirstChild; child != nil; {
+		next := child.NextSibling
+		if child.Type == html.CommentNode ||
+			(child.Type == html.ElementNode && (droppedElements[child.DataAtom] || isHidden(child))) {
+			n.RemoveChild(child)
+		} else {
+			removeDropped(child)
+		}
+		child = next
+	}
+}
+
+func isHidden(n *html.Node) bool {
+	if _, ok := attrOK(n, "hidden"); ok {
+		return true
+	}
+	style := strings.ReplaceAll(attr(n, "style"), " ", "")
+	return strings.Contains(style, "display:none") || attr(n, "aria-hidden") == "true"
+}
+
+// bestCandidate scores every paragraph's parent and grandparent and returns
+// the highest scoring node, falling back to <body>
+func bestCandidate(doc *html.Node) *html.Node {
+	scores := map[*html.Node]float64{}
+	var body *html.Node
+
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			if n.DataAtom == atom.Body {
+				body = n
+			}
+			if n.DataAtom == atom.P || n.DataAtom == atom.Pre || n.DataAtom == atom.Td {
+				scoreParagraph(n, scores)
+			}
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(doc)
+
+	var best *html.Node
+	var bestScore float64
+	for node, score := range scores {
+		// Dense link lists such as menus score lower
+		score *= 1 - linkDensity(node)
+		if best == nil || score > bestScore {
+			best, bestScore = node, score
+		}
+	}
+
+	if best == nil {
+		return body
+	}
+	return best
+}
+
+func scoreParagraph(p *html.Node, scores map[*html.Node]float64) {
+	text := strings.TrimSpace(textOf(p))
+	if len(text) < 25 {
+		return
+	}
+
+	score := 1 + float64(strings.Count(text, ",")) + math.Min(float64(len(text))/100, 3)
+
+	parent := p.Parent
+	if parent == nil || parent.Type != html.ElementNode {
+		return
+	}
+	if _, seen := scores[parent]; !seen {
+		scores[parent] = classWeight(parent)
+	}
+	scores[parent] += score
+
+	grandparent := parent.Parent
+	if grandparent != nil && grandparent.Type == html.ElementNode {
+		if _, seen := scores[grandparent]; !seen {
+			scores[grandparent] = classWeight(grandparent)
+		}
+		scores[grandparent] += score / 2
+	}
+}
+
+func classWeight(n *html.Node) float64 {
+	weight := 0.0
+	if n.DataAtom == atom.Article || n.DataAtom == atom.Main {
+		weight += 25
+	}
+	for _, hint := range []string{attr(n, "class"), attr(n, "id")} {
+		if hint == "" {
+			continue
+		}
+		if negativeHint.MatchString(hint) {
+			weight -= 25
+		}
+		if positiveHint.MatchString(hint) {
+			weight += 25
+		}
+	}
+	return weight
+}
+
+func linkDensity(n *html.Node) float64 {
+	total := len(textOf(n))
+	if total == 0 {
+		return 0
+	}
+
+	linked := 0
+	var walk func(*html.Node)
+	walk = func(c *html.Node) {
+		if c.Type == html.ElementNode && c.DataAtom == atom.A {
+			linked += len(textOf(c))
+			return
+		}
+		for child := c.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(n)
+
+	return float64(linked) / float64(total)
+}
+
+// sanitize writes n with only allowed elements and attributes. Links must be
+// http(s) and open in a new context; images keep only src and alt.
+func sanitize(w *bytes.Buffer, n *html.Node, base *url.URL) {
+	switch n.Type {
+	case html.TextNode:
+		w.WriteString(html.EscapeString(n.Data))
+		return
+	case html.ElementNode:
+	default:
+		return
+	}
+
+	if !allowedElements[n.DataAtom] {
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			sanitize(w, child, base)
+		}
+		return
+	}
+
+	tag := n.DataAtom.String()
+	w.WriteString("<" + tag)
+
+	switch n.DataAtom {
+	case atom.A:
+		if href := resolve(base, attr(n, "href")); href != "" {
+			w.WriteString(` href="` + html.EscapeString(href) + `" rel="nofollow noopener noreferrer"`)
+		}
+	case atom.Img:
+		src := resolve(base, attr(n, "src"))
+		if src == "" {
+			// Lazy-loading pages often keep the real source elsewhere
+			src = resolve(base, attr(n, "data-src"))
+		}
+		if src == "" {
+			w.Truncate(w.Len() - len(tag) - 1)
+			return
+		}
+		w.WriteString(` src="` + html.EscapeString(src) + `"`)
+		if alt := attr(n, "alt"); alt != "" {
+			w.WriteString(` alt="` + html.EscapeString(alt) + `"`)
+		}
+	}
+
+	if n.DataAtom == atom.Br || n.DataAtom == atom.Hr || n.DataAtom == atom.Img {
+		w.WriteString(">")
+		return
+	}
+	w.WriteString(">")
+
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		sanitize(w, child, base)
+	}
+
+	w.WriteString("</" + tag + ">")
+}
+
+func plainText(w *strings.Builder, n *html.Node) {
+	if n.Type == html.TextNode {
+		// Collapse runs of whitespace but keep a single space at either end so
+		// words in adjacent inline elements stay apart
+		collapsed := strings.Join(strings.Fields(n.Data), " ")
+		if collapsed == "" {
+			if n.Data != "" {
+				w.WriteString(" ")
+			}
+			return
+		}
+		if strings.TrimLeft(n.Data, " \t\r\n") != n.Data {
+			w.WriteString(" ")
+		}
+		w.WriteString(collapsed)
+		if strings.TrimRight(n.Data, " \t\r\n") != n.Data {
+			w.WriteString(" ")
+		}
+		return
+	}
+
+	if n.Type == html.ElementNode && n.DataAtom == atom.Img {
+		return
+	}
+
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		plainText(w, child)
+	}
+
+	if n.Type == html.ElementNode && blockElements[n.DataAtom] {
+		w.WriteString("\n\n")
+	}
+}
+
+func collapseBlankLines(text string) string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n\n")
+}
+
+func resolve(base *url.URL, raw string) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return ""
+	}
+
+	ref, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	if base != nil {
+		ref = base.ResolveReference(ref)
+	}
+
+	if ref.Scheme != "http" && ref.Scheme != "https" {
+		return ""
+	}
+	return ref.String()
+}
+
+func textOf(n *html.Node) string {
+	var b strings.Builder
+	var walk func(*html.Node)
+	walk = func(c *html.Node) {
+		if c.Type == html.TextNode {
+			b.WriteString(c.Data)
+		}
+		for child := c.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(n)
+	return b.String()
+}
+
+func attr(n *html.Node, key string) string {
+	value, _ := attrOK(n, key)
+	return value
+}
+
+func attrOK(n *html.Node, key string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Namespace == "" && a.Key == key {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,146 @@
+package snapshot
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/egeuysall/cove/internal/netguard"
+	supabase "github.com/egeuysall/cove/internal/supabase/generated"
+	"github.com/egeuysall/cove/internal/utils"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	// MaxAttempts is how many times a page is fetched before the snapshot is
+	// marked failed
+	MaxAttempts  = 3
+	maxPageBytes = 5 << 20
+	batchSize    = 10
+	retryDelay   = 15 * time.Minute
+)
+
+// Archiver fetches pages for pending snapshots and stores their readable text
+type Archiver struct {
+	queries  *supabase.Queries
+	client   *http.Client
+	interval time.Duration
+}
+
+// NewArchiver builds an archiver. With a nil client pages are fetched
+// through netguard.Client, so a snapshot can never capture an internal page.
+func NewArchiver(queries *supabase.Queries, client *http.Client) *Archiver {
+	if client == nil {
+		client = netguard.Client(20 * time.Second)
+	}
+
+	return &Archiver{
+		queries:  queries,
+		client:   client,
+		interval: 15 * time.Second,
+	}
+}
+
+// Start runs the archiver until ctx is cancelled
+func (a *Archiver) Start(ctx context.Context) {
+	ticker := time.NewTicker(a.interval)
+	defer ticker.Stop()
+
+	for {
+		a.RunOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce archives one batch of pending snapshots
+func (a *Archiver) RunOnce(ctx context.Context) {
+	due, err := a.queries.ClaimDueSnapshots(ctx, batchSize)
+	if err != nil {
+		log.Printf("snapshot: failed to claim snapshots: %v", err)
+		return
+	}
+
+	for _, pending := range due {
+		a.archive(ctx, pending)
+	}
+}
+
+func (a *Archiver) archive(ctx context.Context, pending supabase.ClaimDueSnapshotsRow) {
+	article, err := a.fetch(ctx, pending.Url)
+	if err == nil {
+		err = a.queries.SaveSnapshot(ctx, supabase.SaveSnapshotParams{
+			Title:          utils.TextOrNull(article.Title),
+			ContentHtml:    article.HTML,
+			TextContent:    article.Text,
+			WordCount:      int32(article.WordCount),
+			ReadingMinutes: int32(article.ReadingMinutes),
+			LinkID:         pending.LinkID,
+		})
+		if err != nil {
+			log.Printf("snapshot: failed to save %s: %v", utils.UUIDToString(pending.LinkID), err)
+		}
+		return
+	}
+
+	status := "pending"
+	if pending.Attempts >= MaxAttempts {
+		status = "failed"
+	}
+
+	next := time.Now().Add(retryDelay * time.Duration(pending.Attempts))
+
+	err = a.queries.MarkSnapshotFailed(ctx, supabase.MarkSnapshotFailedParams{
+		Status:        status,
+		LastError:     pgtype.Text{String: err.Error(), Valid: true},
+		NextAttemptAt: pgtype.Timestamptz{Time: next, Valid: true},
+		LinkID:        pending.LinkID,
+	})
+	if err != nil {
+		log.Printf("snapshot: failed to record failure for %s: %v", utils.UUIDToString(pending.LinkID), err)
+	}
+}
+
+func (a *Archiver) fetch(ctx context.Context, pageURL string) (Article, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return Article{}, err
+	}
+
+	req.Header.Set("User-Agent", "Cove-Archiver/1.0")
+	req.Header.Set("Accept", "text/html, application/xhtml+xml;q=0.9")
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return Article{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Article{}, fmt.Errorf("page responded with %d", resp.StatusCode)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return Article{}, fmt.Errorf("cannot archive %q content", mediaType)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxPageBytes+1))
+	if err != nil {
+		return Article{}, err
+	}
+	if len(body) > maxPageBytes {
+		return Article{}, fmt.Errorf("page is larger than %d bytes", maxPageBytes)
+	}
+
+	return Extract(bytes.NewReader(body), resp.Request.URL.String())
+}
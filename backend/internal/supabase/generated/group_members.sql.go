@@ -11,7 +11,23 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
-const addUserToGroup = `-- name: AddUserToGroup :exec
+const addGroupOwner = `-- name: AddGroupOwner :exec
+INSERT INTO group_members (user_id, group_id, role)
+VALUES ($1, $2, 'owner')
+    ON CONFLICT (user_id, group_id) DO UPDATE SET role = 'owner'
+`
+
+type AddGroupOwnerParams struct {
+	UserID  pgtype.UUID
+	GroupID pgtype.UUID
+}
+
+func (q *Queries) AddGroupOwner(ctx context.Context, arg AddGroupOwnerParams) error {
+	_, err := q.db.Exec(ctx, addGroupOwner, arg.UserID, arg.GroupID)
+	return err
+}
+
+const addUserToGroup = `-- name: AddUserToGroup :execrows
 INSERT INTO group_members (user_id, group_id)
 VALUES ($1, $2)
     ON CONFLICT DO NOTHING
@@ -22,29 +38,53 @@ type AddUserToGroupParams struct {
 	GroupID pgtype.UUID
 }
 
-func (q *Queries) AddUserToGroup(ctx context.Context, arg AddUserToGroupParams) error {
-	_, err := q.db.Exec(ctx, addUserToGroup, arg.UserID, arg.GroupID)
-	return err
+func (q *Queries) AddUserToGroup(ctx context.Context, arg AddUserToGroupParams) (int64, error) {
+	result, err := q.db.Exec(ctx, addUserToGroup, arg.UserID, arg.GroupID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
-const getGroupMembers = `-- name: GetGroupMembers :many
-SELECT user_id FROM group_members
-WHERE group_id = $1
+const getGroupMemberProfiles = `-- name: GetGroupMemberProfiles :many
+SELECT gm.user_id, gm.role, gm.joined_at, p.display_name, p.avatar_url, p.bio, p.timezone
+FROM group_members gm
+         LEFT JOIN profiles p ON p.user_id = gm.user_id
+WHERE gm.group_id = $1
+ORDER BY gm.joined_at, gm.user_id
 `
 
-func (q *Queries) GetGroupMembers(ctx context.Context, groupID pgtype.UUID) ([]pgtype.UUID, error) {
-	rows, err := q.db.Query(ctx, getGroupMembers, groupID)
+type GetGroupMemberProfilesRow struct {
+	UserID      pgtype.UUID
+	Role        string
+	JoinedAt    pgtype.Timestamptz
+	DisplayName pgtype.Text
+	AvatarUrl   pgtype.Text
+	Bio         pgtype.Text
+	Timezone    pgtype.Text
+}
+
+func (q *Queries) GetGroupMemberProfiles(ctx context.Context, groupID pgtype.UUID) ([]GetGroupMemberProfilesRow, error) {
+	rows, err := q.db.Query(ctx, getGroupMemberProfiles, groupID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []pgtype.UUID
+	var items []GetGroupMemberProfilesRow
 	for rows.Next() {
-		var user_id pgtype.UUID
-		if err := rows.Scan(&user_id); err != nil {
+		var i GetGroupMemberProfilesRow
+		if err := rows.Scan(
+			&i.UserID,
+			&i.Role,
+			&i.JoinedAt,
+			&i.DisplayName,
+			&i.AvatarUrl,
+			&i.Bio,
+			&i.Timezone,
+		); err != nil {
 			return nil, err
 		}
-		items = append(items, user_id)
+		items = append(items, i)
 	}
 	if err := rows.Err(); err != nil {
 		return nil, err
@@ -52,6 +92,32 @@ func (q *Queries) GetGroupMembers(ctx context.Context, groupID pgtype.UUID) ([]p
 	return items, nil
 }
 
+const getGroupMembersVersion = `-- name: GetGroupMembersVersion :one
+SELECT COUNT(*)::bigint AS member_count,
+       MAX(gm.joined_at)::timestamptz AS last_joined,
+       MAX(p.updated_at)::timestamptz AS profiles_updated
+FROM group_members gm
+         LEFT JOIN profiles p ON p.user_id = gm.user_id
+WHERE gm.group_id = $1
+`
+
+type GetGroupMembersVersionRow struct {
+	MemberCount     int64
+	LastJoined      pgtype.Timestamptz
+	ProfilesUpdated pgtype.Timestamptz
+}
+
+func (q *Queries) GetGroupMembersVersion(ctx context.Context, groupID pgtype.UUID) (GetGroupMembersVersionRow, error) {
+	row := q.db.QueryRow(ctx, getGroupMembersVersion, groupID)
+	var i GetGroupMembersVersionRow
+	err := row.Scan(
+		&i.MemberCount,
+		&i.LastJoined,
+		&i.ProfilesUpdated,
+	)
+	return i, err
+}
+
 const getGroupsForUser = `-- name: GetGroupsForUser :many
 SELECT group_id FROM group_members
 WHERE user_id = $1
@@ -77,6 +143,93 @@ func (q *Queries) GetGroupsForUser(ctx context.Context, userID pgtype.UUID) ([]p
 	return items, nil
 }
 
+const getMemberGroupAccess = `-- name: GetMemberGroupAccess :many
+SELECT gm.group_id, gm.role, g.settings
+FROM group_members gm
+         JOIN groups g ON g.id = gm.group_id
+WHERE gm.user_id = $1 AND gm.group_id = ANY($2::uuid[])
+`
+
+type GetMemberGroupAccessParams struct {
+	UserID   pgtype.UUID
+	GroupIds []pgtype.UUID
+}
+
+type GetMemberGroupAccessRow struct {
+	GroupID  pgtype.UUID
+	Role     string
+	Settings []byte
+}
+
+func (q *Queries) GetMemberGroupAccess(ctx context.Context, arg GetMemberGroupAccessParams) ([]GetMemberGroupAccessRow, error) {
+	rows, err := q.db.Query(ctx, getMemberGroupAccess, arg.UserID, arg.GroupIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetMemberGroupAccessRow
+	for rows.Next() {
+		var i GetMemberGroupAccessRow
+		if err := rows.Scan(
+			&i.GroupID,
+			&i.Role,
+			&i.Settings,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserGroupsVersion = `-- name: GetUserGroupsVersion :one
+SELECT COUNT(*)::bigint AS group_count,
+       MAX(gm.joined_at)::timestamptz AS last_joined,
+       MAX(g.updated_at)::timestamptz AS last_updated
+FROM group_members gm
+         JOIN groups g ON g.id = gm.group_id
+WHERE gm.user_id = $1
+`
+
+type GetUserGroupsVersionRow struct {
+	GroupCount  int64
+	LastJoined  pgtype.Timestamptz
+	LastUpdated pgtype.Timestamptz
+}
+
+func (q *Queries) GetUserGroupsVersion(ctx context.Context, userID pgtype.UUID) (GetUserGroupsVersionRow, error) {
+	row := q.db.QueryRow(ctx, getUserGroupsVersion, userID)
+	var i GetUserGroupsVersionRow
+	err := row.Scan(
+		&i.GroupCount,
+		&i.LastJoined,
+		&i.LastUpdated,
+	)
+	return i, err
+}
+
+const isGroupAdmin = `-- name: IsGroupAdmin :one
+SELECT EXISTS (
+    SELECT 1 FROM group_members
+    WHERE group_id = $1 AND user_id = $2 AND role IN ('owner', 'admin')
+) AS exists
+`
+
+type IsGroupAdminParams struct {
+	GroupID pgtype.UUID
+	UserID  pgtype.UUID
+}
+
+func (q *Queries) IsGroupAdmin(ctx context.Context, arg IsGroupAdminParams) (bool, error) {
+	row := q.db.QueryRow(ctx, isGroupAdmin, arg.GroupID, arg.UserID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
 const isUserInGroup = `-- name: IsUserInGroup :one
 SELECT EXISTS (
     SELECT 1 FROM group_members
@@ -95,3 +248,23 @@ func (q *Queries) IsUserInGroup(ctx context.Context, arg IsUserInGroupParams) (b
 	err := row.Scan(&exists)
 	return exists, err
 }
+
+const setGroupMuted = `-- name: SetGroupMuted :execrows
+UPDATE group_members
+SET muted_at = CASE WHEN $1::bool THEN COALESCE(muted_at, NOW()) END
+WHERE group_id = $2 AND user_id = $3
+`
+
+type SetGroupMutedParams struct {
+	Muted   bool
+	GroupID pgtype.UUID
+	UserID  pgtype.UUID
+}
+
+func (q *Queries) SetGroupMuted(ctx context.Context, arg SetGroupMutedParams) (int64, error) {
+	result, err := q.db.Exec(ctx, setGroupMuted, arg.Muted, arg.GroupID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
@@ -14,7 +14,7 @@ import (
 const createGroup = `-- name: CreateGroup :one
 INSERT INTO groups (name, created_by)
 VALUES ($1, $2)
-    RETURNING id, name, created_by, created_at
+    RETURNING id, name, created_by, created_at, description, emoji, avatar_url, settings, updated_at
 `
 
 type CreateGroupParams struct {
@@ -30,10 +30,31 @@ func (q *Queries) CreateGroup(ctx context.Context, arg CreateGroupParams) (Group
 		&i.Name,
 		&i.CreatedBy,
 		&i.CreatedAt,
+		&i.Description,
+		&i.Emoji,
+		&i.AvatarUrl,
+		&i.Settings,
+		&i.UpdatedAt,
 	)
 	return i, err
 }
 
+const deleteExpiredGroupLinks = `-- name: DeleteExpiredGroupLinks :execrows
+DELETE FROM links l
+    USING groups g
+WHERE l.group_id = g.id
+  AND (g.settings->>'retention_days')::int > 0
+  AND l.created_at < NOW() - make_interval(days => (g.settings->>'retention_days')::int)
+`
+
+func (q *Queries) DeleteExpiredGroupLinks(ctx context.Context) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteExpiredGroupLinks)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const deleteGroup = `-- name: DeleteGroup :exec
 DELETE FROM groups
 WHERE id = $1 AND created_by = $2
@@ -50,7 +71,7 @@ func (q *Queries) DeleteGroup(ctx context.Context, arg DeleteGroupParams) error
 }
 
 const getGroupByID = `-- name: GetGroupByID :one
-SELECT id, name, created_by, created_at FROM groups
+SELECT id, name, created_by, created_at, description, emoji, avatar_url, settings, updated_at FROM groups
 WHERE id = $1
 `
 
@@ -62,12 +83,44 @@ func (q *Queries) GetGroupByID(ctx context.Context, id pgtype.UUID) (Group, erro
 		&i.Name,
 		&i.CreatedBy,
 		&i.CreatedAt,
+		&i.Description,
+		&i.Emoji,
+		&i.AvatarUrl,
+		&i.Settings,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getGroupMemberAccess = `-- name: GetGroupMemberAccess :one
+SELECT gm.role, g.settings
+FROM group_members gm
+         JOIN groups g ON g.id = gm.group_id
+WHERE gm.group_id = $1 AND gm.user_id = $2
+`
+
+type GetGroupMemberAccessParams struct {
+	GroupID pgtype.UUID
+	UserID  pgtype.UUID
+}
+
+type GetGroupMemberAccessRow struct {
+	Role     string
+	Settings []byte
+}
+
+func (q *Queries) GetGroupMemberAccess(ctx context.Context, arg GetGroupMemberAccessParams) (GetGroupMemberAccessRow, error) {
+	row := q.db.QueryRow(ctx, getGroupMemberAccess, arg.GroupID, arg.UserID)
+	var i GetGroupMemberAccessRow
+	err := row.Scan(
+		&i.Role,
+		&i.Settings,
 	)
 	return i, err
 }
 
 const getGroupsByUser = `-- name: GetGroupsByUser :many
-SELECT g.id, g.name, g.created_by, g.created_at
+SELECT g.id, g.name, g.created_by, g.created_at, g.description, g.emoji, g.avatar_url, g.settings, g.updated_at
 FROM groups g
          JOIN group_members gm ON gm.group_id = g.id
 WHERE gm.user_id = $1
@@ -88,6 +141,11 @@ func (q *Queries) GetGroupsByUser(ctx context.Context, userID pgtype.UUID) ([]Gr
 			&i.Name,
 			&i.CreatedBy,
 			&i.CreatedAt,
+			&i.Description,
+			&i.Emoji,
+			&i.AvatarUrl,
+			&i.Settings,
+			&i.UpdatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -98,3 +156,61 @@ func (q *Queries) GetGroupsByUser(ctx context.Context, userID pgtype.UUID) ([]Gr
 	}
 	return items, nil
 }
+
+const lockGroupSettings = `-- name: LockGroupSettings :one
+SELECT settings FROM groups
+WHERE id = $1
+    FOR UPDATE
+`
+
+func (q *Queries) LockGroupSettings(ctx context.Context, id pgtype.UUID) ([]byte, error) {
+	row := q.db.QueryRow(ctx, lockGroupSettings, id)
+	var settings []byte
+	err := row.Scan(&settings)
+	return settings, err
+}
+
+const updateGroup = `-- name: UpdateGroup :one
+UPDATE groups
+SET name = $2,
+    description = $3,
+    emoji = $4,
+    avatar_url = $5,
+    settings = $6,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING id, name, created_by, created_at, description, emoji, avatar_url, settings, updated_at
+`
+
+type UpdateGroupParams struct {
+	ID          pgtype.UUID
+	Name        string
+	Description pgtype.Text
+	Emoji       pgtype.Text
+	AvatarUrl   pgtype.Text
+	Settings    []byte
+}
+
+func (q *Queries) UpdateGroup(ctx context.Context, arg UpdateGroupParams) (Group, error) {
+	row := q.db.QueryRow(ctx, updateGroup,
+		arg.ID,
+		arg.Name,
+		arg.Description,
+		arg.Emoji,
+		arg.AvatarUrl,
+		arg.Settings,
+	)
+	var i Group
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.Description,
+		&i.Emoji,
+		&i.AvatarUrl,
+		&i.Settings,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
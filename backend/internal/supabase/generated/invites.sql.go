@@ -11,31 +11,58 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const claimInvite = `-- name: ClaimInvite :one
+UPDATE invites
+SET used_by = $1
+WHERE code = $2 AND used_by IS NULL
+    RETURNING code, group_id, used_by, created_at, created_by
+`
+
+type ClaimInviteParams struct {
+	UsedBy pgtype.UUID
+	Code   string
+}
+
+func (q *Queries) ClaimInvite(ctx context.Context, arg ClaimInviteParams) (Invite, error) {
+	row := q.db.QueryRow(ctx, claimInvite, arg.UsedBy, arg.Code)
+	var i Invite
+	err := row.Scan(
+		&i.Code,
+		&i.GroupID,
+		&i.UsedBy,
+		&i.CreatedAt,
+		&i.CreatedBy,
+	)
+	return i, err
+}
+
 const createInvite = `-- name: CreateInvite :one
-INSERT INTO invites (code, group_id)
-VALUES ($1, $2)
-    RETURNING code, group_id, used_by, created_at
+INSERT INTO invites (code, group_id, created_by)
+VALUES ($1, $2, $3)
+    RETURNING code, group_id, used_by, created_at, created_by
 `
 
 type CreateInviteParams struct {
-	Code    string
-	GroupID pgtype.UUID
+	Code      string
+	GroupID   pgtype.UUID
+	CreatedBy pgtype.UUID
 }
 
 func (q *Queries) CreateInvite(ctx context.Context, arg CreateInviteParams) (Invite, error) {
-	row := q.db.QueryRow(ctx, createInvite, arg.Code, arg.GroupID)
+	row := q.db.QueryRow(ctx, createInvite, arg.Code, arg.GroupID, arg.CreatedBy)
 	var i Invite
 	err := row.Scan(
 		&i.Code,
 		&i.GroupID,
 		&i.UsedBy,
 		&i.CreatedAt,
+		&i.CreatedBy,
 	)
 	return i, err
 }
 
 const getInviteByCode = `-- name: GetInviteByCode :one
-SELECT code, group_id, used_by, created_at FROM invites
+SELECT code, group_id, used_by, created_at, created_by FROM invites
 WHERE code = $1
 `
 
@@ -47,12 +74,13 @@ func (q *Queries) GetInviteByCode(ctx context.Context, code string) (Invite, err
 		&i.GroupID,
 		&i.UsedBy,
 		&i.CreatedAt,
+		&i.CreatedBy,
 	)
 	return i, err
 }
 
 const getInvitesByGroup = `-- name: GetInvitesByGroup :many
-SELECT code, group_id, used_by, created_at FROM invites
+SELECT code, group_id, used_by, created_at, created_by FROM invites
 WHERE group_id = $1
 ORDER BY created_at DESC
 `
@@ -71,6 +99,7 @@ func (q *Queries) GetInvitesByGroup(ctx context.Context, groupID pgtype.UUID) ([
 			&i.GroupID,
 			&i.UsedBy,
 			&i.CreatedAt,
+			&i.CreatedBy,
 		); err != nil {
 			return nil, err
 		}
@@ -83,7 +112,7 @@ func (q *Queries) GetInvitesByGroup(ctx context.Context, groupID pgtype.UUID) ([
 }
 
 const getUnusedInvite = `-- name: GetUnusedInvite :one
-SELECT code, group_id, used_by, created_at FROM invites
+SELECT code, group_id, used_by, created_at, created_by FROM invites
 WHERE code = $1 AND used_by IS NULL
 `
 
@@ -95,22 +124,7 @@ func (q *Queries) GetUnusedInvite(ctx context.Context, code string) (Invite, err
 		&i.GroupID,
 		&i.UsedBy,
 		&i.CreatedAt,
+		&i.CreatedBy,
 	)
 	return i, err
 }
-
-const markInviteAsUsed = `-- name: MarkInviteAsUsed :exec
-UPDATE invites
-SET used_by = $1
-WHERE code = $2
-`
-
-type MarkInviteAsUsedParams struct {
-	UsedBy pgtype.UUID
-	Code   string
-}
-
-func (q *Queries) MarkInviteAsUsed(ctx context.Context, arg MarkInviteAsUsedParams) error {
-	_, err := q.db.Exec(ctx, markInviteAsUsed, arg.UsedBy, arg.Code)
-	return err
-}
@@ -11,18 +11,64 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const claimLinksForCheck = `-- name: ClaimLinksForCheck :many
+UPDATE links
+SET next_check_at = NOW() + INTERVAL '1 hour'
+WHERE id IN (
+    SELECT id FROM links
+    WHERE next_check_at <= NOW()
+    ORDER BY next_check_at
+    LIMIT $1
+    FOR UPDATE SKIP LOCKED
+)
+    RETURNING id, url, check_failures
+`
+
+type ClaimLinksForCheckRow struct {
+	ID            pgtype.UUID
+	Url           string
+	CheckFailures int32
+}
+
+func (q *Queries) ClaimLinksForCheck(ctx context.Context, limit int32) ([]ClaimLinksForCheckRow, error) {
+	rows, err := q.db.Query(ctx, claimLinksForCheck, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ClaimLinksForCheckRow
+	for rows.Next() {
+		var i ClaimLinksForCheckRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Url,
+			&i.CheckFailures,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const createLink = `-- name: CreateLink :one
-INSERT INTO links (group_id, user_id, url, title, comment)
-VALUES ($1, $2, $3, $4, $5)
-    RETURNING id, group_id, user_id, url, title, comment, created_at
+INSERT INTO links (group_id, user_id, url, title, comment, final_url, url_host, final_host)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+    RETURNING id, group_id, user_id, url, title, comment, created_at, tags, status, status_code, redirect_url, last_checked_at, check_failures, next_check_at, short_id, final_url, hidden_at, hidden_by, updated_at, url_host, final_host
 `
 
 type CreateLinkParams struct {
-	GroupID pgtype.UUID
-	UserID  pgtype.UUID
-	Url     string
-	Title   pgtype.Text
-	Comment pgtype.Text
+	GroupID   pgtype.UUID
+	UserID    pgtype.UUID
+	Url       string
+	Title     pgtype.Text
+	Comment   pgtype.Text
+	FinalUrl  pgtype.Text
+	UrlHost   pgtype.Text
+	FinalHost pgtype.Text
 }
 
 func (q *Queries) CreateLink(ctx context.Context, arg CreateLinkParams) (Link, error) {
@@ -32,6 +78,9 @@ func (q *Queries) CreateLink(ctx context.Context, arg CreateLinkParams) (Link, e
 		arg.Url,
 		arg.Title,
 		arg.Comment,
+		arg.FinalUrl,
+		arg.UrlHost,
+		arg.FinalHost,
 	)
 	var i Link
 	err := row.Scan(
@@ -42,6 +91,20 @@ func (q *Queries) CreateLink(ctx context.Context, arg CreateLinkParams) (Link, e
 		&i.Title,
 		&i.Comment,
 		&i.CreatedAt,
+		&i.Tags,
+		&i.Status,
+		&i.StatusCode,
+		&i.RedirectUrl,
+		&i.LastCheckedAt,
+		&i.CheckFailures,
+		&i.NextCheckAt,
+		&i.ShortID,
+		&i.FinalUrl,
+		&i.HiddenAt,
+		&i.HiddenBy,
+		&i.UpdatedAt,
+		&i.UrlHost,
+		&i.FinalHost,
 	)
 	return i, err
 }
@@ -61,8 +124,110 @@ func (q *Queries) DeleteLink(ctx context.Context, arg DeleteLinkParams) error {
 	return err
 }
 
+const findGroupLinkByURL = `-- name: FindGroupLinkByURL :one
+SELECT id, group_id, user_id, url, title, comment, created_at, tags, status, status_code, redirect_url, last_checked_at, check_failures, next_check_at, short_id, final_url, hidden_at, hidden_by, updated_at, url_host, final_host FROM links
+WHERE group_id = $1 AND COALESCE(final_url, url) = $2
+ORDER BY created_at
+    LIMIT 1
+`
+
+type FindGroupLinkByURLParams struct {
+	GroupID pgtype.UUID
+	Url     string
+}
+
+func (q *Queries) FindGroupLinkByURL(ctx context.Context, arg FindGroupLinkByURLParams) (Link, error) {
+	row := q.db.QueryRow(ctx, findGroupLinkByURL, arg.GroupID, arg.Url)
+	var i Link
+	err := row.Scan(
+		&i.ID,
+		&i.GroupID,
+		&i.UserID,
+		&i.Url,
+		&i.Title,
+		&i.Comment,
+		&i.CreatedAt,
+		&i.Tags,
+		&i.Status,
+		&i.StatusCode,
+		&i.RedirectUrl,
+		&i.LastCheckedAt,
+		&i.CheckFailures,
+		&i.NextCheckAt,
+		&i.ShortID,
+		&i.FinalUrl,
+		&i.HiddenAt,
+		&i.HiddenBy,
+		&i.UpdatedAt,
+		&i.UrlHost,
+		&i.FinalHost,
+	)
+	return i, err
+}
+
+const getGroupLinkURLs = `-- name: GetGroupLinkURLs :many
+SELECT url FROM links
+WHERE group_id = $1
+UNION
+SELECT final_url FROM links
+WHERE group_id = $1 AND final_url IS NOT NULL
+`
+
+func (q *Queries) GetGroupLinkURLs(ctx context.Context, groupID pgtype.UUID) ([]string, error) {
+	rows, err := q.db.Query(ctx, getGroupLinkURLs, groupID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var url string
+		if err := rows.Scan(&url); err != nil {
+			return nil, err
+		}
+		items = append(items, url)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getGroupLinksVersion = `-- name: GetGroupLinksVersion :one
+SELECT COUNT(*)::bigint AS link_count,
+       MAX(updated_at)::timestamptz AS last_updated,
+       (SELECT COUNT(*) FROM group_blocked_domains b WHERE b.group_id = $1)::bigint AS blocked_count,
+       (SELECT MAX(created_at) FROM group_blocked_domains b WHERE b.group_id = $1)::timestamptz AS blocked_updated,
+       (SELECT MAX(p.updated_at) FROM profiles p
+                JOIN group_members gm ON gm.user_id = p.user_id
+        WHERE gm.group_id = $1)::timestamptz AS profiles_updated
+FROM links
+WHERE group_id = $1
+`
+
+type GetGroupLinksVersionRow struct {
+	LinkCount       int64
+	LastUpdated     pgtype.Timestamptz
+	BlockedCount    int64
+	BlockedUpdated  pgtype.Timestamptz
+	ProfilesUpdated pgtype.Timestamptz
+}
+
+func (q *Queries) GetGroupLinksVersion(ctx context.Context, groupID pgtype.UUID) (GetGroupLinksVersionRow, error) {
+	row := q.db.QueryRow(ctx, getGroupLinksVersion, groupID)
+	var i GetGroupLinksVersionRow
+	err := row.Scan(
+		&i.LinkCount,
+		&i.LastUpdated,
+		&i.BlockedCount,
+		&i.BlockedUpdated,
+		&i.ProfilesUpdated,
+	)
+	return i, err
+}
+
 const getLinkByID = `-- name: GetLinkByID :one
-SELECT id, group_id, user_id, url, title, comment, created_at FROM links
+SELECT id, group_id, user_id, url, title, comment, created_at, tags, status, status_code, redirect_url, last_checked_at, check_failures, next_check_at, short_id, final_url, hidden_at, hidden_by, updated_at, url_host, final_host FROM links
 WHERE id = $1
 `
 
@@ -77,25 +242,93 @@ func (q *Queries) GetLinkByID(ctx context.Context, id pgtype.UUID) (Link, error)
 		&i.Title,
 		&i.Comment,
 		&i.CreatedAt,
+		&i.Tags,
+		&i.Status,
+		&i.StatusCode,
+		&i.RedirectUrl,
+		&i.LastCheckedAt,
+		&i.CheckFailures,
+		&i.NextCheckAt,
+		&i.ShortID,
+		&i.FinalUrl,
+		&i.HiddenAt,
+		&i.HiddenBy,
+		&i.UpdatedAt,
+		&i.UrlHost,
+		&i.FinalHost,
+	)
+	return i, err
+}
+
+const getLinkByShortID = `-- name: GetLinkByShortID :one
+SELECT id, group_id, url, final_url, user_id, hidden_at FROM links
+WHERE short_id = $1
+`
+
+type GetLinkByShortIDRow struct {
+	ID       pgtype.UUID
+	GroupID  pgtype.UUID
+	Url      string
+	FinalUrl pgtype.Text
+	UserID   pgtype.UUID
+	HiddenAt pgtype.Timestamptz
+}
+
+func (q *Queries) GetLinkByShortID(ctx context.Context, shortID string) (GetLinkByShortIDRow, error) {
+	row := q.db.QueryRow(ctx, getLinkByShortID, shortID)
+	var i GetLinkByShortIDRow
+	err := row.Scan(
+		&i.ID,
+		&i.GroupID,
+		&i.Url,
+		&i.FinalUrl,
+		&i.UserID,
+		&i.HiddenAt,
 	)
 	return i, err
 }
 
 const getLinksByGroup = `-- name: GetLinksByGroup :many
-SELECT id, group_id, user_id, url, title, comment, created_at FROM links
+SELECT id, group_id, user_id, url, title, comment, created_at, tags, status, status_code, redirect_url, last_checked_at, check_failures, next_check_at, short_id, final_url, hidden_at, hidden_by, updated_at, url_host, final_host FROM links
 WHERE group_id = $1
+  AND ($2::text IS NULL OR status = $2)
+  AND ($3::text IS NULL
+    OR to_tsvector('english', coalesce(title, '') || ' ' || coalesce(comment, '') || ' ' || url) @@ websearch_to_tsquery('english', $3)
+    OR EXISTS (
+        SELECT 1 FROM link_snapshots s
+        WHERE s.link_id = links.id AND s.search_vector @@ websearch_to_tsquery('english', $3)
+    ))
+  AND NOT EXISTS (
+    SELECT 1 FROM group_blocked_domains b
+    WHERE b.group_id = links.group_id
+      AND (links.url_host = b.domain OR (b.include_subdomains AND links.url_host LIKE '%.' || b.domain)
+        OR links.final_host = b.domain OR (b.include_subdomains AND links.final_host LIKE '%.' || b.domain))
+  )
+  AND (links.hidden_at IS NULL OR links.user_id = $4 OR $5::bool)
 ORDER BY created_at DESC
-    LIMIT $2 OFFSET $3
+    LIMIT $6 OFFSET $7
 `
 
 type GetLinksByGroupParams struct {
-	GroupID pgtype.UUID
-	Limit   int32
-	Offset  int32
+	GroupID    pgtype.UUID
+	Status     pgtype.Text
+	Query      pgtype.Text
+	ViewerID   pgtype.UUID
+	ShowHidden bool
+	Limit      int32
+	Offset     int32
 }
 
 func (q *Queries) GetLinksByGroup(ctx context.Context, arg GetLinksByGroupParams) ([]Link, error) {
-	rows, err := q.db.Query(ctx, getLinksByGroup, arg.GroupID, arg.Limit, arg.Offset)
+	rows, err := q.db.Query(ctx, getLinksByGroup,
+		arg.GroupID,
+		arg.Status,
+		arg.Query,
+		arg.ViewerID,
+		arg.ShowHidden,
+		arg.Limit,
+		arg.Offset,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -111,6 +344,20 @@ func (q *Queries) GetLinksByGroup(ctx context.Context, arg GetLinksByGroupParams
 			&i.Title,
 			&i.Comment,
 			&i.CreatedAt,
+			&i.Tags,
+			&i.Status,
+			&i.StatusCode,
+			&i.RedirectUrl,
+			&i.LastCheckedAt,
+			&i.CheckFailures,
+			&i.NextCheckAt,
+			&i.ShortID,
+			&i.FinalUrl,
+			&i.HiddenAt,
+			&i.HiddenBy,
+			&i.UpdatedAt,
+			&i.UrlHost,
+			&i.FinalHost,
 		); err != nil {
 			return nil, err
 		}
@@ -122,6 +369,99 @@ func (q *Queries) GetLinksByGroup(ctx context.Context, arg GetLinksByGroupParams
 	return items, nil
 }
 
+type ImportLinksParams struct {
+	ID        pgtype.UUID
+	GroupID   pgtype.UUID
+	UserID    pgtype.UUID
+	Url       string
+	Title     pgtype.Text
+	Comment   pgtype.Text
+	Tags      []string
+	CreatedAt pgtype.Timestamptz
+	UrlHost   pgtype.Text
+}
+
+const listLinksWithoutHosts = `-- name: ListLinksWithoutHosts :many
+SELECT id, url, final_url FROM links
+WHERE url_host IS NULL
+    LIMIT $1
+`
+
+type ListLinksWithoutHostsRow struct {
+	ID       pgtype.UUID
+	Url      string
+	FinalUrl pgtype.Text
+}
+
+func (q *Queries) ListLinksWithoutHosts(ctx context.Context, limit int32) ([]ListLinksWithoutHostsRow, error) {
+	rows, err := q.db.Query(ctx, listLinksWithoutHosts, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListLinksWithoutHostsRow
+	for rows.Next() {
+		var i ListLinksWithoutHostsRow
+		if err := rows.Scan(&i.ID, &i.Url, &i.FinalUrl); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const recordLinkCheck = `-- name: RecordLinkCheck :exec
+UPDATE links
+SET status = $1,
+    status_code = $2,
+    redirect_url = $3,
+    check_failures = $4,
+    last_checked_at = NOW(),
+    next_check_at = $5
+WHERE id = $6
+`
+
+type RecordLinkCheckParams struct {
+	Status        string
+	StatusCode    pgtype.Int4
+	RedirectUrl   pgtype.Text
+	CheckFailures int32
+	NextCheckAt   pgtype.Timestamptz
+	ID            pgtype.UUID
+}
+
+func (q *Queries) RecordLinkCheck(ctx context.Context, arg RecordLinkCheckParams) error {
+	_, err := q.db.Exec(ctx, recordLinkCheck,
+		arg.Status,
+		arg.StatusCode,
+		arg.RedirectUrl,
+		arg.CheckFailures,
+		arg.NextCheckAt,
+		arg.ID,
+	)
+	return err
+}
+
+const setLinkHosts = `-- name: SetLinkHosts :exec
+UPDATE links
+SET url_host = $2, final_host = $3
+WHERE id = $1
+`
+
+type SetLinkHostsParams struct {
+	ID        pgtype.UUID
+	UrlHost   pgtype.Text
+	FinalHost pgtype.Text
+}
+
+func (q *Queries) SetLinkHosts(ctx context.Context, arg SetLinkHostsParams) error {
+	_, err := q.db.Exec(ctx, setLinkHosts, arg.ID, arg.UrlHost, arg.FinalHost)
+	return err
+}
+
 const updateLinkComment = `-- name: UpdateLinkComment :exec
 UPDATE links
 SET comment = $1
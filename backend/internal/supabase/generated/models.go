@@ -8,17 +8,116 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type DigestSubscription struct {
+	UserID     pgtype.UUID
+	Email      string
+	Frequency  string
+	Timezone   string
+	SendHour   int32
+	LastSentAt pgtype.Timestamptz
+	CreatedAt  pgtype.Timestamptz
+	UpdatedAt  pgtype.Timestamptz
+}
+
+type FeedSourceEntry struct {
+	SourceID pgtype.UUID
+	Guid     string
+	LinkID   pgtype.UUID
+	SeenAt   pgtype.Timestamptz
+}
+
+type FeedToken struct {
+	ID         pgtype.UUID
+	UserID     pgtype.UUID
+	GroupID    pgtype.UUID
+	TokenHash  string
+	CreatedAt  pgtype.Timestamptz
+	LastUsedAt pgtype.Timestamptz
+	RevokedAt  pgtype.Timestamptz
+}
+
 type Group struct {
-	ID        pgtype.UUID
-	Name      string
-	CreatedBy pgtype.UUID
-	CreatedAt pgtype.Timestamptz
+	ID          pgtype.UUID
+	Name        string
+	CreatedBy   pgtype.UUID
+	CreatedAt   pgtype.Timestamptz
+	Description pgtype.Text
+	Emoji       pgtype.Text
+	AvatarUrl   pgtype.Text
+	Settings    []byte
+	UpdatedAt   pgtype.Timestamptz
+}
+
+type GroupBlockedDomain struct {
+	GroupID           pgtype.UUID
+	Domain            string
+	IncludeSubdomains bool
+	CreatedBy         pgtype.UUID
+	CreatedAt         pgtype.Timestamptz
+}
+
+type GroupFeedSource struct {
+	ID              pgtype.UUID
+	GroupID         pgtype.UUID
+	Url             string
+	Title           pgtype.Text
+	IntervalSeconds int32
+	Etag            pgtype.Text
+	LastModified    pgtype.Text
+	LastFetchedAt   pgtype.Timestamptz
+	NextFetchAt     pgtype.Timestamptz
+	LastError       pgtype.Text
+	CreatedBy       pgtype.UUID
+	CreatedAt       pgtype.Timestamptz
 }
 
 type GroupMember struct {
 	UserID   pgtype.UUID
 	GroupID  pgtype.UUID
 	JoinedAt pgtype.Timestamptz
+	Role     string
+	MutedAt  pgtype.Timestamptz
+}
+
+type GroupWebhook struct {
+	ID           pgtype.UUID
+	GroupID      pgtype.UUID
+	Url          string
+	Secret       string
+	Events       []string
+	Enabled      bool
+	FailureCount int32
+	CreatedBy    pgtype.UUID
+	CreatedAt    pgtype.Timestamptz
+	DisabledAt   pgtype.Timestamptz
+}
+
+type IdempotencyKey struct {
+	UserID         pgtype.UUID
+	Key            string
+	Fingerprint    string
+	Status         string
+	ResponseStatus pgtype.Int4
+	ContentType    pgtype.Text
+	ResponseBody   []byte
+	LockedAt       pgtype.Timestamptz
+	CreatedAt      pgtype.Timestamptz
+	ExpiresAt      pgtype.Timestamptz
+}
+
+type ImportJob struct {
+	ID         pgtype.UUID
+	GroupID    pgtype.UUID
+	UserID     pgtype.UUID
+	Format     string
+	Status     string
+	Total      int32
+	Imported   int32
+	Duplicates int32
+	Failed     int32
+	Errors     []byte
+	CreatedAt  pgtype.Timestamptz
+	FinishedAt pgtype.Timestamptz
 }
 
 type Invite struct {
@@ -26,14 +125,164 @@ type Invite struct {
 	GroupID   pgtype.UUID
 	UsedBy    pgtype.UUID
 	CreatedAt pgtype.Timestamptz
+	CreatedBy pgtype.UUID
 }
 
 type Link struct {
+	ID            pgtype.UUID
+	GroupID       pgtype.UUID
+	UserID        pgtype.UUID
+	Url           string
+	Title         pgtype.Text
+	Comment       pgtype.Text
+	CreatedAt     pgtype.Timestamptz
+	Tags          []string
+	Status        string
+	StatusCode    pgtype.Int4
+	RedirectUrl   pgtype.Text
+	LastCheckedAt pgtype.Timestamptz
+	CheckFailures int32
+	NextCheckAt   pgtype.Timestamptz
+	ShortID       string
+	FinalUrl      pgtype.Text
+	HiddenAt      pgtype.Timestamptz
+	HiddenBy      pgtype.UUID
+	UpdatedAt     pgtype.Timestamptz
+	UrlHost       pgtype.Text
+	FinalHost     pgtype.Text
+}
+
+type LinkClick struct {
+	LinkID        pgtype.UUID
+	UserID        pgtype.UUID
+	GroupID       pgtype.UUID
+	ClickedOn     pgtype.Date
+	Clicks        int32
+	LastClickedAt pgtype.Timestamptz
+}
+
+type LinkReply struct {
+	ID        pgtype.UUID
+	LinkID    pgtype.UUID
+	UserID    pgtype.UUID
+	Body      string
+	CreatedAt pgtype.Timestamptz
+}
+
+type LinkReport struct {
+	ID         pgtype.UUID
+	GroupID    pgtype.UUID
+	LinkID     pgtype.UUID
+	ReporterID pgtype.UUID
+	Reason     string
+	Status     string
+	CreatedAt  pgtype.Timestamptz
+	ResolvedAt pgtype.Timestamptz
+}
+
+type LinkSnapshot struct {
+	LinkID         pgtype.UUID
+	Status         string
+	Attempts       int32
+	NextAttemptAt  pgtype.Timestamptz
+	Title          pgtype.Text
+	ContentHtml    string
+	TextContent    string
+	WordCount      int32
+	ReadingMinutes int32
+	LastError      pgtype.Text
+	FetchedAt      pgtype.Timestamptz
+	CreatedAt      pgtype.Timestamptz
+	SearchVector   interface{}
+}
+
+type ModerationAction struct {
+	ID              pgtype.UUID
+	GroupID         pgtype.UUID
+	LinkID          pgtype.UUID
+	LinkUrl         string
+	ModeratorID     pgtype.UUID
+	Action          string
+	Note            pgtype.Text
+	ReportsResolved int32
+	CreatedAt       pgtype.Timestamptz
+}
+
+type Notification struct {
 	ID        pgtype.UUID
+	UserID    pgtype.UUID
+	Kind      string
 	GroupID   pgtype.UUID
+	LinkID    pgtype.UUID
+	ActorID   pgtype.UUID
+	CreatedAt pgtype.Timestamptz
+	ReadAt    pgtype.Timestamptz
+}
+
+type Profile struct {
+	UserID      pgtype.UUID
+	DisplayName pgtype.Text
+	AvatarUrl   pgtype.Text
+	Bio         pgtype.Text
+	Timezone    pgtype.Text
+	CreatedAt   pgtype.Timestamptz
+	UpdatedAt   pgtype.Timestamptz
+}
+
+type PushDelivery struct {
+	ID             pgtype.UUID
+	SubscriptionID pgtype.UUID
+	Payload        []byte
+	Status         string
+	Attempts       int32
+	NextAttemptAt  pgtype.Timestamptz
+	ResponseStatus pgtype.Int4
+	LastError      pgtype.Text
+	CreatedAt      pgtype.Timestamptz
+	ExpiresAt      pgtype.Timestamptz
+	DeliveredAt    pgtype.Timestamptz
+}
+
+type PushSubscription struct {
+	ID        pgtype.UUID
 	UserID    pgtype.UUID
+	Endpoint  string
+	P256dh    string
+	Auth      string
+	UserAgent pgtype.Text
+	CreatedAt pgtype.Timestamptz
+}
+
+type RateLimitBucket struct {
+	Key       string
+	Tokens    float64
+	Allowed   bool
+	UpdatedAt pgtype.Timestamptz
+}
+
+type SavedLink struct {
+	ID        pgtype.UUID
+	UserID    pgtype.UUID
+	LinkID    pgtype.UUID
+	GroupID   pgtype.UUID
 	Url       string
 	Title     pgtype.Text
-	Comment   pgtype.Text
+	Status    string
+	Note      pgtype.Text
 	CreatedAt pgtype.Timestamptz
+	UpdatedAt pgtype.Timestamptz
+}
+
+type WebhookDelivery struct {
+	ID             pgtype.UUID
+	WebhookID      pgtype.UUID
+	Event          string
+	Payload        []byte
+	Status         string
+	Attempts       int32
+	NextAttemptAt  pgtype.Timestamptz
+	ResponseStatus pgtype.Int4
+	LastError      pgtype.Text
+	CreatedAt      pgtype.Timestamptz
+	DeliveredAt    pgtype.Timestamptz
 }
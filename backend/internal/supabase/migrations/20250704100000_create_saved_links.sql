@@ -0,0 +1,22 @@
+CREATE TABLE saved_links (
+                             id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+                             user_id UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE,
+                             link_id UUID REFERENCES links(id) ON DELETE SET NULL,
+                             group_id UUID REFERENCES groups(id) ON DELETE SET NULL,
+                             url TEXT NOT NULL,
+                             title TEXT,
+                             status TEXT NOT NULL DEFAULT 'to-read' CHECK (status IN ('to-read', 'reading', 'done')),
+                             note TEXT,
+                             created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                             updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                             UNIQUE (user_id, link_id)
+);
+
+CREATE INDEX saved_links_user_created_idx ON saved_links (user_id, created_at DESC, id DESC);
+
+ALTER TABLE saved_links ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY owner_can_manage_saved_links ON saved_links
+  FOR ALL
+  TO authenticated
+  USING (user_id = auth.uid());
@@ -0,0 +1,17 @@
+CREATE TABLE digest_subscriptions (
+                                      user_id UUID PRIMARY KEY REFERENCES auth.users(id) ON DELETE CASCADE,
+                                      email TEXT NOT NULL,
+                                      frequency TEXT NOT NULL DEFAULT 'daily' CHECK (frequency IN ('daily', 'weekly', 'off')),
+                                      timezone TEXT NOT NULL DEFAULT 'UTC',
+                                      send_hour INT NOT NULL DEFAULT 8 CHECK (send_hour BETWEEN 0 AND 23),
+                                      last_sent_at TIMESTAMPTZ,
+                                      created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                                      updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE digest_subscriptions ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY owner_can_manage_digest_subscription ON digest_subscriptions
+  FOR ALL
+  TO authenticated
+  USING (user_id = auth.uid());
@@ -0,0 +1,42 @@
+ALTER TABLE group_members
+    ADD COLUMN role TEXT NOT NULL DEFAULT 'member' CHECK (role IN ('owner', 'admin', 'member'));
+
+UPDATE group_members gm
+SET role = 'owner'
+    FROM groups g
+WHERE g.id = gm.group_id AND g.created_by = gm.user_id;
+
+CREATE TABLE group_webhooks (
+                                id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+                                group_id UUID NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+                                url TEXT NOT NULL,
+                                secret TEXT NOT NULL,
+                                events TEXT[] NOT NULL,
+                                enabled BOOLEAN NOT NULL DEFAULT TRUE,
+                                failure_count INT NOT NULL DEFAULT 0,
+                                created_by UUID REFERENCES auth.users(id) ON DELETE SET NULL,
+                                created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                                disabled_at TIMESTAMPTZ
+);
+
+CREATE INDEX group_webhooks_group_idx ON group_webhooks (group_id);
+
+CREATE TABLE webhook_deliveries (
+                                    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+                                    webhook_id UUID NOT NULL REFERENCES group_webhooks(id) ON DELETE CASCADE,
+                                    event TEXT NOT NULL,
+                                    payload JSONB NOT NULL,
+                                    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'succeeded', 'failed')),
+                                    attempts INT NOT NULL DEFAULT 0,
+                                    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                                    response_status INT,
+                                    last_error TEXT,
+                                    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                                    delivered_at TIMESTAMPTZ
+);
+
+CREATE INDEX webhook_deliveries_due_idx ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX webhook_deliveries_webhook_idx ON webhook_deliveries (webhook_id, created_at DESC);
+
+ALTER TABLE group_webhooks ENABLE ROW LEVEL SECURITY;
+ALTER TABLE webhook_deliveries ENABLE ROW LEVEL SECURITY;
@@ -0,0 +1,13 @@
+CREATE TABLE feed_tokens (
+                             id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+                             user_id UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE,
+                             group_id UUID NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+                             token_hash TEXT NOT NULL UNIQUE,
+                             created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                             last_used_at TIMESTAMPTZ,
+                             revoked_at TIMESTAMPTZ
+);
+
+CREATE INDEX feed_tokens_user_group_idx ON feed_tokens (user_id, group_id);
+
+ALTER TABLE feed_tokens ENABLE ROW LEVEL SECURITY;
@@ -0,0 +1,28 @@
+CREATE TABLE group_feed_sources (
+                                    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+                                    group_id UUID NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+                                    url TEXT NOT NULL,
+                                    title TEXT,
+                                    interval_seconds INT NOT NULL DEFAULT 1800 CHECK (interval_seconds >= 300),
+                                    etag TEXT,
+                                    last_modified TEXT,
+                                    last_fetched_at TIMESTAMPTZ,
+                                    next_fetch_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                                    last_error TEXT,
+                                    created_by UUID REFERENCES auth.users(id) ON DELETE SET NULL,
+                                    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                                    UNIQUE (group_id, url)
+);
+
+CREATE INDEX group_feed_sources_due_idx ON group_feed_sources (next_fetch_at);
+
+CREATE TABLE feed_source_entries (
+                                     source_id UUID NOT NULL REFERENCES group_feed_sources(id) ON DELETE CASCADE,
+                                     guid TEXT NOT NULL,
+                                     link_id UUID REFERENCES links(id) ON DELETE SET NULL,
+                                     seen_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                                     PRIMARY KEY (source_id, guid)
+);
+
+ALTER TABLE group_feed_sources ENABLE ROW LEVEL SECURITY;
+ALTER TABLE feed_source_entries ENABLE ROW LEVEL SECURITY;
@@ -0,0 +1,18 @@
+ALTER TABLE links ADD COLUMN tags TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE TABLE import_jobs (
+                             id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+                             group_id UUID NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+                             user_id UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE,
+                             format TEXT NOT NULL,
+                             status TEXT NOT NULL DEFAULT 'running' CHECK (status IN ('running', 'completed', 'failed')),
+                             total INT NOT NULL DEFAULT 0,
+                             imported INT NOT NULL DEFAULT 0,
+                             duplicates INT NOT NULL DEFAULT 0,
+                             failed INT NOT NULL DEFAULT 0,
+                             errors JSONB NOT NULL DEFAULT '[]',
+                             created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                             finished_at TIMESTAMPTZ
+);
+
+ALTER TABLE import_jobs ENABLE ROW LEVEL SECURITY;
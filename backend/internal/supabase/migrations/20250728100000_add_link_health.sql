@@ -0,0 +1,10 @@
+ALTER TABLE links
+    ADD COLUMN status TEXT NOT NULL DEFAULT 'unchecked' CHECK (status IN ('unchecked', 'ok', 'failing', 'dead')),
+    ADD COLUMN status_code INT,
+    ADD COLUMN redirect_url TEXT,
+    ADD COLUMN last_checked_at TIMESTAMPTZ,
+    ADD COLUMN check_failures INT NOT NULL DEFAULT 0,
+    ADD COLUMN next_check_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+CREATE INDEX links_next_check_idx ON links (next_check_at);
+CREATE INDEX links_group_status_idx ON links (group_id, status);
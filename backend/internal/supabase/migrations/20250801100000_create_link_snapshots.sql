@@ -0,0 +1,22 @@
+CREATE TABLE link_snapshots (
+                                link_id UUID PRIMARY KEY REFERENCES links(id) ON DELETE CASCADE,
+                                status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'ready', 'failed')),
+                                attempts INT NOT NULL DEFAULT 0,
+                                next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                                title TEXT,
+                                content_html TEXT NOT NULL DEFAULT '',
+                                text_content TEXT NOT NULL DEFAULT '',
+                                word_count INT NOT NULL DEFAULT 0,
+                                reading_minutes INT NOT NULL DEFAULT 0,
+                                last_error TEXT,
+                                fetched_at TIMESTAMPTZ,
+                                created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                                search_vector TSVECTOR GENERATED ALWAYS AS (
+                                    to_tsvector('english', coalesce(title, '') || ' ' || text_content)
+                                    ) STORED
+);
+
+CREATE INDEX link_snapshots_due_idx ON link_snapshots (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX link_snapshots_search_idx ON link_snapshots USING GIN (search_vector);
+
+ALTER TABLE link_snapshots ENABLE ROW LEVEL SECURITY;
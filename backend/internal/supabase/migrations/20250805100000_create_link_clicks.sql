@@ -0,0 +1,13 @@
+ALTER TABLE links ADD COLUMN short_id TEXT NOT NULL UNIQUE DEFAULT substr(replace(gen_random_uuid()::text, '-', ''), 1, 10);
+
+CREATE TABLE link_clicks (
+                             link_id UUID NOT NULL REFERENCES links(id) ON DELETE CASCADE,
+                             user_id UUID REFERENCES auth.users(id) ON DELETE SET NULL,
+                             group_id UUID REFERENCES groups(id) ON DELETE CASCADE,
+                             clicked_on DATE NOT NULL,
+                             clicks INT NOT NULL DEFAULT 0,
+                             last_clicked_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                             UNIQUE NULLS NOT DISTINCT (link_id, user_id, clicked_on)
+);
+
+ALTER TABLE link_clicks ENABLE ROW LEVEL SECURITY;
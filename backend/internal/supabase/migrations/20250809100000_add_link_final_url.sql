@@ -0,0 +1,3 @@
+ALTER TABLE links ADD COLUMN final_url TEXT;
+
+CREATE INDEX links_group_final_url_idx ON links (group_id, (COALESCE(final_url, url)));
@@ -0,0 +1,16 @@
+ALTER TABLE links ADD COLUMN host TEXT GENERATED ALWAYS AS (
+    lower(substring(COALESCE(final_url, url) from '^[a-zA-Z][a-zA-Z0-9+.-]*://(?:[^/?#@]*@)?([^/?#:]+)'))
+    ) STORED;
+
+CREATE INDEX links_group_host_idx ON links (group_id, host);
+
+CREATE TABLE group_blocked_domains (
+                                       group_id UUID NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+                                       domain TEXT NOT NULL,
+                                       include_subdomains BOOLEAN NOT NULL DEFAULT FALSE,
+                                       created_by UUID REFERENCES auth.users(id) ON DELETE SET NULL,
+                                       created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                                       PRIMARY KEY (group_id, domain)
+);
+
+ALTER TABLE group_blocked_domains ENABLE ROW LEVEL SECURITY;
@@ -0,0 +1,35 @@
+ALTER TABLE links ADD COLUMN hidden_at TIMESTAMPTZ;
+ALTER TABLE links ADD COLUMN hidden_by UUID REFERENCES auth.users(id) ON DELETE SET NULL;
+
+CREATE TABLE link_reports (
+                              id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+                              group_id UUID NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+                              link_id UUID NOT NULL REFERENCES links(id) ON DELETE CASCADE,
+                              reporter_id UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE,
+                              reason TEXT NOT NULL,
+                              status TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'resolved')),
+                              created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                              resolved_at TIMESTAMPTZ,
+                              UNIQUE (link_id, reporter_id)
+);
+
+CREATE INDEX link_reports_open_idx ON link_reports (group_id, created_at) WHERE status = 'open';
+
+-- Actions outlive the links they were taken on, so link_id is not a
+-- foreign key and the URL is copied in
+CREATE TABLE moderation_actions (
+                                    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+                                    group_id UUID NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+                                    link_id UUID NOT NULL,
+                                    link_url TEXT NOT NULL,
+                                    moderator_id UUID REFERENCES auth.users(id) ON DELETE SET NULL,
+                                    action TEXT NOT NULL CHECK (action IN ('hide', 'unhide', 'delete', 'dismiss')),
+                                    note TEXT,
+                                    reports_resolved INTEGER NOT NULL DEFAULT 0,
+                                    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX moderation_actions_group_idx ON moderation_actions (group_id, created_at DESC);
+
+ALTER TABLE link_reports ENABLE ROW LEVEL SECURITY;
+ALTER TABLE moderation_actions ENABLE ROW LEVEL SECURITY;
@@ -0,0 +1,12 @@
+-- Token buckets shared by every API instance. Rows are tiny and written on
+-- each request, so the table is unlogged: a crash just refills every bucket.
+CREATE UNLOGGED TABLE rate_limit_buckets (
+                                             key TEXT PRIMARY KEY,
+                                             tokens DOUBLE PRECISION NOT NULL,
+                                             allowed BOOLEAN NOT NULL,
+                                             updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX rate_limit_buckets_updated_idx ON rate_limit_buckets (updated_at);
+
+ALTER TABLE rate_limit_buckets ENABLE ROW LEVEL SECURITY;
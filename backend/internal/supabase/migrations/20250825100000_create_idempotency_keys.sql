@@ -0,0 +1,17 @@
+CREATE TABLE idempotency_keys (
+                                  user_id UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE,
+                                  key TEXT NOT NULL,
+                                  fingerprint TEXT NOT NULL,
+                                  status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'completed')),
+                                  response_status INT,
+                                  content_type TEXT,
+                                  response_body BYTEA,
+                                  locked_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                                  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                                  expires_at TIMESTAMPTZ NOT NULL,
+                                  PRIMARY KEY (user_id, key)
+);
+
+CREATE INDEX idempotency_keys_expires_idx ON idempotency_keys (expires_at);
+
+ALTER TABLE idempotency_keys ENABLE ROW LEVEL SECURITY;
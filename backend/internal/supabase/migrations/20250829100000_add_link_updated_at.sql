@@ -0,0 +1,24 @@
+ALTER TABLE links ADD COLUMN updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+UPDATE links SET updated_at = COALESCE(created_at, NOW());
+
+-- Only columns that show up in API responses bump updated_at, so the link
+-- checker's scheduling writes do not invalidate every client's ETag
+CREATE OR REPLACE FUNCTION touch_link_updated_at() RETURNS trigger AS $$
+BEGIN
+    IF (NEW.url, NEW.title, NEW.comment, NEW.tags, NEW.status, NEW.status_code, NEW.redirect_url,
+        NEW.last_checked_at, NEW.final_url, NEW.hidden_at)
+        IS DISTINCT FROM
+       (OLD.url, OLD.title, OLD.comment, OLD.tags, OLD.status, OLD.status_code, OLD.redirect_url,
+        OLD.last_checked_at, OLD.final_url, OLD.hidden_at) THEN
+        NEW.updated_at = NOW();
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER links_touch_updated_at
+    BEFORE UPDATE ON links
+    FOR EACH ROW EXECUTE FUNCTION touch_link_updated_at();
+
+CREATE INDEX links_group_updated_idx ON links (group_id, updated_at DESC);
@@ -0,0 +1,14 @@
+ALTER TABLE groups
+    ADD COLUMN description TEXT,
+    ADD COLUMN emoji TEXT,
+    ADD COLUMN avatar_url TEXT,
+    -- who_can_invite, who_can_post, max_members and retention_days; missing
+    -- keys take their defaults, so existing groups keep today's behaviour
+    ADD COLUMN settings JSONB NOT NULL DEFAULT '{}'::jsonb,
+    ADD COLUMN updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+UPDATE groups SET updated_at = COALESCE(created_at, NOW());
+
+-- The retention pruner only visits groups that keep links for a limited time
+CREATE INDEX groups_retention_idx ON groups (((settings->>'retention_days')::int))
+    WHERE (settings->>'retention_days')::int > 0;
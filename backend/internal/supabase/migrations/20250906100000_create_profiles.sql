@@ -0,0 +1,26 @@
+CREATE TABLE profiles (
+                          user_id UUID PRIMARY KEY REFERENCES auth.users(id) ON DELETE CASCADE,
+                          display_name TEXT,
+                          avatar_url TEXT,
+                          bio TEXT,
+                          timezone TEXT,
+                          created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                          updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE profiles ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY owner_can_manage_profile ON profiles
+  FOR ALL
+  TO authenticated
+  USING (user_id = auth.uid());
+
+-- A profile is visible to everyone who shares a group with its owner
+CREATE POLICY group_mates_can_select_profiles ON profiles
+  FOR SELECT
+  TO authenticated
+  USING (EXISTS (
+    SELECT 1 FROM group_members mine
+             JOIN group_members theirs ON theirs.group_id = mine.group_id
+    WHERE mine.user_id = auth.uid() AND theirs.user_id = profiles.user_id
+  ));
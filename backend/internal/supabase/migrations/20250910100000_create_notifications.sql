@@ -0,0 +1,30 @@
+-- Who made an invite, so they can hear when it is accepted
+ALTER TABLE invites
+    ADD COLUMN created_by UUID REFERENCES auth.users(id) ON DELETE SET NULL;
+
+-- Members of a muted group get no new_link notifications; mentions still
+-- reach them
+ALTER TABLE group_members
+    ADD COLUMN muted_at TIMESTAMPTZ;
+
+CREATE TABLE notifications (
+                               id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+                               user_id UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE,
+                               kind TEXT NOT NULL CHECK (kind IN ('new_link', 'mention', 'invite_accepted')),
+                               group_id UUID REFERENCES groups(id) ON DELETE CASCADE,
+                               link_id UUID REFERENCES links(id) ON DELETE CASCADE,
+                               actor_id UUID REFERENCES auth.users(id) ON DELETE SET NULL,
+                               created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                               read_at TIMESTAMPTZ
+);
+
+CREATE INDEX notifications_user_created_idx ON notifications (user_id, created_at DESC, id DESC);
+CREATE INDEX notifications_user_unread_idx ON notifications (user_id) WHERE read_at IS NULL;
+CREATE INDEX notifications_created_idx ON notifications (created_at);
+
+ALTER TABLE notifications ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY owner_can_manage_notifications ON notifications
+  FOR ALL
+  TO authenticated
+  USING (user_id = auth.uid());
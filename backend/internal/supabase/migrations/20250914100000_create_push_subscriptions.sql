@@ -0,0 +1,39 @@
+-- Browser push subscriptions. The endpoint is a capability URL issued by the
+-- browser's push service; p256dh and auth are the keys payloads are
+-- encrypted to.
+CREATE TABLE push_subscriptions (
+                                    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+                                    user_id UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE,
+                                    endpoint TEXT NOT NULL UNIQUE,
+                                    p256dh TEXT NOT NULL,
+                                    auth TEXT NOT NULL,
+                                    user_agent TEXT,
+                                    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX push_subscriptions_user_idx ON push_subscriptions (user_id);
+
+CREATE TABLE push_deliveries (
+                                 id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+                                 subscription_id UUID NOT NULL REFERENCES push_subscriptions(id) ON DELETE CASCADE,
+                                 payload JSONB NOT NULL,
+                                 status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'succeeded', 'failed')),
+                                 attempts INT NOT NULL DEFAULT 0,
+                                 next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                                 response_status INT,
+                                 last_error TEXT,
+                                 created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                                 expires_at TIMESTAMPTZ NOT NULL,
+                                 delivered_at TIMESTAMPTZ
+);
+
+CREATE INDEX push_deliveries_due_idx ON push_deliveries (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX push_deliveries_created_idx ON push_deliveries (created_at) WHERE status <> 'pending';
+
+ALTER TABLE push_subscriptions ENABLE ROW LEVEL SECURITY;
+ALTER TABLE push_deliveries ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY owner_can_manage_push_subscriptions ON push_subscriptions
+  FOR ALL
+  TO authenticated
+  USING (user_id = auth.uid());
@@ -0,0 +1,42 @@
+-- Replies under a link, visible to the link's group. Each reply tells the
+-- link's poster with a reply notification.
+ALTER TABLE notifications DROP CONSTRAINT notifications_kind_check;
+ALTER TABLE notifications
+    ADD CONSTRAINT notifications_kind_check CHECK (kind IN ('new_link', 'mention', 'invite_accepted', 'reply'));
+
+CREATE TABLE link_replies (
+                              id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+                              link_id UUID NOT NULL REFERENCES links(id) ON DELETE CASCADE,
+                              user_id UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE,
+                              body TEXT NOT NULL CHECK (char_length(body) BETWEEN 1 AND 2000),
+                              created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX link_replies_link_created_idx ON link_replies (link_id, created_at, id);
+
+ALTER TABLE link_replies ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY members_can_select_link_replies ON link_replies
+  FOR SELECT
+  TO authenticated
+  USING (EXISTS (
+    SELECT 1 FROM links
+    JOIN group_members ON group_members.group_id = links.group_id
+    WHERE links.id = link_replies.link_id
+      AND group_members.user_id = auth.uid()
+  ));
+
+CREATE POLICY members_can_insert_link_replies ON link_replies
+  FOR INSERT
+  TO authenticated
+  WITH CHECK (user_id = auth.uid() AND EXISTS (
+    SELECT 1 FROM links
+    JOIN group_members ON group_members.group_id = links.group_id
+    WHERE links.id = link_replies.link_id
+      AND group_members.user_id = auth.uid()
+  ));
+
+CREATE POLICY members_can_delete_own_link_replies ON link_replies
+  FOR DELETE
+  TO authenticated
+  USING (user_id = auth.uid());
@@ -0,0 +1,14 @@
+-- The generated host column only looked at COALESCE(final_url, url) and could
+-- not apply IDNA, so a block on a shortener, or on a Unicode or mixed-case
+-- host, never matched. Hosts are now written by the server, normalized the
+-- same way as blocklist entries, for both the posted and the final URL.
+-- Existing rows are filled in by the server on startup.
+DROP INDEX links_group_host_idx;
+ALTER TABLE links DROP COLUMN host;
+
+ALTER TABLE links ADD COLUMN url_host TEXT;
+ALTER TABLE links ADD COLUMN final_host TEXT;
+
+CREATE INDEX links_group_url_host_idx ON links (group_id, url_host);
+CREATE INDEX links_group_final_host_idx ON links (group_id, final_host);
+CREATE INDEX links_missing_host_idx ON links (id) WHERE url_host IS NULL;
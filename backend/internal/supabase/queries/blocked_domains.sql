@@ -0,0 +1,20 @@
+-- name: AddBlockedDomain :one
+INSERT INTO group_blocked_domains (group_id, domain, include_subdomains, created_by)
+VALUES ($1, $2, $3, $4)
+    ON CONFLICT (group_id, domain) DO UPDATE SET include_subdomains = EXCLUDED.include_subdomains
+    RETURNING *;
+
+-- name: ListBlockedDomains :many
+SELECT * FROM group_blocked_domains
+WHERE group_id = $1
+ORDER BY domain;
+
+-- name: DeleteBlockedDomain :execrows
+DELETE FROM group_blocked_domains
+WHERE group_id = $1 AND domain = $2;
+
+-- name: GetBlockingDomain :one
+SELECT domain FROM group_blocked_domains
+WHERE group_id = sqlc.arg('group_id')
+  AND (domain = sqlc.arg('host')::text OR (include_subdomains AND sqlc.arg('host')::text LIKE '%.' || domain))
+    LIMIT 1;
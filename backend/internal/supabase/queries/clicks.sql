@@ -0,0 +1,35 @@
+-- name: RecordClicks :exec
+INSERT INTO link_clicks (link_id, user_id, group_id, clicked_on, clicks, last_clicked_at)
+SELECT * FROM unnest(
+    @link_ids::uuid[],
+    @user_ids::uuid[],
+    @group_ids::uuid[],
+    @days::date[],
+    @counts::int[],
+    @last_clicked::timestamptz[]
+)
+    ON CONFLICT (link_id, user_id, clicked_on) DO UPDATE
+    SET clicks = link_clicks.clicks + EXCLUDED.clicks,
+        last_clicked_at = GREATEST(link_clicks.last_clicked_at, EXCLUDED.last_clicked_at);
+
+-- name: GetLinkOpenCounts :many
+SELECT link_id, COUNT(DISTINCT user_id)::int AS opened_by
+FROM link_clicks
+WHERE link_id = ANY(@link_ids::uuid[]) AND user_id IS NOT NULL
+GROUP BY link_id;
+
+-- name: GetLinkClickTotals :one
+SELECT COALESCE(SUM(clicks), 0)::int AS total_clicks,
+       COUNT(DISTINCT user_id)::int AS unique_members,
+       COALESCE(SUM(clicks) FILTER (WHERE user_id IS NULL), 0)::int AS anonymous_clicks,
+       MAX(last_clicked_at)::timestamptz AS last_clicked_at
+FROM link_clicks
+WHERE link_id = $1;
+
+-- name: GetLinkClicksByDay :many
+SELECT clicked_on, SUM(clicks)::int AS clicks
+FROM link_clicks
+WHERE link_id = $1
+GROUP BY clicked_on
+ORDER BY clicked_on DESC
+    LIMIT $2;
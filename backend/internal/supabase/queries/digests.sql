@@ -0,0 +1,40 @@
+-- name: UpsertDigestSubscription :one
+INSERT INTO digest_subscriptions (user_id, email, frequency, timezone, send_hour, last_sent_at)
+VALUES ($1, $2, $3, $4, $5, NOW())
+    ON CONFLICT (user_id) DO UPDATE
+    SET email = EXCLUDED.email,
+        frequency = EXCLUDED.frequency,
+        timezone = EXCLUDED.timezone,
+        send_hour = EXCLUDED.send_hour,
+        updated_at = NOW()
+    RETURNING *;
+
+-- name: GetDigestSubscription :one
+SELECT * FROM digest_subscriptions
+WHERE user_id = $1;
+
+-- name: ListActiveDigestSubscriptions :many
+SELECT * FROM digest_subscriptions
+WHERE frequency <> 'off';
+
+-- name: MarkDigestSent :execrows
+UPDATE digest_subscriptions
+SET last_sent_at = sqlc.arg(last_sent_at)
+WHERE user_id = sqlc.arg(user_id)
+  AND last_sent_at IS NOT DISTINCT FROM sqlc.narg(previous_sent_at);
+
+-- name: UnsubscribeDigest :exec
+UPDATE digest_subscriptions
+SET frequency = 'off', updated_at = NOW()
+WHERE user_id = $1;
+
+-- name: GetDigestLinks :many
+SELECT l.id, l.group_id, g.name AS group_name, l.url, l.title, l.comment, l.created_at
+FROM links l
+         JOIN groups g ON g.id = l.group_id
+         JOIN group_members gm ON gm.group_id = l.group_id
+WHERE gm.user_id = sqlc.arg(user_id)
+  AND l.user_id IS DISTINCT FROM sqlc.arg(user_id)
+  AND l.created_at > sqlc.arg(since)
+  AND l.hidden_at IS NULL
+ORDER BY g.name, g.id, l.created_at DESC;
@@ -0,0 +1,54 @@
+-- name: CreateFeedSource :one
+INSERT INTO group_feed_sources (group_id, url, interval_seconds, created_by)
+VALUES ($1, $2, $3, $4)
+    RETURNING *;
+
+-- name: ListFeedSourcesByGroup :many
+SELECT * FROM group_feed_sources
+WHERE group_id = $1
+ORDER BY created_at DESC;
+
+-- name: DeleteFeedSource :execrows
+DELETE FROM group_feed_sources
+WHERE id = $1 AND group_id = $2;
+
+-- name: ClaimDueFeedSources :many
+UPDATE group_feed_sources
+SET next_fetch_at = NOW() + make_interval(secs => interval_seconds)
+WHERE id IN (
+    SELECT id FROM group_feed_sources
+    WHERE next_fetch_at <= NOW()
+    ORDER BY next_fetch_at
+        LIMIT $1
+    FOR UPDATE SKIP LOCKED
+)
+    RETURNING *;
+
+-- name: RecordFeedFetch :exec
+UPDATE group_feed_sources
+SET last_fetched_at = NOW(),
+    title = COALESCE(sqlc.narg(title), title),
+    etag = sqlc.narg(etag),
+    last_modified = sqlc.narg(last_modified),
+    last_error = sqlc.narg(last_error)
+WHERE id = sqlc.arg(id);
+
+-- name: HasFeedSourceEntries :one
+SELECT EXISTS (
+    SELECT 1 FROM feed_source_entries
+    WHERE source_id = $1
+) AS exists;
+
+-- name: InsertFeedSourceEntry :execrows
+INSERT INTO feed_source_entries (source_id, guid)
+VALUES ($1, $2)
+    ON CONFLICT DO NOTHING;
+
+-- name: SetFeedSourceEntryLink :exec
+UPDATE feed_source_entries
+SET link_id = $1
+WHERE source_id = $2 AND guid = $3;
+
+-- name: DeleteFeedSourceEntry :exec
+DELETE FROM feed_source_entries
+WHERE source_id = $1 AND guid = $2;
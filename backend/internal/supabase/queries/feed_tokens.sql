@@ -0,0 +1,23 @@
+-- name: CreateFeedToken :one
+INSERT INTO feed_tokens (user_id, group_id, token_hash)
+VALUES ($1, $2, $3)
+    RETURNING *;
+
+-- name: ListFeedTokens :many
+SELECT * FROM feed_tokens
+WHERE user_id = $1 AND group_id = $2 AND revoked_at IS NULL
+ORDER BY created_at DESC;
+
+-- name: GetFeedTokenByHash :one
+SELECT * FROM feed_tokens
+WHERE token_hash = $1 AND revoked_at IS NULL;
+
+-- name: RevokeFeedToken :execrows
+UPDATE feed_tokens
+SET revoked_at = NOW()
+WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL;
+
+-- name: TouchFeedToken :exec
+UPDATE feed_tokens
+SET last_used_at = NOW()
+WHERE id = $1;
@@ -1,4 +1,4 @@
--- name: AddUserToGroup :exec
+-- name: AddUserToGroup :execrows
 INSERT INTO group_members (user_id, group_id)
 VALUES ($1, $2)
     ON CONFLICT DO NOTHING;
@@ -7,12 +7,53 @@ VALUES ($1, $2)
 SELECT group_id FROM group_members
 WHERE user_id = $1;
 
--- name: GetGroupMembers :many
-SELECT user_id FROM group_members
-WHERE group_id = $1;
-
 -- name: IsUserInGroup :one
 SELECT EXISTS (
     SELECT 1 FROM group_members
     WHERE group_id = $1 AND user_id = $2
 ) AS exists;
+
+-- name: AddGroupOwner :exec
+INSERT INTO group_members (user_id, group_id, role)
+VALUES ($1, $2, 'owner')
+    ON CONFLICT (user_id, group_id) DO UPDATE SET role = 'owner';
+
+-- name: IsGroupAdmin :one
+SELECT EXISTS (
+    SELECT 1 FROM group_members
+    WHERE group_id = $1 AND user_id = $2 AND role IN ('owner', 'admin')
+) AS exists;
+
+-- name: GetGroupMembersVersion :one
+SELECT COUNT(*)::bigint AS member_count,
+       MAX(gm.joined_at)::timestamptz AS last_joined,
+       MAX(p.updated_at)::timestamptz AS profiles_updated
+FROM group_members gm
+         LEFT JOIN profiles p ON p.user_id = gm.user_id
+WHERE gm.group_id = $1;
+
+-- name: GetUserGroupsVersion :one
+SELECT COUNT(*)::bigint AS group_count,
+       MAX(gm.joined_at)::timestamptz AS last_joined,
+       MAX(g.updated_at)::timestamptz AS last_updated
+FROM group_members gm
+         JOIN groups g ON g.id = gm.group_id
+WHERE gm.user_id = $1;
+
+-- name: GetMemberGroupAccess :many
+SELECT gm.group_id, gm.role, g.settings
+FROM group_members gm
+         JOIN groups g ON g.id = gm.group_id
+WHERE gm.user_id = @user_id AND gm.group_id = ANY(@group_ids::uuid[]);
+
+-- name: GetGroupMemberProfiles :many
+SELECT gm.user_id, gm.role, gm.joined_at, p.display_name, p.avatar_url, p.bio, p.timezone
+FROM group_members gm
+         LEFT JOIN profiles p ON p.user_id = gm.user_id
+WHERE gm.group_id = $1
+ORDER BY gm.joined_at, gm.user_id;
+
+-- name: SetGroupMuted :execrows
+UPDATE group_members
+SET muted_at = CASE WHEN @muted::bool THEN COALESCE(muted_at, NOW()) END
+WHERE group_id = @group_id AND user_id = @user_id;
@@ -7,6 +7,11 @@ VALUES ($1, $2)
 SELECT * FROM groups
 WHERE id = $1;
 
+-- name: LockGroupSettings :one
+SELECT settings FROM groups
+WHERE id = $1
+    FOR UPDATE;
+
 -- name: GetGroupsByUser :many
 SELECT g.*
 FROM groups g
@@ -17,3 +22,27 @@ ORDER BY g.created_at DESC;
 -- name: DeleteGroup :exec
 DELETE FROM groups
 WHERE id = $1 AND created_by = $2;
+
+-- name: UpdateGroup :one
+UPDATE groups
+SET name = $2,
+    description = $3,
+    emoji = $4,
+    avatar_url = $5,
+    settings = $6,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: GetGroupMemberAccess :one
+SELECT gm.role, g.settings
+FROM group_members gm
+         JOIN groups g ON g.id = gm.group_id
+WHERE gm.group_id = $1 AND gm.user_id = $2;
+
+-- name: DeleteExpiredGroupLinks :execrows
+DELETE FROM links l
+    USING groups g
+WHERE l.group_id = g.id
+  AND (g.settings->>'retention_days')::int > 0
+  AND l.created_at < NOW() - make_interval(days => (g.settings->>'retention_days')::int);
@@ -0,0 +1,32 @@
+-- name: ClaimIdempotencyKey :one
+INSERT INTO idempotency_keys (user_id, key, fingerprint, expires_at)
+VALUES (sqlc.arg('user_id'), sqlc.arg('key'), sqlc.arg('fingerprint'), sqlc.arg('expires_at'))
+    ON CONFLICT (user_id, key) DO UPDATE
+                                  SET fingerprint = EXCLUDED.fingerprint,
+                                  status = 'pending',
+                                  response_status = NULL,
+                                  content_type = NULL,
+                                  response_body = NULL,
+                                  locked_at = NOW(),
+                                  created_at = NOW(),
+                                  expires_at = EXCLUDED.expires_at
+WHERE idempotency_keys.expires_at < NOW()
+   OR (idempotency_keys.status = 'pending' AND idempotency_keys.locked_at < sqlc.arg('stale_before'))
+    RETURNING *;
+
+-- name: GetIdempotencyKey :one
+SELECT * FROM idempotency_keys
+WHERE user_id = $1 AND key = $2;
+
+-- name: CompleteIdempotencyKey :exec
+UPDATE idempotency_keys
+SET status = 'completed', response_status = $3, content_type = $4, response_body = $5
+WHERE user_id = $1 AND key = $2;
+
+-- name: ReleaseIdempotencyKey :exec
+DELETE FROM idempotency_keys
+WHERE user_id = $1 AND key = $2 AND status = 'pending';
+
+-- name: DeleteExpiredIdempotencyKeys :execrows
+DELETE FROM idempotency_keys
+WHERE expires_at < NOW();
@@ -0,0 +1,18 @@
+-- name: CreateImportJob :one
+INSERT INTO import_jobs (group_id, user_id, format, total)
+VALUES ($1, $2, $3, $4)
+    RETURNING *;
+
+-- name: GetImportJob :one
+SELECT * FROM import_jobs
+WHERE id = $1 AND group_id = $2;
+
+-- name: FinishImportJob :exec
+UPDATE import_jobs
+SET status = $1,
+    imported = $2,
+    duplicates = $3,
+    failed = $4,
+    errors = $5,
+    finished_at = NOW()
+WHERE id = $6;
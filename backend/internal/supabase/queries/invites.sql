@@ -1,17 +1,18 @@
+-- name: ClaimInvite :one
+UPDATE invites
+SET used_by = $1
+WHERE code = $2 AND used_by IS NULL
+    RETURNING *;
+
 -- name: CreateInvite :one
-INSERT INTO invites (code, group_id)
-VALUES ($1, $2)
+INSERT INTO invites (code, group_id, created_by)
+VALUES ($1, $2, $3)
     RETURNING *;
 
 -- name: GetInviteByCode :one
 SELECT * FROM invites
 WHERE code = $1;
 
--- name: MarkInviteAsUsed :exec
-UPDATE invites
-SET used_by = $1
-WHERE code = $2;
-
 -- name: GetUnusedInvite :one
 SELECT * FROM invites
 WHERE code = $1 AND used_by IS NULL;
@@ -0,0 +1,16 @@
+-- name: CreateLinkReply :one
+INSERT INTO link_replies (link_id, user_id, body)
+VALUES ($1, $2, $3)
+    RETURNING *;
+
+-- name: ListLinkReplies :many
+SELECT * FROM link_replies
+WHERE link_id = sqlc.arg(link_id)
+  AND (sqlc.narg(cursor_created_at)::timestamptz IS NULL
+    OR (created_at, id) > (sqlc.narg(cursor_created_at)::timestamptz, sqlc.narg(cursor_id)::uuid))
+ORDER BY created_at, id
+    LIMIT sqlc.arg(row_limit);
+
+-- name: DeleteLinkReply :execrows
+DELETE FROM link_replies
+WHERE id = $1 AND link_id = $2 AND user_id = $3;
@@ -1,13 +1,27 @@
 -- name: CreateLink :one
-INSERT INTO links (group_id, user_id, url, title, comment)
-VALUES ($1, $2, $3, $4, $5)
+INSERT INTO links (group_id, user_id, url, title, comment, final_url, url_host, final_host)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
     RETURNING *;
 
 -- name: GetLinksByGroup :many
 SELECT * FROM links
-WHERE group_id = $1
+WHERE group_id = sqlc.arg('group_id')
+  AND (sqlc.narg('status')::text IS NULL OR status = sqlc.narg('status'))
+  AND (sqlc.narg('query')::text IS NULL
+    OR to_tsvector('english', coalesce(title, '') || ' ' || coalesce(comment, '') || ' ' || url) @@ websearch_to_tsquery('english', sqlc.narg('query'))
+    OR EXISTS (
+        SELECT 1 FROM link_snapshots s
+        WHERE s.link_id = links.id AND s.search_vector @@ websearch_to_tsquery('english', sqlc.narg('query'))
+    ))
+  AND NOT EXISTS (
+    SELECT 1 FROM group_blocked_domains b
+    WHERE b.group_id = links.group_id
+      AND (links.url_host = b.domain OR (b.include_subdomains AND links.url_host LIKE '%.' || b.domain)
+        OR links.final_host = b.domain OR (b.include_subdomains AND links.final_host LIKE '%.' || b.domain))
+  )
+  AND (links.hidden_at IS NULL OR links.user_id = sqlc.arg('viewer_id') OR sqlc.arg('show_hidden')::bool)
 ORDER BY created_at DESC
-    LIMIT $2 OFFSET $3;
+    LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
 
 -- name: GetLinkByID :one
 SELECT * FROM links
@@ -21,3 +35,67 @@ WHERE id = $1 AND user_id = $2;
 UPDATE links
 SET comment = $1
 WHERE id = $2 AND user_id = $3;
+
+-- name: ImportLinks :copyfrom
+INSERT INTO links (id, group_id, user_id, url, title, comment, tags, created_at, url_host)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);
+
+-- name: GetGroupLinkURLs :many
+SELECT url FROM links
+WHERE group_id = $1
+UNION
+SELECT final_url FROM links
+WHERE group_id = $1 AND final_url IS NOT NULL;
+
+-- name: ClaimLinksForCheck :many
+UPDATE links
+SET next_check_at = NOW() + INTERVAL '1 hour'
+WHERE id IN (
+    SELECT id FROM links
+    WHERE next_check_at <= NOW()
+    ORDER BY next_check_at
+    LIMIT $1
+    FOR UPDATE SKIP LOCKED
+)
+    RETURNING id, url, check_failures;
+
+-- name: RecordLinkCheck :exec
+UPDATE links
+SET status = $1,
+    status_code = $2,
+    redirect_url = $3,
+    check_failures = $4,
+    last_checked_at = NOW(),
+    next_check_at = $5
+WHERE id = $6;
+
+-- name: GetLinkByShortID :one
+SELECT id, group_id, url, final_url, user_id, hidden_at FROM links
+WHERE short_id = $1;
+
+-- name: FindGroupLinkByURL :one
+SELECT * FROM links
+WHERE group_id = $1 AND COALESCE(final_url, url) = $2
+ORDER BY created_at
+    LIMIT 1;
+
+-- name: GetGroupLinksVersion :one
+SELECT COUNT(*)::bigint AS link_count,
+       MAX(updated_at)::timestamptz AS last_updated,
+       (SELECT COUNT(*) FROM group_blocked_domains b WHERE b.group_id = $1)::bigint AS blocked_count,
+       (SELECT MAX(created_at) FROM group_blocked_domains b WHERE b.group_id = $1)::timestamptz AS blocked_updated,
+       (SELECT MAX(p.updated_at) FROM profiles p
+                JOIN group_members gm ON gm.user_id = p.user_id
+        WHERE gm.group_id = $1)::timestamptz AS profiles_updated
+FROM links
+WHERE group_id = $1;
+
+-- name: ListLinksWithoutHosts :many
+SELECT id, url, final_url FROM links
+WHERE url_host IS NULL
+    LIMIT $1;
+
+-- name: SetLinkHosts :exec
+UPDATE links
+SET url_host = $2, final_host = $3
+WHERE id = $1;
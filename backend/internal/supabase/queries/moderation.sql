@@ -0,0 +1,48 @@
+-- name: CreateLinkReport :one
+INSERT INTO link_reports (group_id, link_id, reporter_id, reason)
+VALUES ($1, $2, $3, $4)
+    ON CONFLICT (link_id, reporter_id) DO UPDATE
+                                       SET reason = EXCLUDED.reason, status = 'open', created_at = NOW(), resolved_at = NULL
+    RETURNING *;
+
+-- name: GetModerationQueue :many
+SELECT l.id, l.user_id, l.url, l.title, l.comment, l.status, l.created_at, l.hidden_at,
+       COUNT(r.id)::int AS report_count,
+       array_agg(r.reason ORDER BY r.created_at)::text[] AS reasons,
+       MIN(r.created_at)::timestamptz AS first_reported_at
+FROM link_reports r
+         JOIN links l ON l.id = r.link_id
+WHERE r.group_id = $1 AND r.status = 'open'
+GROUP BY l.id
+ORDER BY report_count DESC, first_reported_at
+    LIMIT $2 OFFSET $3;
+
+-- name: ResolveLinkReports :execrows
+UPDATE link_reports
+SET status = 'resolved', resolved_at = NOW()
+WHERE link_id = $1 AND status = 'open';
+
+-- name: HideLink :exec
+UPDATE links
+SET hidden_at = NOW(), hidden_by = $2
+WHERE id = $1;
+
+-- name: UnhideLink :exec
+UPDATE links
+SET hidden_at = NULL, hidden_by = NULL
+WHERE id = $1;
+
+-- name: DeleteGroupLink :exec
+DELETE FROM links
+WHERE id = $1 AND group_id = $2;
+
+-- name: CreateModerationAction :one
+INSERT INTO moderation_actions (group_id, link_id, link_url, moderator_id, action, note, reports_resolved)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+    RETURNING *;
+
+-- name: ListModerationActions :many
+SELECT * FROM moderation_actions
+WHERE group_id = $1
+ORDER BY created_at DESC
+    LIMIT $2 OFFSET $3;
@@ -0,0 +1,54 @@
+-- name: CreateNotification :one
+INSERT INTO notifications (user_id, kind, group_id, link_id, actor_id)
+VALUES ($1, $2, $3, $4, $5)
+    RETURNING *;
+
+-- name: CreateLinkNotifications :many
+INSERT INTO notifications (user_id, kind, group_id, link_id, actor_id)
+SELECT gm.user_id, 'new_link', gm.group_id, @link_id, @actor_id
+FROM group_members gm
+WHERE gm.group_id = @group_id
+  AND gm.muted_at IS NULL
+  AND gm.user_id IS DISTINCT FROM @actor_id
+  AND NOT (gm.user_id = ANY(@skip_user_ids::uuid[]))
+    RETURNING *;
+
+-- name: ListNotifications :many
+SELECT n.id, n.user_id, n.kind, n.group_id, n.link_id, n.actor_id, n.created_at, n.read_at,
+       g.name AS group_name, l.url AS link_url, l.title AS link_title
+FROM notifications n
+         LEFT JOIN groups g ON g.id = n.group_id
+         LEFT JOIN links l ON l.id = n.link_id
+WHERE n.user_id = sqlc.arg(user_id)
+  AND (NOT sqlc.arg(unread_only)::bool OR n.read_at IS NULL)
+  AND (sqlc.narg(cursor_created_at)::timestamptz IS NULL
+    OR (n.created_at, n.id) < (sqlc.narg(cursor_created_at)::timestamptz, sqlc.narg(cursor_id)::uuid))
+ORDER BY n.created_at DESC, n.id DESC
+    LIMIT sqlc.arg(row_limit);
+
+-- name: GetNotification :one
+SELECT n.id, n.user_id, n.kind, n.group_id, n.link_id, n.actor_id, n.created_at, n.read_at,
+       g.name AS group_name, l.url AS link_url, l.title AS link_title
+FROM notifications n
+         LEFT JOIN groups g ON g.id = n.group_id
+         LEFT JOIN links l ON l.id = n.link_id
+WHERE n.id = $1 AND n.user_id = $2;
+
+-- name: CountUnreadNotifications :one
+SELECT COUNT(*) FROM notifications
+WHERE user_id = $1 AND read_at IS NULL;
+
+-- name: MarkNotificationRead :one
+UPDATE notifications
+SET read_at = COALESCE(read_at, NOW())
+WHERE id = $1 AND user_id = $2
+RETURNING id;
+
+-- name: MarkAllNotificationsRead :execrows
+UPDATE notifications
+SET read_at = NOW()
+WHERE user_id = $1 AND read_at IS NULL;
+
+-- name: DeleteNotificationsBefore :execrows
+DELETE FROM notifications
+WHERE created_at < $1;
@@ -0,0 +1,31 @@
+-- name: GetProfile :one
+SELECT * FROM profiles
+WHERE user_id = $1;
+
+-- name: UpsertProfile :one
+INSERT INTO profiles (user_id, display_name, avatar_url, bio, timezone)
+VALUES ($1, $2, $3, $4, $5)
+    ON CONFLICT (user_id) DO UPDATE
+    SET display_name = EXCLUDED.display_name,
+        avatar_url = EXCLUDED.avatar_url,
+        bio = EXCLUDED.bio,
+        timezone = EXCLUDED.timezone,
+        updated_at = NOW()
+RETURNING *;
+
+-- name: SharesGroup :one
+SELECT EXISTS (
+    SELECT 1 FROM group_members a
+             JOIN group_members b ON b.group_id = a.group_id
+    WHERE a.user_id = @user_id AND b.user_id = @other_user_id
+) AS exists;
+
+-- name: GetVisibleProfiles :many
+SELECT p.user_id, p.display_name, p.avatar_url, p.bio, p.timezone, p.created_at, p.updated_at
+FROM profiles p
+WHERE p.user_id = ANY(@user_ids::uuid[])
+  AND (p.user_id = @viewer_id OR EXISTS (
+    SELECT 1 FROM group_members a
+             JOIN group_members b ON b.group_id = a.group_id
+    WHERE a.user_id = @viewer_id AND b.user_id = p.user_id
+));
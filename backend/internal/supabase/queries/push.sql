@@ -0,0 +1,72 @@
+-- name: UpsertPushSubscription :one
+INSERT INTO push_subscriptions (user_id, endpoint, p256dh, auth, user_agent)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (endpoint) DO UPDATE
+SET user_id = EXCLUDED.user_id,
+    p256dh = EXCLUDED.p256dh,
+    auth = EXCLUDED.auth,
+    user_agent = EXCLUDED.user_agent
+    RETURNING *;
+
+-- name: GetPushSubscription :one
+SELECT * FROM push_subscriptions
+WHERE id = $1 AND user_id = $2;
+
+-- name: ListPushSubscriptions :many
+SELECT * FROM push_subscriptions
+WHERE user_id = $1
+ORDER BY created_at DESC;
+
+-- name: DeletePushSubscription :execrows
+DELETE FROM push_subscriptions
+WHERE id = $1 AND user_id = $2;
+
+-- name: PrunePushSubscription :exec
+DELETE FROM push_subscriptions
+WHERE id = $1;
+
+-- name: CreatePushDelivery :exec
+INSERT INTO push_deliveries (subscription_id, payload, expires_at)
+VALUES ($1, $2, $3);
+
+-- name: EnqueuePushDeliveries :execrows
+INSERT INTO push_deliveries (subscription_id, payload, expires_at)
+SELECT id, $1, $2
+FROM push_subscriptions
+WHERE user_id = ANY($3::uuid[]);
+
+-- name: ClaimDuePushDeliveries :many
+UPDATE push_deliveries d
+SET next_attempt_at = NOW() + INTERVAL '1 minute'
+    FROM push_subscriptions s
+WHERE s.id = d.subscription_id
+  AND d.id IN (
+    SELECT id FROM push_deliveries
+    WHERE status = 'pending' AND next_attempt_at <= NOW()
+    ORDER BY next_attempt_at
+        LIMIT $1
+    FOR UPDATE SKIP LOCKED
+)
+    RETURNING d.id, d.subscription_id, d.payload, d.attempts, d.expires_at, s.endpoint, s.p256dh, s.auth;
+
+-- name: MarkPushDeliverySucceeded :exec
+UPDATE push_deliveries
+SET status = 'succeeded',
+    attempts = attempts + 1,
+    response_status = $1,
+    last_error = NULL,
+    delivered_at = NOW()
+WHERE id = $2;
+
+-- name: MarkPushDeliveryFailed :exec
+UPDATE push_deliveries
+SET status = $1,
+    attempts = attempts + 1,
+    next_attempt_at = $2,
+    response_status = $3,
+    last_error = $4
+WHERE id = $5;
+
+-- name: DeleteFinishedPushDeliveriesBefore :execrows
+DELETE FROM push_deliveries
+WHERE status <> 'pending' AND created_at < $1;
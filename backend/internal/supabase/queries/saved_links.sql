@@ -0,0 +1,60 @@
+-- name: SaveLink :one
+INSERT INTO saved_links (user_id, link_id, group_id, url, title, status, note)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+    RETURNING *;
+
+-- Saved copies of a hidden link are hidden too, except from its poster and
+-- the group's moderators
+
+-- name: GetSavedLink :one
+SELECT * FROM saved_links
+WHERE id = $1 AND user_id = $2
+  AND NOT EXISTS (
+    SELECT 1 FROM links l
+    WHERE l.id = saved_links.link_id
+      AND l.hidden_at IS NOT NULL
+      AND l.user_id IS DISTINCT FROM saved_links.user_id
+      AND NOT EXISTS (
+        SELECT 1 FROM group_members gm
+        WHERE gm.group_id = l.group_id AND gm.user_id = saved_links.user_id
+          AND gm.role IN ('owner', 'admin')));
+
+-- name: ListSavedLinks :many
+SELECT * FROM saved_links
+WHERE user_id = sqlc.arg(user_id)
+  AND (sqlc.narg(status)::text IS NULL OR status = sqlc.narg(status)::text)
+  AND (sqlc.narg(group_id)::uuid IS NULL OR group_id = sqlc.narg(group_id)::uuid)
+  AND (sqlc.narg(cursor_created_at)::timestamptz IS NULL
+    OR (created_at, id) < (sqlc.narg(cursor_created_at)::timestamptz, sqlc.narg(cursor_id)::uuid))
+  AND NOT EXISTS (
+    SELECT 1 FROM links l
+    WHERE l.id = saved_links.link_id
+      AND l.hidden_at IS NOT NULL
+      AND l.user_id IS DISTINCT FROM saved_links.user_id
+      AND NOT EXISTS (
+        SELECT 1 FROM group_members gm
+        WHERE gm.group_id = l.group_id AND gm.user_id = saved_links.user_id
+          AND gm.role IN ('owner', 'admin')))
+ORDER BY created_at DESC, id DESC
+    LIMIT sqlc.arg(row_limit);
+
+-- name: UpdateSavedLink :one
+UPDATE saved_links
+SET status = COALESCE(sqlc.narg(status), status),
+    note = COALESCE(sqlc.narg(note), note),
+    updated_at = NOW()
+WHERE id = sqlc.arg(id) AND user_id = sqlc.arg(user_id)
+  AND NOT EXISTS (
+    SELECT 1 FROM links l
+    WHERE l.id = saved_links.link_id
+      AND l.hidden_at IS NOT NULL
+      AND l.user_id IS DISTINCT FROM saved_links.user_id
+      AND NOT EXISTS (
+        SELECT 1 FROM group_members gm
+        WHERE gm.group_id = l.group_id AND gm.user_id = saved_links.user_id
+          AND gm.role IN ('owner', 'admin')))
+    RETURNING *;
+
+-- name: DeleteSavedLink :exec
+DELETE FROM saved_links
+WHERE id = $1 AND user_id = $2;
@@ -0,0 +1,43 @@
+-- name: CreatePendingSnapshot :exec
+INSERT INTO link_snapshots (link_id)
+VALUES ($1)
+    ON CONFLICT DO NOTHING;
+
+-- name: ClaimDueSnapshots :many
+UPDATE link_snapshots s
+SET attempts = s.attempts + 1,
+    next_attempt_at = NOW() + INTERVAL '10 minutes'
+FROM links l
+WHERE l.id = s.link_id
+  AND s.link_id IN (
+    SELECT link_id FROM link_snapshots
+    WHERE status = 'pending' AND next_attempt_at <= NOW()
+    ORDER BY next_attempt_at
+    LIMIT $1
+    FOR UPDATE SKIP LOCKED
+)
+    RETURNING s.link_id, COALESCE(l.final_url, l.url)::text AS url, s.attempts;
+
+-- name: SaveSnapshot :exec
+UPDATE link_snapshots
+SET status = 'ready',
+    title = $1,
+    content_html = $2,
+    text_content = $3,
+    word_count = $4,
+    reading_minutes = $5,
+    last_error = NULL,
+    fetched_at = NOW()
+WHERE link_id = $6;
+
+-- name: MarkSnapshotFailed :exec
+UPDATE link_snapshots
+SET status = $1,
+    last_error = $2,
+    next_attempt_at = $3
+WHERE link_id = $4;
+
+-- name: GetSnapshot :one
+SELECT link_id, status, title, content_html, text_content, word_count, reading_minutes, last_error, fetched_at, created_at
+FROM link_snapshots
+WHERE link_id = $1;
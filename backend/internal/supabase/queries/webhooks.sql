@@ -0,0 +1,100 @@
+-- name: CreateWebhook :one
+INSERT INTO group_webhooks (group_id, url, secret, events, created_by)
+VALUES ($1, $2, $3, $4, $5)
+    RETURNING *;
+
+-- name: GetWebhook :one
+SELECT * FROM group_webhooks
+WHERE id = $1 AND group_id = $2;
+
+-- name: ListWebhooksByGroup :many
+SELECT * FROM group_webhooks
+WHERE group_id = $1
+ORDER BY created_at DESC;
+
+-- name: ListWebhooksForEvent :many
+SELECT * FROM group_webhooks
+WHERE group_id = sqlc.arg(group_id)
+  AND enabled
+  AND sqlc.arg(event)::text = ANY(events);
+
+-- name: UpdateWebhook :one
+UPDATE group_webhooks
+SET url = COALESCE(sqlc.narg(url), url),
+    events = COALESCE(sqlc.narg(events)::text[], events),
+    enabled = COALESCE(sqlc.narg(enabled), enabled),
+    failure_count = CASE WHEN sqlc.narg(enabled)::boolean THEN 0 ELSE failure_count END,
+    disabled_at = CASE
+        WHEN sqlc.narg(enabled)::boolean IS NULL THEN disabled_at
+        WHEN sqlc.narg(enabled)::boolean THEN NULL
+        ELSE NOW()
+    END
+WHERE id = sqlc.arg(id) AND group_id = sqlc.arg(group_id)
+    RETURNING *;
+
+-- name: DeleteWebhook :exec
+DELETE FROM group_webhooks
+WHERE id = $1 AND group_id = $2;
+
+-- name: RecordWebhookSuccess :exec
+UPDATE group_webhooks
+SET failure_count = 0
+WHERE id = $1;
+
+-- name: RecordWebhookFailure :one
+UPDATE group_webhooks
+SET failure_count = failure_count + 1,
+    enabled = enabled AND failure_count + 1 < sqlc.arg(max_failures)::int,
+    disabled_at = CASE
+        WHEN enabled AND failure_count + 1 >= sqlc.arg(max_failures)::int THEN NOW()
+        ELSE disabled_at
+    END
+WHERE id = sqlc.arg(id)
+    RETURNING enabled;
+
+-- name: CreateWebhookDelivery :one
+INSERT INTO webhook_deliveries (webhook_id, event, payload)
+VALUES ($1, $2, $3)
+    RETURNING *;
+
+-- name: GetWebhookDelivery :one
+SELECT * FROM webhook_deliveries
+WHERE id = $1 AND webhook_id = $2;
+
+-- name: ListWebhookDeliveries :many
+SELECT * FROM webhook_deliveries
+WHERE webhook_id = $1
+ORDER BY created_at DESC
+    LIMIT $2;
+
+-- name: ClaimDueWebhookDeliveries :many
+UPDATE webhook_deliveries d
+SET next_attempt_at = NOW() + INTERVAL '1 minute'
+    FROM group_webhooks h
+WHERE h.id = d.webhook_id
+  AND d.id IN (
+    SELECT id FROM webhook_deliveries
+    WHERE status = 'pending' AND next_attempt_at <= NOW()
+    ORDER BY next_attempt_at
+        LIMIT $1
+    FOR UPDATE SKIP LOCKED
+)
+    RETURNING d.id, d.webhook_id, d.event, d.payload, d.attempts, h.url, h.secret, h.enabled;
+
+-- name: MarkWebhookDeliverySucceeded :exec
+UPDATE webhook_deliveries
+SET status = 'succeeded',
+    attempts = attempts + 1,
+    response_status = $1,
+    last_error = NULL,
+    delivered_at = NOW()
+WHERE id = $2;
+
+-- name: MarkWebhookDeliveryFailed :exec
+UPDATE webhook_deliveries
+SET status = $1,
+    attempts = attempts + 1,
+    next_attempt_at = $2,
+    response_status = $3,
+    last_error = $4
+WHERE id = $5;
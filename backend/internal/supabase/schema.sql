@@ -4,9 +4,19 @@ CREATE TABLE groups (
                         id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
                         name TEXT NOT NULL,
                         created_by UUID REFERENCES auth.users(id) ON DELETE CASCADE,
-                        created_at TIMESTAMPTZ DEFAULT NOW()
+                        created_at TIMESTAMPTZ DEFAULT NOW(),
+                        description TEXT,
+                        emoji TEXT,
+                        avatar_url TEXT,
+                        -- who_can_invite, who_can_post, max_members and
+                        -- retention_days; missing keys take their defaults
+                        settings JSONB NOT NULL DEFAULT '{}'::jsonb,
+                        updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
 );
 
+CREATE INDEX groups_retention_idx ON groups (((settings->>'retention_days')::int))
+    WHERE (settings->>'retention_days')::int > 0;
+
 ALTER TABLE groups ENABLE ROW LEVEL SECURITY;
 
 CREATE POLICY group_owner_access ON groups
@@ -18,6 +28,7 @@ CREATE TABLE group_members (
                                user_id UUID REFERENCES auth.users(id) ON DELETE CASCADE,
                                group_id UUID REFERENCES groups(id) ON DELETE CASCADE,
                                joined_at TIMESTAMPTZ DEFAULT NOW(),
+                               role TEXT NOT NULL DEFAULT 'member' CHECK (role IN ('owner', 'admin', 'member')),
                                PRIMARY KEY (user_id, group_id)
 );
 
@@ -40,7 +51,21 @@ CREATE TABLE links (
                        url TEXT NOT NULL,
                        title TEXT,
                        comment TEXT,
-                       created_at TIMESTAMPTZ DEFAULT NOW()
+                       created_at TIMESTAMPTZ DEFAULT NOW(),
+                       tags TEXT[] NOT NULL DEFAULT '{}',
+                       status TEXT NOT NULL DEFAULT 'unchecked' CHECK (status IN ('unchecked', 'ok', 'failing', 'dead')),
+                       status_code INT,
+                       redirect_url TEXT,
+                       last_checked_at TIMESTAMPTZ,
+                       check_failures INT NOT NULL DEFAULT 0,
+                       next_check_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                       short_id TEXT NOT NULL UNIQUE DEFAULT substr(replace(gen_random_uuid()::text, '-', ''), 1, 10),
+                       final_url TEXT,
+                       hidden_at TIMESTAMPTZ,
+                       hidden_by UUID REFERENCES auth.users(id) ON DELETE SET NULL,
+                       updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                       url_host TEXT,
+                       final_host TEXT
 );
 
 ALTER TABLE links ENABLE ROW LEVEL SECURITY;
@@ -90,4 +115,418 @@ CREATE POLICY authenticated_can_select_invites ON invites
 CREATE POLICY authenticated_can_insert_invites ON invites
   FOR INSERT
   TO authenticated
-  WITH CHECK (true);
\ No newline at end of file
+  WITH CHECK (true);
+
+CREATE TABLE saved_links (
+                             id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+                             user_id UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE,
+                             link_id UUID REFERENCES links(id) ON DELETE SET NULL,
+                             group_id UUID REFERENCES groups(id) ON DELETE SET NULL,
+                             url TEXT NOT NULL,
+                             title TEXT,
+                             status TEXT NOT NULL DEFAULT 'to-read' CHECK (status IN ('to-read', 'reading', 'done')),
+                             note TEXT,
+                             created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                             updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                             UNIQUE (user_id, link_id)
+);
+
+CREATE INDEX saved_links_user_created_idx ON saved_links (user_id, created_at DESC, id DESC);
+
+ALTER TABLE saved_links ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY owner_can_manage_saved_links ON saved_links
+  FOR ALL
+  TO authenticated
+  USING (user_id = auth.uid());
+
+CREATE TABLE digest_subscriptions (
+                                      user_id UUID PRIMARY KEY REFERENCES auth.users(id) ON DELETE CASCADE,
+                                      email TEXT NOT NULL,
+                                      frequency TEXT NOT NULL DEFAULT 'daily' CHECK (frequency IN ('daily', 'weekly', 'off')),
+                                      timezone TEXT NOT NULL DEFAULT 'UTC',
+                                      send_hour INT NOT NULL DEFAULT 8 CHECK (send_hour BETWEEN 0 AND 23),
+                                      last_sent_at TIMESTAMPTZ,
+                                      created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                                      updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE digest_subscriptions ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY owner_can_manage_digest_subscription ON digest_subscriptions
+  FOR ALL
+  TO authenticated
+  USING (user_id = auth.uid());
+
+CREATE TABLE group_webhooks (
+                                id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+                                group_id UUID NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+                                url TEXT NOT NULL,
+                                secret TEXT NOT NULL,
+                                events TEXT[] NOT NULL,
+                                enabled BOOLEAN NOT NULL DEFAULT TRUE,
+                                failure_count INT NOT NULL DEFAULT 0,
+                                created_by UUID REFERENCES auth.users(id) ON DELETE SET NULL,
+                                created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                                disabled_at TIMESTAMPTZ
+);
+
+CREATE INDEX group_webhooks_group_idx ON group_webhooks (group_id);
+
+CREATE TABLE webhook_deliveries (
+                                    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+                                    webhook_id UUID NOT NULL REFERENCES group_webhooks(id) ON DELETE CASCADE,
+                                    event TEXT NOT NULL,
+                                    payload JSONB NOT NULL,
+                                    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'succeeded', 'failed')),
+                                    attempts INT NOT NULL DEFAULT 0,
+                                    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                                    response_status INT,
+                                    last_error TEXT,
+                                    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                                    delivered_at TIMESTAMPTZ
+);
+
+CREATE INDEX webhook_deliveries_due_idx ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX webhook_deliveries_webhook_idx ON webhook_deliveries (webhook_id, created_at DESC);
+
+ALTER TABLE group_webhooks ENABLE ROW LEVEL SECURITY;
+ALTER TABLE webhook_deliveries ENABLE ROW LEVEL SECURITY;
+
+CREATE TABLE feed_tokens (
+                             id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+                             user_id UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE,
+                             group_id UUID NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+                             token_hash TEXT NOT NULL UNIQUE,
+                             created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                             last_used_at TIMESTAMPTZ,
+                             revoked_at TIMESTAMPTZ
+);
+
+CREATE INDEX feed_tokens_user_group_idx ON feed_tokens (user_id, group_id);
+
+ALTER TABLE feed_tokens ENABLE ROW LEVEL SECURITY;
+
+CREATE TABLE group_feed_sources (
+                                    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+                                    group_id UUID NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+                                    url TEXT NOT NULL,
+                                    title TEXT,
+                                    interval_seconds INT NOT NULL DEFAULT 1800 CHECK (interval_seconds >= 300),
+                                    etag TEXT,
+                                    last_modified TEXT,
+                                    last_fetched_at TIMESTAMPTZ,
+                                    next_fetch_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                                    last_error TEXT,
+                                    created_by UUID REFERENCES auth.users(id) ON DELETE SET NULL,
+                                    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                                    UNIQUE (group_id, url)
+);
+
+CREATE INDEX group_feed_sources_due_idx ON group_feed_sources (next_fetch_at);
+
+CREATE TABLE feed_source_entries (
+                                     source_id UUID NOT NULL REFERENCES group_feed_sources(id) ON DELETE CASCADE,
+                                     guid TEXT NOT NULL,
+                                     link_id UUID REFERENCES links(id) ON DELETE SET NULL,
+                                     seen_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                                     PRIMARY KEY (source_id, guid)
+);
+
+ALTER TABLE group_feed_sources ENABLE ROW LEVEL SECURITY;
+ALTER TABLE feed_source_entries ENABLE ROW LEVEL SECURITY;
+
+CREATE TABLE import_jobs (
+                             id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+                             group_id UUID NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+                             user_id UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE,
+                             format TEXT NOT NULL,
+                             status TEXT NOT NULL DEFAULT 'running' CHECK (status IN ('running', 'completed', 'failed')),
+                             total INT NOT NULL DEFAULT 0,
+                             imported INT NOT NULL DEFAULT 0,
+                             duplicates INT NOT NULL DEFAULT 0,
+                             failed INT NOT NULL DEFAULT 0,
+                             errors JSONB NOT NULL DEFAULT '[]',
+                             created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                             finished_at TIMESTAMPTZ
+);
+
+ALTER TABLE import_jobs ENABLE ROW LEVEL SECURITY;
+
+CREATE INDEX links_next_check_idx ON links (next_check_at);
+CREATE INDEX links_group_status_idx ON links (group_id, status);
+
+CREATE TABLE link_snapshots (
+                                link_id UUID PRIMARY KEY REFERENCES links(id) ON DELETE CASCADE,
+                                status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'ready', 'failed')),
+                                attempts INT NOT NULL DEFAULT 0,
+                                next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                                title TEXT,
+                                content_html TEXT NOT NULL DEFAULT '',
+                                text_content TEXT NOT NULL DEFAULT '',
+                                word_count INT NOT NULL DEFAULT 0,
+                                reading_minutes INT NOT NULL DEFAULT 0,
+                                last_error TEXT,
+                                fetched_at TIMESTAMPTZ,
+                                created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                                search_vector TSVECTOR GENERATED ALWAYS AS (
+                                    to_tsvector('english', coalesce(title, '') || ' ' || text_content)
+                                    ) STORED
+);
+
+CREATE INDEX link_snapshots_due_idx ON link_snapshots (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX link_snapshots_search_idx ON link_snapshots USING GIN (search_vector);
+
+ALTER TABLE link_snapshots ENABLE ROW LEVEL SECURITY;
+
+CREATE TABLE link_clicks (
+                             link_id UUID NOT NULL REFERENCES links(id) ON DELETE CASCADE,
+                             user_id UUID REFERENCES auth.users(id) ON DELETE SET NULL,
+                             group_id UUID REFERENCES groups(id) ON DELETE CASCADE,
+                             clicked_on DATE NOT NULL,
+                             clicks INT NOT NULL DEFAULT 0,
+                             last_clicked_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                             UNIQUE NULLS NOT DISTINCT (link_id, user_id, clicked_on)
+);
+
+ALTER TABLE link_clicks ENABLE ROW LEVEL SECURITY;
+
+CREATE INDEX links_group_final_url_idx ON links (group_id, (COALESCE(final_url, url)));
+
+CREATE INDEX links_group_url_host_idx ON links (group_id, url_host);
+
+CREATE INDEX links_group_final_host_idx ON links (group_id, final_host);
+
+CREATE INDEX links_missing_host_idx ON links (id) WHERE url_host IS NULL;
+
+CREATE TABLE group_blocked_domains (
+                                       group_id UUID NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+                                       domain TEXT NOT NULL,
+                                       include_subdomains BOOLEAN NOT NULL DEFAULT FALSE,
+                                       created_by UUID REFERENCES auth.users(id) ON DELETE SET NULL,
+                                       created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                                       PRIMARY KEY (group_id, domain)
+);
+
+ALTER TABLE group_blocked_domains ENABLE ROW LEVEL SECURITY;
+
+CREATE TABLE link_reports (
+                              id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+                              group_id UUID NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+                              link_id UUID NOT NULL REFERENCES links(id) ON DELETE CASCADE,
+                              reporter_id UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE,
+                              reason TEXT NOT NULL,
+                              status TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'resolved')),
+                              created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                              resolved_at TIMESTAMPTZ,
+                              UNIQUE (link_id, reporter_id)
+);
+
+CREATE INDEX link_reports_open_idx ON link_reports (group_id, created_at) WHERE status = 'open';
+
+-- Actions outlive the links they were taken on, so link_id is not a
+-- foreign key and the URL is copied in
+CREATE TABLE moderation_actions (
+                                    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+                                    group_id UUID NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+                                    link_id UUID NOT NULL,
+                                    link_url TEXT NOT NULL,
+                                    moderator_id UUID REFERENCES auth.users(id) ON DELETE SET NULL,
+                                    action TEXT NOT NULL CHECK (action IN ('hide', 'unhide', 'delete', 'dismiss')),
+                                    note TEXT,
+                                    reports_resolved INTEGER NOT NULL DEFAULT 0,
+                                    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX moderation_actions_group_idx ON moderation_actions (group_id, created_at DESC);
+
+ALTER TABLE link_reports ENABLE ROW LEVEL SECURITY;
+ALTER TABLE moderation_actions ENABLE ROW LEVEL SECURITY;
+
+-- Token buckets shared by every API instance. Rows are tiny and written on
+-- each request, so the table is unlogged: a crash just refills every bucket.
+CREATE UNLOGGED TABLE rate_limit_buckets (
+                                             key TEXT PRIMARY KEY,
+                                             tokens DOUBLE PRECISION NOT NULL,
+                                             allowed BOOLEAN NOT NULL,
+                                             updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX rate_limit_buckets_updated_idx ON rate_limit_buckets (updated_at);
+
+ALTER TABLE rate_limit_buckets ENABLE ROW LEVEL SECURITY;
+
+CREATE TABLE idempotency_keys (
+                                  user_id UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE,
+                                  key TEXT NOT NULL,
+                                  fingerprint TEXT NOT NULL,
+                                  status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'completed')),
+                                  response_status INT,
+                                  content_type TEXT,
+                                  response_body BYTEA,
+                                  locked_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                                  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                                  expires_at TIMESTAMPTZ NOT NULL,
+                                  PRIMARY KEY (user_id, key)
+);
+
+CREATE INDEX idempotency_keys_expires_idx ON idempotency_keys (expires_at);
+
+ALTER TABLE idempotency_keys ENABLE ROW LEVEL SECURITY;
+
+-- Only columns that show up in API responses bump updated_at, so the link
+-- checker's scheduling writes do not invalidate every client's ETag
+CREATE OR REPLACE FUNCTION touch_link_updated_at() RETURNS trigger AS $$
+BEGIN
+    IF (NEW.url, NEW.title, NEW.comment, NEW.tags, NEW.status, NEW.status_code, NEW.redirect_url,
+        NEW.last_checked_at, NEW.final_url, NEW.hidden_at)
+        IS DISTINCT FROM
+       (OLD.url, OLD.title, OLD.comment, OLD.tags, OLD.status, OLD.status_code, OLD.redirect_url,
+        OLD.last_checked_at, OLD.final_url, OLD.hidden_at) THEN
+        NEW.updated_at = NOW();
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER links_touch_updated_at
+    BEFORE UPDATE ON links
+    FOR EACH ROW EXECUTE FUNCTION touch_link_updated_at();
+
+CREATE INDEX links_group_updated_idx ON links (group_id, updated_at DESC);
+
+CREATE TABLE profiles (
+                          user_id UUID PRIMARY KEY REFERENCES auth.users(id) ON DELETE CASCADE,
+                          display_name TEXT,
+                          avatar_url TEXT,
+                          bio TEXT,
+                          timezone TEXT,
+                          created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                          updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE profiles ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY owner_can_manage_profile ON profiles
+  FOR ALL
+  TO authenticated
+  USING (user_id = auth.uid());
+
+-- A profile is visible to everyone who shares a group with its owner
+CREATE POLICY group_mates_can_select_profiles ON profiles
+  FOR SELECT
+  TO authenticated
+  USING (EXISTS (
+    SELECT 1 FROM group_members mine
+             JOIN group_members theirs ON theirs.group_id = mine.group_id
+    WHERE mine.user_id = auth.uid() AND theirs.user_id = profiles.user_id
+  ));
+
+-- Who made an invite, so they can hear when it is accepted
+ALTER TABLE invites
+    ADD COLUMN created_by UUID REFERENCES auth.users(id) ON DELETE SET NULL;
+
+-- Members of a muted group get no new_link notifications; mentions still
+-- reach them
+ALTER TABLE group_members
+    ADD COLUMN muted_at TIMESTAMPTZ;
+
+CREATE TABLE notifications (
+                               id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+                               user_id UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE,
+                               kind TEXT NOT NULL CHECK (kind IN ('new_link', 'mention', 'invite_accepted', 'reply')),
+                               group_id UUID REFERENCES groups(id) ON DELETE CASCADE,
+                               link_id UUID REFERENCES links(id) ON DELETE CASCADE,
+                               actor_id UUID REFERENCES auth.users(id) ON DELETE SET NULL,
+                               created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                               read_at TIMESTAMPTZ
+);
+
+CREATE INDEX notifications_user_created_idx ON notifications (user_id, created_at DESC, id DESC);
+CREATE INDEX notifications_user_unread_idx ON notifications (user_id) WHERE read_at IS NULL;
+CREATE INDEX notifications_created_idx ON notifications (created_at);
+
+ALTER TABLE notifications ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY owner_can_manage_notifications ON notifications
+  FOR ALL
+  TO authenticated
+  USING (user_id = auth.uid());
+
+-- Browser push subscriptions. The endpoint is a capability URL issued by the
+-- browser's push service; p256dh and auth are the keys payloads are
+-- encrypted to.
+CREATE TABLE push_subscriptions (
+                                    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+                                    user_id UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE,
+                                    endpoint TEXT NOT NULL UNIQUE,
+                                    p256dh TEXT NOT NULL,
+                                    auth TEXT NOT NULL,
+                                    user_agent TEXT,
+                                    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX push_subscriptions_user_idx ON push_subscriptions (user_id);
+
+CREATE TABLE push_deliveries (
+                                 id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+                                 subscription_id UUID NOT NULL REFERENCES push_subscriptions(id) ON DELETE CASCADE,
+                                 payload JSONB NOT NULL,
+                                 status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'succeeded', 'failed')),
+                                 attempts INT NOT NULL DEFAULT 0,
+                                 next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                                 response_status INT,
+                                 last_error TEXT,
+                                 created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                                 expires_at TIMESTAMPTZ NOT NULL,
+                                 delivered_at TIMESTAMPTZ
+);
+
+CREATE INDEX push_deliveries_due_idx ON push_deliveries (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX push_deliveries_created_idx ON push_deliveries (created_at) WHERE status <> 'pending';
+
+ALTER TABLE push_subscriptions ENABLE ROW LEVEL SECURITY;
+ALTER TABLE push_deliveries ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY owner_can_manage_push_subscriptions ON push_subscriptions
+  FOR ALL
+  TO authenticated
+  USING (user_id = auth.uid());
+
+-- Replies under a link, visible to the link's group
+CREATE TABLE link_replies (
+                              id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+                              link_id UUID NOT NULL REFERENCES links(id) ON DELETE CASCADE,
+                              user_id UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE,
+                              body TEXT NOT NULL CHECK (char_length(body) BETWEEN 1 AND 2000),
+                              created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX link_replies_link_created_idx ON link_replies (link_id, created_at, id);
+
+ALTER TABLE link_replies ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY members_can_select_link_replies ON link_replies
+  FOR SELECT
+  TO authenticated
+  USING (EXISTS (
+    SELECT 1 FROM links
+    JOIN group_members ON group_members.group_id = links.group_id
+    WHERE links.id = link_replies.link_id
+      AND group_members.user_id = auth.uid()
+  ));
+
+CREATE POLICY members_can_insert_link_replies ON link_replies
+  FOR INSERT
+  TO authenticated
+  WITH CHECK (user_id = auth.uid() AND EXISTS (
+    SELECT 1 FROM links
+    JOIN group_members ON group_members.group_id = links.group_id
+    WHERE links.id = link_replies.link_id
+      AND group_members.user_id = auth.uid()
+  ));
+
+CREATE POLICY members_can_delete_own_link_replies ON link_replies
+  FOR DELETE
+  TO authenticated
+  USING (user_id = auth.uid());
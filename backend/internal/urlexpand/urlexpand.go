@@ -0,0 +1,130 @@
+// Package urlexpand follows redirect chains from URL shorteners such as
+// t.co, bit.ly and lnkd.in to find where a link really points.
+package urlexpand
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/egeuysall/cove/internal/netguard"
+)
+
+const (
+	// MaxHops is the longest redirect chain that is followed
+	MaxHops = 10
+	// expandTimeout bounds the whole chain so link creation stays fast
+	expandTimeout = 2 * time.Second
+)
+
+var (
+	ErrPrivateTarget = errors.New("link points to a private or loopback address")
+	ErrTooManyHops   = fmt.Errorf("link redirects more than %d times", MaxHops)
+	ErrBadScheme     = errors.New("link redirects to a non-http URL")
+)
+
+// client never follows redirects itself so each hop can be inspected
+var client = &http.Client{
+	Transport: netguard.Transport(),
+	CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+// Expand returns the final URL of rawURL's redirect chain. Hops that point at
+// private or loopback addresses, non-http schemes or chains longer than
+// MaxHops are errors. If the chain cannot be followed for any other reason,
+// such as a timeout, the last URL reached is returned with a nil error so a
+// slow shortener never blocks posting.
+func Expand(ctx context.Context, rawURL string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, expandTimeout)
+	defer cancel()
+
+	current, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL, err
+	}
+
+	for hop := 0; ; hop++ {
+		if current.Scheme != "http" && current.Scheme != "https" {
+			return "", ErrBadScheme
+		}
+		if netguard.CheckHost(current.Hostname()) != nil {
+			return "", ErrPrivateTarget
+		}
+
+		next, err := nextHop(ctx, current)
+		if errors.Is(err, netguard.ErrPrivateAddress) {
+			return "", ErrPrivateTarget
+		}
+		if err != nil || next == nil {
+			return current.String(), nil
+		}
+
+		// next is redirect number hop+1
+		if hop >= MaxHops {
+			return "", ErrTooManyHops
+		}
+		current = next
+	}
+}
+
+// nextHop requests u and returns the redirect target, or nil when u is not
+// a redirect. HEAD is tried first; servers that reject it get a GET.
+func nextHop(ctx context.Context, u *url.URL) (*url.URL, error) {
+	resp, err := request(ctx, http.MethodHead, u)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented || resp.StatusCode == http.StatusForbidden {
+		resp, err = request(ctx, http.MethodGet, u)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	switch resp.StatusCode {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+	default:
+		return nil, nil
+	}
+
+	location := resp.Header.Get("Location")
+	if location == "" {
+		return nil, nil
+	}
+
+	next, err := u.Parse(location)
+	if err != nil {
+		return nil, nil
+	}
+	return next, nil
+}
+
+func request(ctx context.Context, method string, u *url.URL) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("User-Agent", "Cove-LinkExpander/1.0")
+	if method == http.MethodGet {
+		req.Header.Set("Range", "bytes=0-0")
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	resp.Body.Close()
+
+	return resp, nil
+}
@@ -1,17 +1,34 @@
 package utils
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
 	generated "github.com/egeuysall/cove/internal/supabase/generated"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"log"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 )
 
 var Queries *generated.Queries
 
-func Init(q *generated.Queries) {
+// DB is the pool behind Queries, for work sqlc cannot express such as
+// streaming through server-side cursors
+var DB *pgxpool.Pool
+
+func Init(db *pgxpool.Pool, q *generated.Queries) {
+	DB = db
 	Queries = q
 }
 
@@ -37,6 +54,19 @@ func SendError(w http.ResponseWriter, message string, statusCode int) {
 	}
 }
 
+// SendValidationError is SendError with a list of the specific problems
+// found, so clients can point at the offending fields
+func SendValidationError(w http.ResponseWriter, message string, details interface{}) {
+	w.WriteHeader(http.StatusBadRequest)
+
+	errorResponse := map[string]interface{}{"error": message, "details": details}
+	err := json.NewEncoder(w).Encode(errorResponse)
+
+	if err != nil {
+		log.Printf("SendValidationError encoding failed: %v", err)
+	}
+}
+
 func ParseUUID(str string) (pgtype.UUID, error) {
 	var id pgtype.UUID
 	err := id.Scan(str)
@@ -49,3 +79,148 @@ func UUIDToString(u pgtype.UUID) string {
 	}
 	return uuid.UUID(u.Bytes).String()
 }
+
+func TextOrNull(s string) pgtype.Text {
+	return pgtype.Text{String: s, Valid: s != ""}
+}
+
+// IsJSONNull reports whether raw is the JSON null, which in a merge patch
+// (RFC 7396) removes the member it is set on
+func IsJSONNull(raw json.RawMessage) bool {
+	return bytes.Equal(bytes.TrimSpace(raw), []byte("null"))
+}
+
+// PatchText reads a nullable string member of a merge patch. Null and the
+// empty string both clear it.
+func PatchText(raw json.RawMessage) (pgtype.Text, error) {
+	if IsJSONNull(raw) {
+		return pgtype.Text{}, nil
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return pgtype.Text{}, errors.New("must be a string or null")
+	}
+	return TextOrNull(strings.TrimSpace(s)), nil
+}
+
+// IsAvatarURL reports whether s is fit to show as an image on someone else's
+// screen: an absolute https URL of reasonable length
+func IsAvatarURL(s string) bool {
+	if len(s) > 2048 {
+		return false
+	}
+	parsed, err := url.Parse(s)
+	return err == nil && parsed.Scheme == "https" && parsed.Host != ""
+}
+
+// IsUniqueViolation reports whether err is a Postgres unique constraint error
+func IsUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+// ParseLimit reads the "limit" query parameter, falling back to def and
+// capping the result at max.
+func ParseLimit(r *http.Request, def, max int32) (int32, error) {
+	raw := r.URL.Query().Get("limit")
+	if raw == "" {
+		return def, nil
+	}
+
+	limit, err := strconv.ParseInt(raw, 10, 32)
+	if err != nil || limit < 1 {
+		return 0, errors.New("invalid limit")
+	}
+
+	if int32(limit) > max {
+		return max, nil
+	}
+	return int32(limit), nil
+}
+
+// EncodeCursor builds an opaque keyset cursor from the last row of a page
+func EncodeCursor(createdAt time.Time, id pgtype.UUID) string {
+	raw := createdAt.UTC().Format(time.RFC3339Nano) + "|" + UUIDToString(id)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeCursor reverses EncodeCursor. An empty cursor yields invalid
+// (NULL) values so queries start from the first page.
+func DecodeCursor(cursor string) (pgtype.Timestamptz, pgtype.UUID, error) {
+	var createdAt pgtype.Timestamptz
+	var id pgtype.UUID
+
+	if cursor == "" {
+		return createdAt, id, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return createdAt, id, errors.New("invalid cursor")
+	}
+
+	parts := strings.SplitN(string(raw), "|", 2)
+	if len(parts) != 2 {
+		return createdAt, id, errors.New("invalid cursor")
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return createdAt, id, errors.New("invalid cursor")
+	}
+
+	id, err = ParseUUID(parts[1])
+	if err != nil {
+		return createdAt, id, errors.New("invalid cursor")
+	}
+
+	createdAt = pgtype.Timestamptz{Time: t, Valid: true}
+	return createdAt, id, nil
+}
+
+// WeakETag builds a weak validator from whatever determines a response, such
+// as a row count and the latest updated_at, so handlers can answer
+// conditional requests before running the query that builds the body
+func WeakETag(parts ...interface{}) string {
+	hash := sha256.New()
+	for _, part := range parts {
+		fmt.Fprintf(hash, "%v\x00", part)
+	}
+	return `W/"` + hex.EncodeToString(hash.Sum(nil)[:16]) + `"`
+}
+
+// CheckNotModified sets the ETag and Last-Modified validators on w and, when
+// the request's conditional headers match them, replies 304 and returns true.
+// If-None-Match takes precedence over If-Modified-Since as per RFC 9110.
+func CheckNotModified(w http.ResponseWriter, r *http.Request, etag string, lastModified time.Time) bool {
+	if etag != "" {
+		w.Header().Set("ETag", etag)
+	}
+	if !lastModified.IsZero() {
+		w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		if etag == "" {
+			return false
+		}
+		for _, candidate := range strings.Split(inm, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+				w.WriteHeader(http.StatusNotModified)
+				return true
+			}
+		}
+		return false
+	}
+
+	if ims := r.Header.Get("If-Modified-Since"); ims != "" && !lastModified.IsZero() {
+		since, err := http.ParseTime(ims)
+		if err == nil && !lastModified.Truncate(time.Second).After(since) {
+			w.WriteHeader(http.StatusNotModified)
+			return true
+		}
+	}
+
+	return false
+}
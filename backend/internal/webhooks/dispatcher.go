@@ -0,0 +1,182 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/egeuysall/cove/internal/netguard"
+	supabase "github.com/egeuysall/cove/internal/supabase/generated"
+	"github.com/egeuysall/cove/internal/utils"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	// MaxAttempts is how many times a single delivery is tried before it is
+	// marked failed
+	MaxAttempts = 8
+	// MaxConsecutiveFailures disables a webhook after this many failed
+	// attempts in a row across all of its deliveries
+	MaxConsecutiveFailures = 15
+
+	baseBackoff = 30 * time.Second
+	maxBackoff  = 6 * time.Hour
+	batchSize   = 20
+)
+
+// Backoff returns the delay before retrying after the given number of
+// attempts: 30s, 1m, 2m, 4m... capped at six hours.
+func Backoff(attempts int32) time.Duration {
+	delay := baseBackoff
+	for i := int32(1); i < attempts; i++ {
+		delay *= 2
+		if delay >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return delay
+}
+
+// Dispatcher claims due deliveries and posts them to their endpoints. Claims
+// use SKIP LOCKED so several instances can run one each.
+type Dispatcher struct {
+	queries  *supabase.Queries
+	client   *http.Client
+	interval time.Duration
+}
+
+// NewDispatcher builds a dispatcher whose client will not connect to
+// private or loopback addresses, since webhook URLs come from users
+func NewDispatcher(queries *supabase.Queries) *Dispatcher {
+	return &Dispatcher{
+		queries:  queries,
+		client:   netguard.Client(10 * time.Second),
+		interval: 5 * time.Second,
+	}
+}
+
+// Start runs the dispatcher until ctx is cancelled
+func (d *Dispatcher) Start(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		d.RunOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce delivers one batch of due deliveries
+func (d *Dispatcher) RunOnce(ctx context.Context) {
+	deliveries, err := d.queries.ClaimDueWebhookDeliveries(ctx, batchSize)
+	if err != nil {
+		log.Printf("webhooks: failed to claim deliveries: %v", err)
+		return
+	}
+
+	for _, delivery := range deliveries {
+		d.deliver(ctx, delivery)
+	}
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, delivery supabase.ClaimDueWebhookDeliveriesRow) {
+	if !delivery.Enabled {
+		d.markFailed(ctx, delivery, "failed", pgtype.Int4{}, "webhook is disabled")
+		return
+	}
+
+	statusCode, err := d.post(ctx, delivery)
+	if err == nil {
+		err = d.queries.MarkWebhookDeliverySucceeded(ctx, supabase.MarkWebhookDeliverySucceededParams{
+			ResponseStatus: pgtype.Int4{Int32: int32(statusCode), Valid: true},
+			ID:             delivery.ID,
+		})
+		if err != nil {
+			log.Printf("webhooks: failed to record delivery %s: %v", utils.UUIDToString(delivery.ID), err)
+		}
+
+		err = d.queries.RecordWebhookSuccess(ctx, delivery.WebhookID)
+		if err != nil {
+			log.Printf("webhooks: failed to reset failures for %s: %v", utils.UUIDToString(delivery.WebhookID), err)
+		}
+		return
+	}
+
+	responseStatus := pgtype.Int4{Int32: int32(statusCode), Valid: statusCode != 0}
+
+	status := "pending"
+	if delivery.Attempts+1 >= MaxAttempts {
+		status = "failed"
+	}
+	d.markFailed(ctx, delivery, status, responseStatus, err.Error())
+
+	enabled, err := d.queries.RecordWebhookFailure(ctx, supabase.RecordWebhookFailureParams{
+		MaxFailures: MaxConsecutiveFailures,
+		ID:          delivery.WebhookID,
+	})
+	if err != nil {
+		log.Printf("webhooks: failed to record failure for %s: %v", utils.UUIDToString(delivery.WebhookID), err)
+		return
+	}
+
+	if !enabled {
+		log.Printf("webhooks: disabled %s after %d consecutive failures", utils.UUIDToString(delivery.WebhookID), MaxConsecutiveFailures)
+	}
+}
+
+func (d *Dispatcher) markFailed(ctx context.Context, delivery supabase.ClaimDueWebhookDeliveriesRow, status string, responseStatus pgtype.Int4, reason string) {
+	next := time.Now().Add(Backoff(delivery.Attempts + 1))
+
+	err := d.queries.MarkWebhookDeliveryFailed(ctx, supabase.MarkWebhookDeliveryFailedParams{
+		Status:         status,
+		NextAttemptAt:  pgtype.Timestamptz{Time: next, Valid: true},
+		ResponseStatus: responseStatus,
+		LastError:      pgtype.Text{String: reason, Valid: true},
+		ID:             delivery.ID,
+	})
+	if err != nil {
+		log.Printf("webhooks: failed to record delivery %s: %v", utils.UUIDToString(delivery.ID), err)
+	}
+}
+
+// post sends the delivery and returns the response status. Any non-2xx
+// response counts as a failure.
+func (d *Dispatcher) post(ctx context.Context, delivery supabase.ClaimDueWebhookDeliveriesRow) (int, error) {
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Url, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Cove-Webhooks/1.0")
+	req.Header.Set(EventHeader, delivery.Event)
+	req.Header.Set(DeliveryHeader, utils.UUIDToString(delivery.ID))
+	req.Header.Set(TimestampHeader, fmt.Sprintf("%d", timestamp))
+	req.Header.Set(SignatureHeader, Sign(delivery.Secret, timestamp, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	// Drain a little so the connection can be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("endpoint responded with %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
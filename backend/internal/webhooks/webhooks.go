@@ -0,0 +1,110 @@
+package webhooks
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"strconv"
+	"time"
+
+	supabase "github.com/egeuysall/cove/internal/supabase/generated"
+	"github.com/egeuysall/cove/internal/utils"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	EventLinkCreated  = "link.created"
+	EventLinkDeleted  = "link.deleted"
+	EventMemberJoined = "member.joined"
+)
+
+// Events lists every event a webhook can subscribe to
+var Events = []string{EventLinkCreated, EventLinkDeleted, EventMemberJoined}
+
+const (
+	SignatureHeader = "X-Cove-Signature"
+	TimestampHeader = "X-Cove-Timestamp"
+	EventHeader     = "X-Cove-Event"
+	DeliveryHeader  = "X-Cove-Delivery"
+)
+
+func IsValidEvent(event string) bool {
+	for _, e := range Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Payload is the JSON body posted to webhook endpoints
+type Payload struct {
+	Event     string      `json:"event"`
+	GroupID   string      `json:"group_id"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// GenerateSecret returns a random signing secret, shown to the admin once
+func GenerateSecret() (string, error) {
+	b := make([]byte, 32)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+// Sign computes the signature header value for a delivery. Receivers should
+// recompute HMAC-SHA256 over "<timestamp>.<body>" and compare in constant time.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Enqueue persists one pending delivery per enabled webhook in the group that
+// subscribes to event. Failures are logged rather than returned so callers
+// never fail a user request because of a webhook.
+func Enqueue(ctx context.Context, queries *supabase.Queries, groupID pgtype.UUID, event string, data interface{}) {
+	hooks, err := queries.ListWebhooksForEvent(ctx, supabase.ListWebhooksForEventParams{
+		GroupID: groupID,
+		Event:   event,
+	})
+	if err != nil {
+		log.Printf("webhooks: failed to list webhooks for %s: %v", event, err)
+		return
+	}
+
+	if len(hooks) == 0 {
+		return
+	}
+
+	payload, err := json.Marshal(Payload{
+		Event:     event,
+		GroupID:   utils.UUIDToString(groupID),
+		CreatedAt: time.Now().UTC(),
+		Data:      data,
+	})
+	if err != nil {
+		log.Printf("webhooks: failed to encode %s payload: %v", event, err)
+		return
+	}
+
+	for _, hook := range hooks {
+		_, err := queries.CreateWebhookDelivery(ctx, supabase.CreateWebhookDeliveryParams{
+			WebhookID: hook.ID,
+			Event:     event,
+			Payload:   payload,
+		})
+		if err != nil {
+			log.Printf("webhooks: failed to queue %s for %s: %v", event, utils.UUIDToString(hook.ID), err)
+		}
+	}
+}
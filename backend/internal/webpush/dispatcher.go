@@ -0,0 +1,223 @@
+package webpush
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/egeuysall/cove/internal/netguard"
+	supabase "github.com/egeuysall/cove/internal/supabase/generated"
+	"github.com/egeuysall/cove/internal/utils"
+	"github.com/egeuysall/cove/internal/webhooks"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	// MaxAttempts is how many times a message is tried before it is marked
+	// failed
+	MaxAttempts = 5
+
+	batchSize = 50
+)
+
+// Dispatcher claims due push deliveries and sends them. Claims use SKIP
+// LOCKED so several instances can run one each.
+type Dispatcher struct {
+	cfg      Config
+	queries  *supabase.Queries
+	client   *http.Client
+	interval time.Duration
+}
+
+// NewDispatcher builds a dispatcher. A nil client uses a default that
+// refuses private and loopback addresses unless cfg.AllowLocal is set.
+func NewDispatcher(cfg Config, queries *supabase.Queries, client *http.Client) *Dispatcher {
+	if client == nil {
+		if cfg.AllowLocal {
+			client = &http.Client{Timeout: 10 * time.Second}
+		} else {
+			client = netguard.Client(10 * time.Second)
+		}
+	}
+
+	return &Dispatcher{
+		cfg:      cfg,
+		queries:  queries,
+		client:   client,
+		interval: 2 * time.Second,
+	}
+}
+
+// Start runs the dispatcher until ctx is cancelled
+func (d *Dispatcher) Start(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		d.RunOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce sends one batch of due deliveries
+func (d *Dispatcher) RunOnce(ctx context.Context) {
+	deliveries, err := d.queries.ClaimDuePushDeliveries(ctx, batchSize)
+	if err != nil {
+		log.Printf("webpush: failed to claim deliveries: %v", err)
+		return
+	}
+
+	for _, delivery := range deliveries {
+		d.deliver(ctx, delivery)
+	}
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, delivery supabase.ClaimDuePushDeliveriesRow) {
+	now := time.Now()
+	ttl := delivery.ExpiresAt.Time.Sub(now)
+	if ttl <= 0 {
+		d.markFailed(ctx, delivery, "failed", now, pgtype.Int4{}, "expired before it could be delivered")
+		return
+	}
+
+	statusCode, err := d.send(ctx, delivery, ttl, now)
+	if err == nil {
+		err = d.queries.MarkPushDeliverySucceeded(ctx, supabase.MarkPushDeliverySucceededParams{
+			ResponseStatus: pgtype.Int4{Int32: int32(statusCode), Valid: true},
+			ID:             delivery.ID,
+		})
+		if err != nil {
+			log.Printf("webpush: failed to record delivery %s: %v", utils.UUIDToString(delivery.ID), err)
+		}
+		return
+	}
+
+	// The push service no longer knows the subscription: the user revoked
+	// permission or the browser dropped it. Deleting it also drops its queue.
+	if statusCode == http.StatusNotFound || statusCode == http.StatusGone {
+		err := d.queries.PrunePushSubscription(ctx, delivery.SubscriptionID)
+		if err != nil {
+			log.Printf("webpush: failed to prune subscription %s: %v", utils.UUIDToString(delivery.SubscriptionID), err)
+		}
+		return
+	}
+
+	responseStatus := pgtype.Int4{Int32: int32(statusCode), Valid: statusCode != 0}
+
+	// Other 4xx answers will not change on retry, except being throttled
+	status := "pending"
+	if delivery.Attempts+1 >= MaxAttempts || statusCode >= 400 && statusCode < 500 && statusCode != http.StatusTooManyRequests {
+		status = "failed"
+	}
+	d.markFailed(ctx, delivery, status, now, responseStatus, err.Error())
+}
+
+func (d *Dispatcher) markFailed(ctx context.Context, delivery supabase.ClaimDuePushDeliveriesRow, status string, now time.Time, responseStatus pgtype.Int4, reason string) {
+	// Retries back off like webhook deliveries, but stop once the message
+	// would arrive too late to matter
+	next := now.Add(webhooks.Backoff(delivery.Attempts + 1))
+	if status == "pending" && next.After(delivery.ExpiresAt.Time) {
+		status = "failed"
+	}
+
+	err := d.queries.MarkPushDeliveryFailed(ctx, supabase.MarkPushDeliveryFailedParams{
+		Status:         status,
+		NextAttemptAt:  pgtype.Timestamptz{Time: next, Valid: true},
+		ResponseStatus: responseStatus,
+		LastError:      pgtype.Text{String: reason, Valid: true},
+		ID:             delivery.ID,
+	})
+	if err != nil {
+		log.Printf("webpush: failed to record delivery %s: %v", utils.UUIDToString(delivery.ID), err)
+	}
+}
+
+// send encrypts and posts the delivery and returns the response status. Push
+// services answer 201 Created; any non-2xx response counts as a failure.
+func (d *Dispatcher) send(ctx context.Context, delivery supabase.ClaimDuePushDeliveriesRow, ttl time.Duration, now time.Time) (int, error) {
+	uaPublic, authSecret, err := ParseKeys(delivery.P256dh, delivery.Auth)
+	if err != nil {
+		return 0, err
+	}
+
+	body, err := Encrypt(delivery.Payload, uaPublic, authSecret)
+	if err != nil {
+		return 0, err
+	}
+
+	authorization, err := d.cfg.Authorization(delivery.Endpoint, now)
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("TTL", strconv.Itoa(int(ttl.Seconds())))
+	req.Header.Set("Urgency", "normal")
+	req.Header.Set("Authorization", authorization)
+	req.Header.Set("User-Agent", "Cove-Push/1.0")
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	// Drain a little so the connection can be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("push service responded with %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// Pruner deletes finished deliveries once they are a week old
+type Pruner struct {
+	queries  *supabase.Queries
+	interval time.Duration
+	maxAge   time.Duration
+}
+
+func NewPruner(queries *supabase.Queries) *Pruner {
+	return &Pruner{
+		queries:  queries,
+		interval: time.Hour,
+		maxAge:   7 * 24 * time.Hour,
+	}
+}
+
+// Start prunes until ctx is cancelled
+func (p *Pruner) Start(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			before := pgtype.Timestamptz{Time: time.Now().Add(-p.maxAge), Valid: true}
+			_, err := p.queries.DeleteFinishedPushDeliveriesBefore(ctx, before)
+			if err != nil {
+				log.Printf("webpush: failed to prune deliveries: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,283 @@
+package webpush
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	supabase "github.com/egeuysall/cove/internal/supabase/generated"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// fakeDB hands out one delivery and records what the dispatcher does with
+// it, keyed on the "-- name:" line sqlc puts at the top of each query
+type fakeDB struct {
+	mu        sync.Mutex
+	delivery  supabase.ClaimDuePushDeliveriesRow
+	pruned    []pgtype.UUID
+	succeeded []supabase.MarkPushDeliverySucceededParams
+	failed    []supabase.MarkPushDeliveryFailedParams
+}
+
+func queryName(sql string) string {
+	fields := strings.Fields(sql)
+	if len(fields) < 3 || fields[0] != "--" || fields[1] != "name:" {
+		return ""
+	}
+	return fields[2]
+}
+
+func (db *fakeDB) Exec(_ context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	switch queryName(sql) {
+	case "PrunePushSubscription":
+		db.pruned = append(db.pruned, args[0].(pgtype.UUID))
+		return pgconn.NewCommandTag("DELETE 1"), nil
+	case "MarkPushDeliverySucceeded":
+		db.succeeded = append(db.succeeded, supabase.MarkPushDeliverySucceededParams{
+			ResponseStatus: args[0].(pgtype.Int4),
+			ID:             args[1].(pgtype.UUID),
+		})
+		return pgconn.NewCommandTag("UPDATE 1"), nil
+	case "MarkPushDeliveryFailed":
+		db.failed = append(db.failed, supabase.MarkPushDeliveryFailedParams{
+			Status:         args[0].(string),
+			NextAttemptAt:  args[1].(pgtype.Timestamptz),
+			ResponseStatus: args[2].(pgtype.Int4),
+			LastError:      args[3].(pgtype.Text),
+			ID:             args[4].(pgtype.UUID),
+		})
+		return pgconn.NewCommandTag("UPDATE 1"), nil
+	}
+	return pgconn.CommandTag{}, fmt.Errorf("fakeDB: unexpected exec %q", queryName(sql))
+}
+
+func (db *fakeDB) Query(_ context.Context, sql string, _ ...interface{}) (pgx.Rows, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if queryName(sql) == "ClaimDuePushDeliveries" {
+		return &deliveryRows{rows: []supabase.ClaimDuePushDeliveriesRow{db.delivery}}, nil
+	}
+	return nil, fmt.Errorf("fakeDB: unexpected query %q", queryName(sql))
+}
+
+func (db *fakeDB) QueryRow(_ context.Context, sql string, _ ...interface{}) pgx.Row {
+	return errRow{fmt.Errorf("fakeDB: unexpected query %q", queryName(sql))}
+}
+
+type errRow struct{ err error }
+
+func (r errRow) Scan(...interface{}) error { return r.err }
+
+func (db *fakeDB) CopyFrom(context.Context, pgx.Identifier, []string, pgx.CopyFromSource) (int64, error) {
+	return 0, errors.New("fakeDB: unexpected copy")
+}
+
+// deliveryRows scans claimed deliveries in the column order of
+// ClaimDuePushDeliveries
+type deliveryRows struct {
+	rows    []supabase.ClaimDuePushDeliveriesRow
+	current supabase.ClaimDuePushDeliveriesRow
+}
+
+func (r *deliveryRows) Next() bool {
+	if len(r.rows) == 0 {
+		return false
+	}
+	r.current, r.rows = r.rows[0], r.rows[1:]
+	return true
+}
+
+func (r *deliveryRows) Scan(dest ...interface{}) error {
+	*dest[0].(*pgtype.UUID) = r.current.ID
+	*dest[1].(*pgtype.UUID) = r.current.SubscriptionID
+	*dest[2].(*[]byte) = r.current.Payload
+	*dest[3].(*int32) = r.current.Attempts
+	*dest[4].(*pgtype.Timestamptz) = r.current.ExpiresAt
+	*dest[5].(*string) = r.current.Endpoint
+	*dest[6].(*string) = r.current.P256dh
+	*dest[7].(*string) = r.current.Auth
+	return nil
+}
+
+func (r *deliveryRows) Close()                                       {}
+func (r *deliveryRows) Err() error                                   { return nil }
+func (r *deliveryRows) CommandTag() pgconn.CommandTag                { return pgconn.CommandTag{} }
+func (r *deliveryRows) FieldDescriptions() []pgconn.FieldDescription { return nil }
+func (r *deliveryRows) Values() ([]interface{}, error)               { return nil, nil }
+func (r *deliveryRows) RawValues() [][]byte                          { return nil }
+func (r *deliveryRows) Conn() *pgx.Conn                              { return nil }
+
+func testConfig(t *testing.T) Config {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	public, err := key.PublicKey.ECDH()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return Config{
+		Key:       key,
+		PublicKey: base64.RawURLEncoding.EncodeToString(public.Bytes()),
+		Subject:   "mailto:push@cove.test",
+	}
+}
+
+// pushService is a push service stub for one subscription. It checks and
+// decrypts every message with Receive, as cmd/pushstub does, and answers
+// with status.
+type pushService struct {
+	*httptest.Server
+	mu       sync.Mutex
+	received [][]byte
+	rejected []error
+}
+
+func newPushService(t *testing.T, sub testSubscription, status int) *pushService {
+	t.Helper()
+
+	service := &pushService{}
+	service.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload, err := Receive(r, sub.private, sub.authSecret)
+
+		service.mu.Lock()
+		defer service.mu.Unlock()
+
+		if err != nil {
+			service.rejected = append(service.rejected, err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		service.received = append(service.received, payload)
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(service.Close)
+
+	return service
+}
+
+func testDelivery(sub testSubscription, endpoint string) supabase.ClaimDuePushDeliveriesRow {
+	p256dh, auth := sub.keys()
+
+	return supabase.ClaimDuePushDeliveriesRow{
+		ID:             pgtype.UUID{Bytes: [16]byte{1}, Valid: true},
+		SubscriptionID: pgtype.UUID{Bytes: [16]byte{2}, Valid: true},
+		Payload:        []byte(`{"title":"New link in Reading club","url":"/groups/1"}`),
+		ExpiresAt:      pgtype.Timestamptz{Time: time.Now().Add(TTL), Valid: true},
+		Endpoint:       endpoint,
+		P256dh:         p256dh,
+		Auth:           auth,
+	}
+}
+
+func TestDispatcherDelivers(t *testing.T) {
+	sub := newTestSubscription(t)
+	service := newPushService(t, sub, http.StatusCreated)
+
+	db := &fakeDB{delivery: testDelivery(sub, service.URL+"/push/abc")}
+	NewDispatcher(testConfig(t), supabase.New(db), service.Client()).RunOnce(context.Background())
+
+	if len(service.rejected) > 0 {
+		t.Fatalf("push service rejected the message: %v", service.rejected[0])
+	}
+	if len(service.received) != 1 || string(service.received[0]) != string(db.delivery.Payload) {
+		t.Fatalf("push service received %q", service.received)
+	}
+
+	if len(db.succeeded) != 1 || db.succeeded[0].ResponseStatus.Int32 != http.StatusCreated {
+		t.Errorf("recorded successes %+v", db.succeeded)
+	}
+	if len(db.failed) > 0 || len(db.pruned) > 0 {
+		t.Errorf("recorded failures %+v and pruned %v", db.failed, db.pruned)
+	}
+}
+
+func TestDispatcherPrunesGoneSubscriptions(t *testing.T) {
+	for _, status := range []int{http.StatusNotFound, http.StatusGone} {
+		t.Run(http.StatusText(status), func(t *testing.T) {
+			sub := newTestSubscription(t)
+			service := newPushService(t, sub, status)
+
+			db := &fakeDB{delivery: testDelivery(sub, service.URL+"/push/abc")}
+			NewDispatcher(testConfig(t), supabase.New(db), service.Client()).RunOnce(context.Background())
+
+			if len(service.received) != 1 {
+				t.Fatalf("push service received %d messages, rejected %v", len(service.received), service.rejected)
+			}
+			if len(db.pruned) != 1 || db.pruned[0] != db.delivery.SubscriptionID {
+				t.Errorf("pruned %v, want the subscription", db.pruned)
+			}
+			if len(db.succeeded) > 0 || len(db.failed) > 0 {
+				t.Errorf("recorded successes %+v and failures %+v", db.succeeded, db.failed)
+			}
+		})
+	}
+}
+
+func TestDispatcherRetriesOnlyTransientFailures(t *testing.T) {
+	tests := []struct {
+		status int
+		want   string
+	}{
+		{http.StatusInternalServerError, "pending"},
+		{http.StatusTooManyRequests, "pending"},
+		{http.StatusRequestEntityTooLarge, "failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			sub := newTestSubscription(t)
+			service := newPushService(t, sub, tt.status)
+
+			db := &fakeDB{delivery: testDelivery(sub, service.URL+"/push/abc")}
+			NewDispatcher(testConfig(t), supabase.New(db), service.Client()).RunOnce(context.Background())
+
+			if len(db.failed) != 1 {
+				t.Fatalf("recorded failures %+v", db.failed)
+			}
+			failure := db.failed[0]
+			if failure.Status != tt.want || failure.ResponseStatus.Int32 != int32(tt.status) {
+				t.Errorf("recorded %s with status %d, want %s", failure.Status, failure.ResponseStatus.Int32, tt.want)
+			}
+			if len(db.pruned) > 0 {
+				t.Errorf("pruned %v", db.pruned)
+			}
+		})
+	}
+}
+
+func TestDispatcherDropsExpiredDeliveries(t *testing.T) {
+	sub := newTestSubscription(t)
+	service := newPushService(t, sub, http.StatusCreated)
+
+	delivery := testDelivery(sub, service.URL+"/push/abc")
+	delivery.ExpiresAt.Time = time.Now().Add(-time.Minute)
+	db := &fakeDB{delivery: delivery}
+	NewDispatcher(testConfig(t), supabase.New(db), service.Client()).RunOnce(context.Background())
+
+	if len(service.received)+len(service.rejected) > 0 {
+		t.Errorf("an expired message was sent")
+	}
+	if len(db.failed) != 1 || db.failed[0].Status != "failed" {
+		t.Errorf("recorded failures %+v", db.failed)
+	}
+}
@@ -0,0 +1,153 @@
+package webpush
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+)
+
+const (
+	authSecretLength = 16
+	saltLength       = 16
+	publicKeyLength  = 65
+
+	// recordSize is the aes128gcm record size. Every message fits one record.
+	recordSize = 4096
+	headerSize = saltLength + 4 + 1 + publicKeyLength
+
+	// MaxPayloadSize is the largest plaintext that fits the 4096 bytes every
+	// push service must accept, after the header, the padding delimiter and
+	// the GCM tag
+	MaxPayloadSize = 4096 - headerSize - 1 - 16
+)
+
+// recordDelimiter ends the plaintext of the last (here, only) record
+const recordDelimiter = 0x02
+
+var ErrPayloadTooLarge = errors.New("webpush: payload is too large")
+
+// Encrypt encrypts plaintext for a subscription with the aes128gcm content
+// encoding, as RFC 8291 specifies for Web Push. A fresh key pair and salt
+// are used for every message.
+func Encrypt(plaintext []byte, uaPublic *ecdh.PublicKey, authSecret []byte) ([]byte, error) {
+	if len(plaintext) > MaxPayloadSize {
+		return nil, ErrPayloadTooLarge
+	}
+
+	asPrivate, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	asPublic := asPrivate.PublicKey().Bytes()
+
+	shared, err := asPrivate.ECDH(uaPublic)
+	if err != nil {
+		return nil, err
+	}
+
+	salt := make([]byte, saltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	gcm, nonce, err := contentCipher(shared, authSecret, uaPublic.Bytes(), asPublic, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	body := make([]byte, headerSize, headerSize+len(plaintext)+1+gcm.Overhead())
+	copy(body, salt)
+	binary.BigEndian.PutUint32(body[saltLength:], recordSize)
+	body[saltLength+4] = publicKeyLength
+	copy(body[saltLength+5:], asPublic)
+
+	record := append(append([]byte{}, plaintext...), recordDelimiter)
+	return gcm.Seal(body, nonce, record, nil), nil
+}
+
+// Decrypt reverses Encrypt on the receiving side, given the subscription's
+// private key. Browsers do this themselves; it is here for push-service
+// stubs that want to show what they were sent.
+func Decrypt(body []byte, uaPrivate *ecdh.PrivateKey, authSecret []byte) ([]byte, error) {
+	if len(body) < saltLength+5 {
+		return nil, errors.New("webpush: message is too short")
+	}
+
+	salt := body[:saltLength]
+	size := binary.BigEndian.Uint32(body[saltLength:])
+	keyLength := int(body[saltLength+4])
+	if len(body) < saltLength+5+keyLength {
+		return nil, errors.New("webpush: message is too short")
+	}
+	asPublicBytes := body[saltLength+5 : saltLength+5+keyLength]
+	ciphertext := body[saltLength+5+keyLength:]
+
+	if uint32(len(ciphertext)) > size {
+		return nil, errors.New("webpush: messages of more than one record are not supported")
+	}
+
+	asPublic, err := ecdh.P256().NewPublicKey(asPublicBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	shared, err := uaPrivate.ECDH(asPublic)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, nonce, err := contentCipher(shared, authSecret, uaPrivate.PublicKey().Bytes(), asPublicBytes, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	record, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Padding is zeros after the delimiter
+	record = bytes.TrimRight(record, "\x00")
+	if len(record) == 0 || record[len(record)-1] != recordDelimiter {
+		return nil, errors.New("webpush: missing record delimiter")
+	}
+	return record[:len(record)-1], nil
+}
+
+// contentCipher derives the content encryption key and nonce from the ECDH
+// secret: first mixing in the subscription's auth secret and both public
+// keys (RFC 8291 section 3.4), then the salt (RFC 8188 section 2.2)
+func contentCipher(shared, authSecret, uaPublic, asPublic, salt []byte) (cipher.AEAD, []byte, error) {
+	keyInfo := "WebPush: info\x00" + string(uaPublic) + string(asPublic)
+	ikm, err := hkdf.Key(sha256.New, shared, authSecret, keyInfo, 32)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cek, err := hkdf.Key(sha256.New, ikm, salt, "Content-Encoding: aes128gcm\x00", 16)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	nonce, err := hkdf.Key(sha256.New, ikm, salt, "Content-Encoding: nonce\x00", 12)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, nil, err
+	}
+	return gcm, nonce, nil
+}
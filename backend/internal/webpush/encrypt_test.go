@@ -0,0 +1,150 @@
+package webpush
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"testing"
+)
+
+// testSubscription is a browser's side of a subscription: the key pair it
+// decrypts with and the auth secret it shares with the server
+type testSubscription struct {
+	private    *ecdh.PrivateKey
+	authSecret []byte
+}
+
+func newTestSubscription(t *testing.T) testSubscription {
+	t.Helper()
+
+	private, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	authSecret := make([]byte, authSecretLength)
+	if _, err := rand.Read(authSecret); err != nil {
+		t.Fatal(err)
+	}
+
+	return testSubscription{private: private, authSecret: authSecret}
+}
+
+// keys returns the subscription's keys as PushSubscription.toJSON() does
+func (s testSubscription) keys() (p256dh, auth string) {
+	return base64.RawURLEncoding.EncodeToString(s.private.PublicKey().Bytes()),
+		base64.RawURLEncoding.EncodeToString(s.authSecret)
+}
+
+func mustDecode(t *testing.T, s string) []byte {
+	t.Helper()
+
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return raw
+}
+
+func TestEncryptRoundTrip(t *testing.T) {
+	sub := newTestSubscription(t)
+	p256dh, auth := sub.keys()
+
+	uaPublic, authSecret, err := ParseKeys(p256dh, auth)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, size := range []int{0, 1, 41, 1000, MaxPayloadSize} {
+		plaintext := bytes.Repeat([]byte("x"), size)
+
+		body, err := Encrypt(plaintext, uaPublic, authSecret)
+		if err != nil {
+			t.Fatalf("Encrypt %d bytes: %v", size, err)
+		}
+		if len(body) > recordSize {
+			t.Errorf("%d-byte payload encrypts to %d bytes", size, len(body))
+		}
+
+		decrypted, err := Decrypt(body, sub.private, sub.authSecret)
+		if err != nil {
+			t.Fatalf("Decrypt %d bytes: %v", size, err)
+		}
+		if !bytes.Equal(decrypted, plaintext) {
+			t.Errorf("%d-byte payload decrypts to %d bytes", size, len(decrypted))
+		}
+	}
+}
+
+func TestEncryptUsesFreshKeys(t *testing.T) {
+	sub := newTestSubscription(t)
+
+	first, err := Encrypt([]byte("same"), sub.private.PublicKey(), sub.authSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := Encrypt([]byte("same"), sub.private.PublicKey(), sub.authSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bytes.Equal(first[:headerSize], second[:headerSize]) {
+		t.Errorf("two messages share a salt and key")
+	}
+}
+
+func TestEncryptRejectsLargePayloads(t *testing.T) {
+	sub := newTestSubscription(t)
+
+	_, err := Encrypt(make([]byte, MaxPayloadSize+1), sub.private.PublicKey(), sub.authSecret)
+	if !errors.Is(err, ErrPayloadTooLarge) {
+		t.Errorf("Encrypt = %v, want ErrPayloadTooLarge", err)
+	}
+}
+
+func TestDecryptRejectsTampering(t *testing.T) {
+	sub := newTestSubscription(t)
+
+	body, err := Encrypt([]byte("hello"), sub.private.PublicKey(), sub.authSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tampered := append([]byte{}, body...)
+	tampered[len(tampered)-1] ^= 1
+	if _, err := Decrypt(tampered, sub.private, sub.authSecret); err == nil {
+		t.Errorf("a tampered message decrypts")
+	}
+
+	otherSecret := append([]byte{}, sub.authSecret...)
+	otherSecret[0] ^= 1
+	if _, err := Decrypt(body, sub.private, otherSecret); err == nil {
+		t.Errorf("a message decrypts with the wrong auth secret")
+	}
+
+	other := newTestSubscription(t)
+	if _, err := Decrypt(body, other.private, sub.authSecret); err == nil {
+		t.Errorf("a message decrypts with another subscription's key")
+	}
+}
+
+// The example message from RFC 8291 Appendix A
+func TestDecryptRFC8291Example(t *testing.T) {
+	uaPrivate, err := ecdh.P256().NewPrivateKey(mustDecode(t, "q1dXpw3UpT5VOmu_cf_v6ih07Aems3njxI-JWgLcM94"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	authSecret := mustDecode(t, "BTBZMqHH6r4Tts7J_aSIgg")
+	body := mustDecode(t, "DGv6ra1nlYgDCS1FRnbzlwAAEABBBP4z9KsN6nGRTbVYI_c7VJSPQTBtkgcy27mlmlMoZIIg"+
+		"Dll6e3vCYLocInmYWAmS6TlzAC8wEqKK6PBru3jl7A_yl95bQpu6cVPTpK4Mqgkf1CXztLVBSt2Ks3oZwbuwXPXLWyouBWLVWGNWQexSgSxsj_Qulcy4a-fN")
+
+	plaintext, err := Decrypt(body, uaPrivate, authSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(plaintext) != "When I grow up, I want to be a watermelon" {
+		t.Errorf("plaintext = %q", plaintext)
+	}
+}
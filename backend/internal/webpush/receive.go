@@ -0,0 +1,94 @@
+package webpush
+
+import (
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Receive checks a message the way a push service would and decrypts it the
+// way the browser would, given the subscription's private key. Like Decrypt
+// it is for push-service stubs and tests.
+func Receive(r *http.Request, uaPrivate *ecdh.PrivateKey, authSecret []byte) ([]byte, error) {
+	if r.Header.Get("Content-Encoding") != "aes128gcm" {
+		return nil, errors.New("Content-Encoding must be aes128gcm")
+	}
+	if r.Header.Get("TTL") == "" {
+		return nil, errors.New("TTL header is required")
+	}
+
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if err := verifyVAPID(r.Header.Get("Authorization"), scheme+"://"+r.Host); err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, recordSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > recordSize {
+		return nil, errors.New("message is larger than 4096 bytes")
+	}
+
+	return Decrypt(body, uaPrivate, authSecret)
+}
+
+// verifyVAPID checks an Authorization header of the form
+// "vapid t=<jwt>, k=<public key>" (RFC 8292)
+func verifyVAPID(header, audience string) error {
+	scheme, params, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "vapid") {
+		return errors.New("Authorization must use the vapid scheme")
+	}
+
+	var token, key string
+	for _, param := range strings.Split(params, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+		switch name {
+		case "t":
+			token = value
+		case "k":
+			key = value
+		}
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(key)
+	if err != nil {
+		return fmt.Errorf("vapid k is not base64url: %w", err)
+	}
+	public, err := ecdh.P256().NewPublicKey(raw)
+	if err != nil {
+		return fmt.Errorf("vapid k is not a P-256 key: %w", err)
+	}
+	bytes := public.Bytes()
+	verifyKey := &ecdsa.PublicKey{
+		Curve: elliptic.P256(),
+		X:     new(big.Int).SetBytes(bytes[1:33]),
+		Y:     new(big.Int).SetBytes(bytes[33:]),
+	}
+
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(token, claims, func(*jwt.Token) (interface{}, error) {
+		return verifyKey, nil
+	}, jwt.WithValidMethods([]string{"ES256"}), jwt.WithAudience(audience), jwt.WithExpirationRequired())
+	if err != nil {
+		return fmt.Errorf("vapid t: %w", err)
+	}
+
+	if sub, _ := claims["sub"].(string); !strings.HasPrefix(sub, "mailto:") && !strings.HasPrefix(sub, "https:") {
+		return errors.New("vapid t: sub must be a mailto: or https: URL")
+	}
+	return nil
+}
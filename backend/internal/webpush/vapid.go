@@ -0,0 +1,99 @@
+package webpush
+
+import (
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// tokenLifetime is how long a VAPID token is valid. RFC 8292 caps it at a
+// day.
+const tokenLifetime = 12 * time.Hour
+
+// ParsePrivateKey reads a P-256 private key, either as the raw 32-byte
+// scalar in base64url or as a PEM-encoded EC or PKCS #8 key
+func ParsePrivateKey(s string) (*ecdsa.PrivateKey, error) {
+	if strings.HasPrefix(s, "-----BEGIN") {
+		return parsePEMKey(s)
+	}
+
+	raw, err := decodeBase64URL(s)
+	if err != nil {
+		return nil, errors.New("webpush: VAPID private key is neither PEM nor base64url")
+	}
+
+	private, err := ecdh.P256().NewPrivateKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("webpush: invalid VAPID private key: %w", err)
+	}
+
+	// The uncompressed public point is 0x04 || X || Y
+	public := private.PublicKey().Bytes()
+	return &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(public[1:33]),
+			Y:     new(big.Int).SetBytes(public[33:]),
+		},
+		D: new(big.Int).SetBytes(raw),
+	}, nil
+}
+
+func parsePEMKey(s string) (*ecdsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(s))
+	if block == nil {
+		return nil, errors.New("webpush: invalid PEM in VAPID private key")
+	}
+
+	key, err := x509.ParseECPrivateKey(block.Bytes)
+	if err != nil {
+		parsed, pkcs8Err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if pkcs8Err != nil {
+			return nil, fmt.Errorf("webpush: invalid VAPID private key: %w", err)
+		}
+
+		var ok bool
+		key, ok = parsed.(*ecdsa.PrivateKey)
+		if !ok {
+			return nil, errors.New("webpush: VAPID private key is not an ECDSA key")
+		}
+	}
+
+	if key.Curve != elliptic.P256() {
+		return nil, errors.New("webpush: VAPID private key must be on P-256")
+	}
+	return key, nil
+}
+
+// Authorization builds the Authorization header for a message to endpoint:
+// an ES256 token naming the push service's origin as its audience, and the
+// public key the subscription was created with
+func (c Config) Authorization(endpoint string, now time.Time) (string, error) {
+	parsed, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+
+	claims := jwt.MapClaims{
+		"aud": parsed.Scheme + "://" + parsed.Host,
+		"exp": now.Add(tokenLifetime).Unix(),
+		"sub": c.Subject,
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodES256, claims).SignedString(c.Key)
+	if err != nil {
+		return "", err
+	}
+
+	return "vapid t=" + token + ", k=" + c.PublicKey, nil
+}
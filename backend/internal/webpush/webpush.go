@@ -0,0 +1,192 @@
+// Package webpush sends Web Push notifications to users' browsers and
+// phones. Messages are queued in the database and delivered by Dispatcher,
+// which encrypts each payload to its subscription (RFC 8291) and identifies
+// the server to the push service with a VAPID token (RFC 8292).
+package webpush
+
+import (
+	"context"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/egeuysall/cove/internal/netguard"
+	supabase "github.com/egeuysall/cove/internal/supabase/generated"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	// TTL is how long a message waits, in our queue and then at the push
+	// service, for a device that is offline
+	TTL = 24 * time.Hour
+
+	MaxEndpointLength = 2048
+
+	maxTitleLength = 100
+	maxBodyLength  = 240
+)
+
+// Config holds the VAPID key pair and where endpoints may point
+type Config struct {
+	Key *ecdsa.PrivateKey
+	// PublicKey is Key's public half as browsers expect it for
+	// applicationServerKey: an uncompressed point in unpadded base64url
+	PublicKey string
+	// Subject is a mailto: or https: contact for the push service
+	Subject string
+	// AllowLocal accepts http and private-network endpoints, so a local
+	// push-service stub can stand in for a real one
+	AllowLocal bool
+}
+
+// ConfigFromEnv reads the VAPID settings. VAPID_PRIVATE_KEY is a P-256 key,
+// either the 32-byte scalar in base64url that web-push tooling generates or
+// a PEM block. Setting PUSH_ALLOW_LOCAL_ENDPOINTS=true lets subscriptions
+// point at a stub such as `go run ./cmd/pushstub`.
+func ConfigFromEnv() (Config, bool, error) {
+	cfg := Config{
+		Subject:    os.Getenv("VAPID_SUBJECT"),
+		AllowLocal: os.Getenv("PUSH_ALLOW_LOCAL_ENDPOINTS") == "true",
+	}
+
+	raw := strings.TrimSpace(os.Getenv("VAPID_PRIVATE_KEY"))
+	if raw == "" {
+		return cfg, false, nil
+	}
+
+	key, err := ParsePrivateKey(raw)
+	if err != nil {
+		return cfg, false, err
+	}
+
+	public, err := key.ECDH()
+	if err != nil {
+		return cfg, false, err
+	}
+
+	cfg.Key = key
+	cfg.PublicKey = base64.RawURLEncoding.EncodeToString(public.PublicKey().Bytes())
+
+	if cfg.Subject == "" {
+		cfg.Subject = "mailto:push@cove.egeuysal.com"
+	}
+
+	return cfg, true, nil
+}
+
+// Enabled reports whether a VAPID key is configured. Without one nothing is
+// queued, since nothing would ever deliver it.
+func Enabled() bool {
+	return strings.TrimSpace(os.Getenv("VAPID_PRIVATE_KEY")) != ""
+}
+
+// Message is the JSON payload the app's service worker turns into a
+// notification
+type Message struct {
+	Title string `json:"title"`
+	Body  string `json:"body,omitempty"`
+	// URL is the app page to open when the notification is tapped
+	URL string `json:"url,omitempty"`
+	// Tag lets a newer notification replace an older one on the device
+	Tag string `json:"tag,omitempty"`
+}
+
+// Enqueue queues msg for every push subscription of userIds. Like webhooks,
+// failures are logged rather than returned so a notification never fails
+// the request that caused it.
+func Enqueue(ctx context.Context, queries *supabase.Queries, userIds []pgtype.UUID, msg Message) {
+	if len(userIds) == 0 || !Enabled() {
+		return
+	}
+
+	msg.Title = truncate(msg.Title, maxTitleLength)
+	msg.Body = truncate(msg.Body, maxBodyLength)
+
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		log.Printf("webpush: failed to encode message: %v", err)
+		return
+	}
+
+	_, err = queries.EnqueuePushDeliveries(ctx, supabase.EnqueuePushDeliveriesParams{
+		Payload:   payload,
+		ExpiresAt: ExpiresAt(),
+		UserIds:   userIds,
+	})
+	if err != nil {
+		log.Printf("webpush: failed to queue message: %v", err)
+	}
+}
+
+// ExpiresAt is when a message queued now should be given up on
+func ExpiresAt() pgtype.Timestamptz {
+	return pgtype.Timestamptz{Time: time.Now().Add(TTL), Valid: true}
+}
+
+func truncate(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	runes := []rune(s)
+	return strings.TrimSpace(string(runes[:max-1])) + "…"
+}
+
+// ValidateSubscription checks a subscription as a browser's
+// PushSubscription.toJSON() reports it. Errors are safe to show the caller.
+func ValidateSubscription(endpoint, p256dh, auth string, allowLocal bool) error {
+	if endpoint == "" {
+		return errors.New("Endpoint is required")
+	}
+	if len(endpoint) > MaxEndpointLength {
+		return errors.New("Endpoint is too long")
+	}
+
+	parsed, err := url.Parse(endpoint)
+	if err != nil || parsed.Host == "" || !(parsed.Scheme == "https" || allowLocal && parsed.Scheme == "http") {
+		return errors.New("Endpoint must be an https URL")
+	}
+
+	if !allowLocal && netguard.CheckHost(parsed.Hostname()) != nil {
+		return errors.New("Endpoint must be a public push service")
+	}
+
+	if _, _, err := ParseKeys(p256dh, auth); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ParseKeys decodes a subscription's keys: the browser's P-256 public key
+// and the 16-byte authentication secret
+func ParseKeys(p256dh, auth string) (*ecdh.PublicKey, []byte, error) {
+	raw, err := decodeBase64URL(p256dh)
+	if err != nil {
+		return nil, nil, errors.New("keys.p256dh must be base64url")
+	}
+
+	public, err := ecdh.P256().NewPublicKey(raw)
+	if err != nil {
+		return nil, nil, errors.New("keys.p256dh must be an uncompressed P-256 public key")
+	}
+
+	secret, err := decodeBase64URL(auth)
+	if err != nil || len(secret) != authSecretLength {
+		return nil, nil, errors.New("keys.auth must be 16 bytes of base64url")
+	}
+
+	return public, secret, nil
+}
+
+// decodeBase64URL accepts base64url with or without padding, as browsers and
+// libraries disagree on it
+func decodeBase64URL(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
@@ -1,6 +1,18 @@
+export type GroupSettings = {
+    who_can_invite: "members" | "admins"
+    who_can_post: "members" | "admins"
+    max_members: number
+    retention_days: number
+}
+
 export type Group = {
     ID: string
     Name: string
     CreatedBy?: string
     CreatedAt?: string
+    Description?: string
+    Emoji?: string
+    AvatarURL?: string
+    Settings?: GroupSettings
+    UpdatedAt?: string
 }
\ No newline at end of file